@@ -53,6 +53,9 @@ func runReports(args []string) {
   fs := flag.NewFlagSet("reports-run", flag.ExitOnError)
   configPath := fs.String("config", "/etc/lightningos/config.yaml", "Path to config.yaml")
   dateStr := fs.String("date", "", "Report date (YYYY-MM-DD), defaults to yesterday")
+  rebuild := fs.Bool("rebuild-from-events", false, "Recompute stored days from reports_events instead of LND (needs reports.store_events)")
+  fromStr := fs.String("from", "", "With --rebuild-from-events: start date (YYYY-MM-DD), defaults to --date")
+  toStr := fs.String("to", "", "With --rebuild-from-events: end date (YYYY-MM-DD), defaults to --date")
   _ = fs.Parse(args)
 
   cfg, err := config.Load(*configPath)
@@ -61,6 +64,11 @@ func runReports(args []string) {
   }
 
   logger := log.New(os.Stdout, "", log.LstdFlags)
+  opts, err := server.ReportsOptions(cfg, logger)
+  if err != nil {
+    logger.Fatalf("reports-run failed: %v", err)
+  }
+  opts.StrictValidation = reportsStrictValidation()
   dsn, err := server.ResolveNotificationsDSN(logger)
   if err != nil {
     logger.Fatalf("reports-run failed: %v", err)
@@ -76,12 +84,17 @@ func runReports(args []string) {
   defer pool.Close()
 
   lnd := lndclient.New(cfg, logger)
-  svc := reports.NewService(pool, lnd, logger)
+  svc := reports.NewService(reports.NewPgStore(pool, opts), lnd, logger, opts)
   if err := svc.EnsureSchema(ctx); err != nil {
     logger.Fatalf("reports-run failed: %v", err)
   }
+  var notifier *reports.WebhookNotifier
+  if strings.TrimSpace(cfg.Reports.WebhookURL) != "" {
+    notifier = reports.NewWebhookNotifier(cfg.Reports.WebhookURL, cfg.Reports.WebhookSecret, logger)
+    svc.SetNotifier(notifier)
+  }
 
-  loc := time.Local
+  loc := opts.Location()
   reportDate := time.Now().In(loc).AddDate(0, 0, -1)
   if strings.TrimSpace(*dateStr) != "" {
     parsed, err := reports.ParseDate(*dateStr, loc)
@@ -91,10 +104,18 @@ func runReports(args []string) {
     reportDate = parsed
   }
 
+  if *rebuild {
+    rebuildFromEvents(ctx, svc, logger, reportDate, *fromStr, *toStr, loc)
+    return
+  }
+
   row, err := svc.RunDaily(ctx, reportDate, loc, nil)
   if err != nil {
     logger.Fatalf("reports-run failed: %v", err)
   }
+  if err := svc.RecordFiatRates(ctx, reportDate, loc); err != nil {
+    logger.Printf("reports: fiat rates incomplete: %v", err)
+  }
 
   logger.Printf(
     "reports: stored %s (revenue %d sats, cost %d sats, net %d sats)",
@@ -103,6 +124,7 @@ func runReports(args []string) {
     row.Metrics.RebalanceFeeCostSat,
     row.Metrics.NetRoutingProfitSat,
   )
+  notifier.Wait()
 }
 
 func runReportsBackfill(args []string) {
@@ -123,6 +145,11 @@ func runReportsBackfill(args []string) {
   }
 
   logger := log.New(os.Stdout, "", log.LstdFlags)
+  opts, err := server.ReportsOptions(cfg, logger)
+  if err != nil {
+    logger.Fatalf("reports-backfill failed: %v", err)
+  }
+  opts.StrictValidation = reportsStrictValidation()
   dsn, err := server.ResolveNotificationsDSN(logger)
   if err != nil {
     logger.Fatalf("reports-backfill failed: %v", err)
@@ -135,7 +162,7 @@ func runReportsBackfill(args []string) {
   defer pool.Close()
 
   lnd := lndclient.New(cfg, logger)
-  svc := reports.NewService(pool, lnd, logger)
+  svc := reports.NewService(reports.NewPgStore(pool, opts), lnd, logger, opts)
   schemaCtx, schemaCancel := context.WithTimeout(context.Background(), 30*time.Second)
   if err := svc.EnsureSchema(schemaCtx); err != nil {
     schemaCancel()
@@ -143,7 +170,7 @@ func runReportsBackfill(args []string) {
   }
   schemaCancel()
 
-  loc := time.Local
+  loc := opts.Location()
   startDate, err := reports.ParseDate(*fromStr, loc)
   if err != nil {
     logger.Fatalf("reports-backfill failed: invalid --from date")
@@ -157,7 +184,7 @@ func runReportsBackfill(args []string) {
   }
   limit := *maxDays
   if limit <= 0 {
-    limit = reports.CustomRangeDaysLimit()
+    limit = opts.RangeDaysLimit()
   }
   days := int(endDate.Sub(startDate).Hours()/24) + 1
   if days > limit {
@@ -177,6 +204,11 @@ func runReportsBackfill(args []string) {
     dayKey := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
     override := rebalanceByDay[dayKey]
     row, err := svc.RunDaily(dayCtx, day, loc, &override)
+    if err == nil {
+      if rateErr := svc.RecordFiatRates(dayCtx, day, loc); rateErr != nil {
+        logger.Printf("reports: fiat rates incomplete for %s: %v", day.Format("2006-01-02"), rateErr)
+      }
+    }
     dayCancel()
     if err != nil {
       logger.Fatalf("reports-backfill failed on %s: %v", day.Format("2006-01-02"), err)
@@ -191,6 +223,46 @@ func runReportsBackfill(args []string) {
   }
 }
 
+func rebuildFromEvents(ctx context.Context, svc *reports.Service, logger *log.Logger, reportDate time.Time, fromStr, toStr string, loc *time.Location) {
+  startDate, endDate := reportDate, reportDate
+  if strings.TrimSpace(fromStr) != "" {
+    parsed, err := reports.ParseDate(fromStr, loc)
+    if err != nil {
+      logger.Fatalf("reports-run failed: invalid --from date")
+    }
+    startDate = parsed
+  }
+  if strings.TrimSpace(toStr) != "" {
+    parsed, err := reports.ParseDate(toStr, loc)
+    if err != nil {
+      logger.Fatalf("reports-run failed: invalid --to date")
+    }
+    endDate = parsed
+  }
+  if endDate.Before(startDate) {
+    logger.Fatalf("reports-run failed: invalid range")
+  }
+
+  rows, err := svc.RebuildFromEvents(ctx, startDate, endDate, loc)
+  if err != nil {
+    logger.Fatalf("reports-run failed: %v", err)
+  }
+  for _, row := range rows {
+    logger.Printf(
+      "reports: rebuilt %s from events (revenue %d sats, cost %d sats, net %d sats)",
+      row.ReportDate.Format("2006-01-02"),
+      row.Metrics.ForwardFeeRevenueSat,
+      row.Metrics.RebalanceFeeCostSat,
+      row.Metrics.NetRoutingProfitSat,
+    )
+  }
+  logger.Printf("reports: rebuilt %d day(s) from events", len(rows))
+}
+
+func reportsStrictValidation() bool {
+  return strings.TrimSpace(os.Getenv("REPORTS_STRICT_VALIDATION")) == "1"
+}
+
 func reportsRunTimeout() time.Duration {
   raw := strings.TrimSpace(os.Getenv("REPORTS_RUN_TIMEOUT_SEC"))
   if raw == "" {
@@ -0,0 +1,73 @@
+package audit
+
+import (
+  "context"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Entry is one control action: what was run, by whom and how it ended.
+type Entry struct {
+  ID int64 `json:"id"`
+  OccurredAt time.Time `json:"occurred_at"`
+  Action string `json:"action"`
+  Actor string `json:"actor"`
+  Result string `json:"result"`
+}
+
+func EnsureSchema(ctx context.Context, db *pgxpool.Pool) error {
+  if db == nil {
+    return nil
+  }
+  _, err := db.Exec(ctx, `
+create table if not exists control_audit (
+  id bigserial primary key,
+  occurred_at timestamptz not null default now(),
+  action text not null,
+  actor text not null default '',
+  result text not null default ''
+);
+`)
+  return err
+}
+
+func Insert(ctx context.Context, db *pgxpool.Pool, entry Entry) error {
+  if db == nil {
+    return nil
+  }
+  _, err := db.Exec(ctx, `
+insert into control_audit (occurred_at, action, actor, result)
+values ($1, $2, $3, $4)
+`, entry.OccurredAt.UTC(), entry.Action, entry.Actor, entry.Result)
+  return err
+}
+
+// Fetch returns entries newest first. A beforeID of zero starts at the latest
+// entry; pass the last id of a page to fetch the next one.
+func Fetch(ctx context.Context, db *pgxpool.Pool, beforeID int64, limit int) ([]Entry, error) {
+  if db == nil {
+    return nil, nil
+  }
+  rows, err := db.Query(ctx, `
+select id, occurred_at, action, actor, result
+from control_audit
+where ($1::bigint = 0 or id < $1)
+order by id desc
+limit $2
+`, beforeID, limit)
+  if err != nil {
+    return nil, err
+  }
+  defer rows.Close()
+
+  var items []Entry
+  for rows.Next() {
+    var entry Entry
+    if err := rows.Scan(&entry.ID, &entry.OccurredAt, &entry.Action, &entry.Actor, &entry.Result); err != nil {
+      return nil, err
+    }
+    items = append(items, entry)
+  }
+  return items, rows.Err()
+}
@@ -3,6 +3,8 @@ package config
 import (
   "fmt"
   "os"
+  "regexp"
+  "strings"
 
   "gopkg.in/yaml.v3"
 )
@@ -14,6 +16,9 @@ type Config struct {
   Postgres PostgresConfig `yaml:"postgres"`
   UI UIConfig `yaml:"ui"`
   Features FeaturesConfig `yaml:"features"`
+  Elements ElementsConfig `yaml:"elements"`
+  Reports ReportsConfig `yaml:"reports"`
+  Systemd SystemdConfig `yaml:"systemd"`
 }
 
 type ServerConfig struct {
@@ -21,6 +26,15 @@ type ServerConfig struct {
   Port    int    `yaml:"port"`
   TLSCert string `yaml:"tls_cert"`
   TLSKey  string `yaml:"tls_key"`
+  RateLimit RateLimitConfig `yaml:"rate_limit"`
+}
+
+// RateLimitConfig limits the status endpoints. Enabled defaults to true;
+// enabled: false turns the limiter off. Zero rate or burst means the default.
+type RateLimitConfig struct {
+  Enabled *bool `yaml:"enabled"`
+  RequestsPerSecond float64 `yaml:"requests_per_second"`
+  Burst int `yaml:"burst"`
 }
 
 type LNDConfig struct {
@@ -49,6 +63,63 @@ type FeaturesConfig struct {
   EnableAppStorePlaceholder bool `yaml:"enable_app_store_placeholder"`
 }
 
+type ElementsConfig struct {
+  RPCWaitTimeoutSec int `yaml:"rpc_wait_timeout_sec"`
+  StatusTimeoutSec int `yaml:"status_timeout_sec"`
+  ExpectedChain string `yaml:"expected_chain"`
+  ServiceUnit string `yaml:"service_unit"`
+  StaleAfterSec int `yaml:"stale_after_sec"`
+  MinVersion string `yaml:"min_version"`
+  User string `yaml:"user"`
+}
+
+type SystemdConfig struct {
+  StatusUnits []string `yaml:"status_units"`
+  MaxConcurrent int `yaml:"max_concurrent"`
+}
+
+type ReportsConfig struct {
+  WebhookURL string `yaml:"webhook_url"`
+  WebhookSecret string `yaml:"webhook_secret"`
+  Timezone string `yaml:"timezone"`
+  StoreEvents bool `yaml:"store_events"`
+  StoreChannels bool `yaml:"store_channels"`
+  QueryTimeoutSec int `yaml:"query_timeout_sec"`
+  MaxRangeDays int `yaml:"max_range_days"`
+  FetchAllMaxRows int `yaml:"fetch_all_max_rows"`
+  Anomalies ReportsAnomaliesConfig `yaml:"anomalies"`
+  FiatCurrencies []string `yaml:"fiat_currencies"`
+  PriceURL string `yaml:"price_url"`
+}
+
+type ReportsAnomaliesConfig struct {
+  CostRevenueRatio float64 `yaml:"cost_revenue_ratio"`
+  ActiveStreakDays int `yaml:"active_streak_days"`
+}
+
+const DefaultElementsServiceUnit = "lightningos-elements.service"
+
+const DefaultElementsUser = "losop"
+
+var serviceUnitPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9@._-]*\.service$`)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var userNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// ValidUserName reports whether name is a plain POSIX account name that is safe
+// to pass to systemd-run --uid/--gid.
+func ValidUserName(name string) bool {
+  return userNamePattern.MatchString(name)
+}
+
+// ValidServiceUnit reports whether name is a systemd service unit that is safe
+// to pass to systemctl. The leading character must not be a dash so the name
+// can never be parsed as a flag.
+func ValidServiceUnit(name string) bool {
+  return serviceUnitPattern.MatchString(name)
+}
+
 func Load(path string) (*Config, error) {
   b, err := os.ReadFile(path)
   if err != nil {
@@ -72,6 +143,92 @@ func Load(path string) (*Config, error) {
   if cfg.UI.StaticDir == "" {
     cfg.UI.StaticDir = "/opt/lightningos/ui"
   }
+  if cfg.Server.RateLimit.RequestsPerSecond < 0 || cfg.Server.RateLimit.Burst < 0 {
+    return nil, fmt.Errorf("server rate limit must be positive")
+  }
+  if cfg.Server.RateLimit.Enabled != nil && !*cfg.Server.RateLimit.Enabled {
+    cfg.Server.RateLimit.RequestsPerSecond = 0
+    cfg.Server.RateLimit.Burst = 0
+  } else {
+    if cfg.Server.RateLimit.RequestsPerSecond == 0 {
+      cfg.Server.RateLimit.RequestsPerSecond = 2
+    }
+    if cfg.Server.RateLimit.Burst == 0 {
+      cfg.Server.RateLimit.Burst = 5
+    }
+  }
+  for i, currency := range cfg.Reports.FiatCurrencies {
+    currency = strings.ToUpper(strings.TrimSpace(currency))
+    if !currencyPattern.MatchString(currency) {
+      return nil, fmt.Errorf("reports fiat_currencies entries must be 3-letter codes")
+    }
+    cfg.Reports.FiatCurrencies[i] = currency
+  }
+  if cfg.Reports.PriceURL != "" && !strings.HasPrefix(cfg.Reports.PriceURL, "https://") && !strings.HasPrefix(cfg.Reports.PriceURL, "http://") {
+    return nil, fmt.Errorf("reports price_url must be an http(s) url")
+  }
+  if cfg.Reports.StoreChannels && !cfg.Reports.StoreEvents {
+    return nil, fmt.Errorf("reports.store_channels requires reports.store_events")
+  }
+  if cfg.Elements.RPCWaitTimeoutSec < 0 || cfg.Elements.StatusTimeoutSec < 0 || cfg.Elements.StaleAfterSec < 0 {
+    return nil, fmt.Errorf("elements timeouts must be positive")
+  }
+  if cfg.Elements.RPCWaitTimeoutSec == 0 {
+    cfg.Elements.RPCWaitTimeoutSec = 5
+  }
+  if cfg.Elements.StatusTimeoutSec == 0 {
+    cfg.Elements.StatusTimeoutSec = 6
+  }
+  if cfg.Elements.StaleAfterSec == 0 {
+    cfg.Elements.StaleAfterSec = 600
+  }
+  if cfg.Elements.ExpectedChain == "" {
+    cfg.Elements.ExpectedChain = "liquidv1"
+  }
+  if cfg.Elements.ServiceUnit == "" {
+    cfg.Elements.ServiceUnit = DefaultElementsServiceUnit
+  }
+  if !ValidServiceUnit(cfg.Elements.ServiceUnit) {
+    return nil, fmt.Errorf("elements service_unit must match [A-Za-z0-9@._-]+.service")
+  }
+  if cfg.Elements.User == "" {
+    cfg.Elements.User = DefaultElementsUser
+  }
+  if !ValidUserName(cfg.Elements.User) {
+    return nil, fmt.Errorf("elements user must match [a-z_][a-z0-9_-]{0,31}")
+  }
+  if len(cfg.Systemd.StatusUnits) == 0 {
+    cfg.Systemd.StatusUnits = []string{"lnd.service", "lightningos-manager.service", "postgresql.service", "tor.service"}
+  }
+  for _, unit := range cfg.Systemd.StatusUnits {
+    if !ValidServiceUnit(unit) {
+      return nil, fmt.Errorf("systemd status unit %q must match [A-Za-z0-9@._-]+.service", unit)
+    }
+  }
+  if cfg.Systemd.MaxConcurrent < 0 {
+    return nil, fmt.Errorf("systemd max_concurrent must be positive")
+  }
+  if cfg.Systemd.MaxConcurrent == 0 {
+    cfg.Systemd.MaxConcurrent = 4
+  }
+  if cfg.Reports.QueryTimeoutSec < 0 {
+    return nil, fmt.Errorf("reports query timeout must be positive")
+  }
+  if cfg.Reports.QueryTimeoutSec == 0 {
+    cfg.Reports.QueryTimeoutSec = 60
+  }
+  if cfg.Reports.MaxRangeDays < 0 {
+    return nil, fmt.Errorf("reports max range days must be positive")
+  }
+  if cfg.Reports.MaxRangeDays == 0 {
+    cfg.Reports.MaxRangeDays = 730
+  }
+  if cfg.Reports.FetchAllMaxRows < 0 {
+    return nil, fmt.Errorf("reports fetch all max rows must not be negative")
+  }
+  if cfg.Reports.FetchAllMaxRows == 0 {
+    cfg.Reports.FetchAllMaxRows = 3650
+  }
 
   if cfg.Server.TLSCert == "" || cfg.Server.TLSKey == "" {
     return nil, fmt.Errorf("server TLS cert/key required")
@@ -0,0 +1,79 @@
+package elements
+
+import (
+  "context"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Snapshot is one sample of the Elements sync state.
+type Snapshot struct {
+  CapturedAt time.Time `json:"captured_at"`
+  Blocks int64 `json:"blocks"`
+  Headers int64 `json:"headers"`
+  VerificationProgress float64 `json:"verification_progress"`
+  Peers int `json:"peers"`
+}
+
+func EnsureHistorySchema(ctx context.Context, db *pgxpool.Pool) error {
+  if db == nil {
+    return nil
+  }
+  _, err := db.Exec(ctx, `
+create table if not exists elements_status_history (
+  captured_at timestamptz primary key,
+  blocks bigint not null default 0,
+  headers bigint not null default 0,
+  verification_progress double precision not null default 0,
+  peers integer not null default 0
+);
+`)
+  return err
+}
+
+func InsertSnapshot(ctx context.Context, db *pgxpool.Pool, snap Snapshot) error {
+  if db == nil {
+    return nil
+  }
+  _, err := db.Exec(ctx, `
+insert into elements_status_history (captured_at, blocks, headers, verification_progress, peers)
+values ($1, $2, $3, $4, $5)
+on conflict (captured_at) do nothing
+`, snap.CapturedAt.UTC(), snap.Blocks, snap.Headers, snap.VerificationProgress, snap.Peers)
+  return err
+}
+
+func FetchSnapshots(ctx context.Context, db *pgxpool.Pool, start, end time.Time) ([]Snapshot, error) {
+  if db == nil {
+    return nil, nil
+  }
+  rows, err := db.Query(ctx, `
+select captured_at, blocks, headers, verification_progress, peers
+from elements_status_history
+where captured_at >= $1 and captured_at <= $2
+order by captured_at asc
+`, start.UTC(), end.UTC())
+  if err != nil {
+    return nil, err
+  }
+  defer rows.Close()
+
+  var items []Snapshot
+  for rows.Next() {
+    var snap Snapshot
+    if err := rows.Scan(&snap.CapturedAt, &snap.Blocks, &snap.Headers, &snap.VerificationProgress, &snap.Peers); err != nil {
+      return nil, err
+    }
+    items = append(items, snap)
+  }
+  return items, rows.Err()
+}
+
+func PruneSnapshots(ctx context.Context, db *pgxpool.Pool, cutoff time.Time) error {
+  if db == nil {
+    return nil
+  }
+  _, err := db.Exec(ctx, "delete from elements_status_history where captured_at < $1", cutoff.UTC())
+  return err
+}
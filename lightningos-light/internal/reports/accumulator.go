@@ -0,0 +1,53 @@
+package reports
+
+import "sync"
+
+type Accumulator struct {
+  mu sync.Mutex
+  forwardFeeMsat int64
+  routedVolumeMsat int64
+  rebalanceCostMsat int64
+  forwardCount int64
+  rebalanceCount int64
+}
+
+func NewAccumulator() *Accumulator {
+  return &Accumulator{}
+}
+
+func (a *Accumulator) AddForward(feeMsat, volumeMsat int64) {
+  a.mu.Lock()
+  a.forwardFeeMsat += feeMsat
+  a.routedVolumeMsat += volumeMsat
+  a.forwardCount++
+  a.mu.Unlock()
+}
+
+func (a *Accumulator) AddRebalance(costMsat int64) {
+  a.mu.Lock()
+  a.rebalanceCostMsat += costMsat
+  a.rebalanceCount++
+  a.mu.Unlock()
+}
+
+func (a *Accumulator) Snapshot() Metrics {
+  a.mu.Lock()
+  defer a.mu.Unlock()
+  return metricsFromMsat(a.forwardFeeMsat, a.rebalanceCostMsat, a.routedVolumeMsat, a.forwardCount, a.rebalanceCount)
+}
+
+func metricsFromMsat(forwardRevenueMsat, rebalanceCostMsat, routedVolumeMsat, forwardCount, rebalanceCount int64) Metrics {
+  netMsat := forwardRevenueMsat - rebalanceCostMsat
+  return Metrics{
+    ForwardFeeRevenueSat: forwardRevenueMsat / 1000,
+    ForwardFeeRevenueMsat: forwardRevenueMsat,
+    RebalanceFeeCostSat: rebalanceCostMsat / 1000,
+    RebalanceFeeCostMsat: rebalanceCostMsat,
+    NetRoutingProfitSat: netMsat / 1000,
+    NetRoutingProfitMsat: netMsat,
+    ForwardCount: forwardCount,
+    RebalanceCount: rebalanceCount,
+    RoutedVolumeSat: routedVolumeMsat / 1000,
+    RoutedVolumeMsat: routedVolumeMsat,
+  }
+}
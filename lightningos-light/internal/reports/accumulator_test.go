@@ -0,0 +1,55 @@
+package reports
+
+import (
+  "sync"
+  "testing"
+)
+
+func TestAccumulatorSnapshot(t *testing.T) {
+  acc := NewAccumulator()
+  acc.AddForward(1500, 2000000)
+  acc.AddForward(2500, 3000000)
+  acc.AddRebalance(1200)
+
+  metrics := acc.Snapshot()
+  if metrics.ForwardFeeRevenueMsat != 4000 || metrics.ForwardFeeRevenueSat != 4 {
+    t.Fatalf("unexpected revenue: %+v", metrics)
+  }
+  if metrics.RebalanceFeeCostMsat != 1200 || metrics.RebalanceFeeCostSat != 1 {
+    t.Fatalf("unexpected cost: %+v", metrics)
+  }
+  if metrics.NetRoutingProfitMsat != 2800 || metrics.NetRoutingProfitSat != 2 {
+    t.Fatalf("unexpected net: %+v", metrics)
+  }
+  if metrics.RoutedVolumeMsat != 5000000 || metrics.RoutedVolumeSat != 5000 {
+    t.Fatalf("unexpected volume: %+v", metrics)
+  }
+  if metrics.ForwardCount != 2 || metrics.RebalanceCount != 1 {
+    t.Fatalf("unexpected counts: %+v", metrics)
+  }
+}
+
+func TestAccumulatorConcurrent(t *testing.T) {
+  acc := NewAccumulator()
+  var wg sync.WaitGroup
+  for i := 0; i < 50; i++ {
+    wg.Add(2)
+    go func() {
+      defer wg.Done()
+      acc.AddForward(1000, 10000)
+    }()
+    go func() {
+      defer wg.Done()
+      acc.AddRebalance(500)
+    }()
+  }
+  wg.Wait()
+
+  metrics := acc.Snapshot()
+  if metrics.ForwardCount != 50 || metrics.RebalanceCount != 50 {
+    t.Fatalf("unexpected counts: %+v", metrics)
+  }
+  if metrics.NetRoutingProfitMsat != 25000 {
+    t.Fatalf("unexpected net: %d", metrics.NetRoutingProfitMsat)
+  }
+}
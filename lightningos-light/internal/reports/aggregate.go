@@ -0,0 +1,140 @@
+package reports
+
+import (
+  "math"
+  "sort"
+  "time"
+)
+
+func summarizeRows(items []Row) Summary {
+  days := int64(len(items))
+  if days == 0 {
+    return Summary{}
+  }
+
+  totals := Metrics{}
+  maxes := Metrics{}
+  medians := Metrics{}
+  columns := make([][]int64, len(metricCounters(&totals)))
+  for i, item := range items {
+    metrics := item.Metrics
+    values := metricCounters(&metrics)
+    totalFields := metricCounters(&totals)
+    maxFields := metricCounters(&maxes)
+    for j, value := range values {
+      *totalFields[j] += *value
+      if i == 0 || *value > *maxFields[j] {
+        *maxFields[j] = *value
+      }
+      columns[j] = append(columns[j], *value)
+    }
+  }
+  for j, field := range metricCounters(&medians) {
+    *field = medianInt64(columns[j])
+  }
+
+  fillMsatFromSat(&totals)
+  fillMsatFromSat(&maxes)
+  fillMsatFromSat(&medians)
+  return Summary{
+    Days: days,
+    HasData: true,
+    Totals: totals,
+    Averages: averageMetrics(totals, days),
+    Max: maxes,
+    Median: medians,
+    EffectivePpm: effectivePpm(totals),
+    RebalanceCostRatio: rebalanceCostRatio(totals),
+  }
+}
+
+func rollupRows(items []Row, granularity Granularity) []RollupBucket {
+  var buckets []RollupBucket
+  index := map[time.Time]int{}
+  for _, item := range items {
+    start := bucketStart(item.ReportDate, granularity)
+    pos, ok := index[start]
+    if !ok {
+      pos = len(buckets)
+      index[start] = pos
+      buckets = append(buckets, RollupBucket{BucketStart: start})
+    }
+    bucket := &buckets[pos]
+    bucket.Days++
+    metrics := item.Metrics
+    totalFields := metricCounters(&bucket.Totals)
+    for j, value := range metricCounters(&metrics) {
+      *totalFields[j] += *value
+    }
+  }
+
+  sort.Slice(buckets, func(i, j int) bool {
+    return buckets[i].BucketStart.Before(buckets[j].BucketStart)
+  })
+  for i := range buckets {
+    fillMsatFromSat(&buckets[i].Totals)
+    buckets[i].Averages = averageMetrics(buckets[i].Totals, buckets[i].Days)
+  }
+  return buckets
+}
+
+func weekdayTotals(items []Row) [7]Metrics {
+  var buckets [7]Metrics
+  for _, item := range items {
+    metrics := item.Metrics
+    totalFields := metricCounters(&buckets[item.ReportDate.Weekday()])
+    for j, value := range metricCounters(&metrics) {
+      *totalFields[j] += *value
+    }
+  }
+  for i := range buckets {
+    fillMsatFromSat(&buckets[i])
+  }
+  return buckets
+}
+
+func bucketStart(date time.Time, granularity Granularity) time.Time {
+  day := normalizeReportDate(date)
+  switch granularity {
+  case Weekly:
+    offset := (int(day.Weekday()) + 6) % 7
+    return day.AddDate(0, 0, -offset)
+  case Monthly:
+    return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+  case Quarterly:
+    month := (day.Month()-1)/3*3 + 1
+    return time.Date(day.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+  case Yearly:
+    return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+  default:
+    return day
+  }
+}
+
+func metricCounters(metrics *Metrics) []*int64 {
+  return []*int64{
+    &metrics.ForwardFeeRevenueSat,
+    &metrics.ForwardFeeRevenueMsat,
+    &metrics.RebalanceFeeCostSat,
+    &metrics.RebalanceFeeCostMsat,
+    &metrics.NetRoutingProfitSat,
+    &metrics.NetRoutingProfitMsat,
+    &metrics.ForwardCount,
+    &metrics.RebalanceCount,
+    &metrics.RoutedVolumeSat,
+    &metrics.RoutedVolumeMsat,
+  }
+}
+
+func medianInt64(values []int64) int64 {
+  if len(values) == 0 {
+    return 0
+  }
+  sorted := append([]int64(nil), values...)
+  sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+  mid := len(sorted) / 2
+  if len(sorted)%2 == 1 {
+    return sorted[mid]
+  }
+  return int64(math.Round((float64(sorted[mid-1]) + float64(sorted[mid])) / 2))
+}
@@ -0,0 +1,98 @@
+package reports
+
+import (
+  "testing"
+  "time"
+)
+
+func TestSummarizeRowsMaxAndMedian(t *testing.T) {
+  items := []Row{
+    {ReportDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardFeeRevenueSat: 10, ForwardCount: 1}},
+    {ReportDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardFeeRevenueSat: 40, ForwardCount: 4}},
+    {ReportDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardFeeRevenueSat: 25, ForwardCount: 2}},
+    {ReportDate: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardFeeRevenueSat: 20, ForwardCount: 3}},
+  }
+
+  summary := summarizeRows(items)
+  if summary.Days != 4 || !summary.HasData {
+    t.Fatalf("expected 4 days with data, got %d (%v)", summary.Days, summary.HasData)
+  }
+  if summary.Totals.ForwardFeeRevenueSat != 95 || summary.Totals.ForwardFeeRevenueMsat != 95000 {
+    t.Fatalf("unexpected totals: %+v", summary.Totals)
+  }
+  if summary.Max.ForwardFeeRevenueSat != 40 || summary.Max.ForwardCount != 4 {
+    t.Fatalf("unexpected max: %+v", summary.Max)
+  }
+  if summary.Median.ForwardFeeRevenueSat != 23 {
+    t.Fatalf("expected median 23 (22.5 rounded), got %d", summary.Median.ForwardFeeRevenueSat)
+  }
+  if summary.Averages.ForwardCount != 2 {
+    t.Fatalf("unexpected average forward count: %d", summary.Averages.ForwardCount)
+  }
+}
+
+func TestSummarizeRowsEmpty(t *testing.T) {
+  if summary := summarizeRows(nil); summary.Days != 0 || summary.HasData {
+    t.Fatalf("expected empty summary, got %+v", summary)
+  }
+}
+
+func TestRollupRowsWeekly(t *testing.T) {
+  items := []Row{
+    {ReportDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardCount: 1}},
+    {ReportDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardCount: 2}},
+    {ReportDate: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardCount: 3}},
+  }
+
+  buckets := rollupRows(items, Weekly)
+  if len(buckets) != 2 {
+    t.Fatalf("expected 2 buckets, got %d", len(buckets))
+  }
+  if got := buckets[0].BucketStart.Format("2006-01-02"); got != "2026-02-23" {
+    t.Fatalf("unexpected first bucket start: %s", got)
+  }
+  if got := buckets[1].BucketStart.Format("2006-01-02"); got != "2026-03-02" {
+    t.Fatalf("unexpected second bucket start: %s", got)
+  }
+  if buckets[1].Days != 2 || buckets[1].Totals.ForwardCount != 5 {
+    t.Fatalf("unexpected second bucket: %+v", buckets[1])
+  }
+}
+
+func TestRollupRowsQuarterly(t *testing.T) {
+  items := []Row{
+    {ReportDate: time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardCount: 1}},
+    {ReportDate: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardCount: 2}},
+    {ReportDate: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardCount: 3}},
+  }
+
+  buckets := rollupRows(items, Quarterly)
+  if len(buckets) != 2 {
+    t.Fatalf("expected 2 buckets, got %d", len(buckets))
+  }
+  if got := buckets[0].BucketStart.Format("2006-01-02"); got != "2026-01-01" || buckets[0].Totals.ForwardCount != 3 {
+    t.Fatalf("unexpected first bucket: %s %+v", got, buckets[0])
+  }
+  if got := buckets[1].BucketStart.Format("2006-01-02"); got != "2026-04-01" {
+    t.Fatalf("unexpected second bucket start: %s", got)
+  }
+}
+
+func TestWeekdayTotals(t *testing.T) {
+  items := []Row{
+    {ReportDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardFeeRevenueSat: 5, ForwardCount: 1}},
+    {ReportDate: time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardFeeRevenueSat: 7, ForwardCount: 2}},
+    {ReportDate: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC), Metrics: Metrics{ForwardCount: 9}},
+  }
+
+  buckets := weekdayTotals(items)
+  if buckets[time.Sunday].ForwardFeeRevenueSat != 12 || buckets[time.Sunday].ForwardFeeRevenueMsat != 12000 {
+    t.Fatalf("unexpected sunday bucket: %+v", buckets[time.Sunday])
+  }
+  if buckets[time.Wednesday].ForwardCount != 9 {
+    t.Fatalf("unexpected wednesday bucket: %+v", buckets[time.Wednesday])
+  }
+  if buckets[time.Monday] != (Metrics{}) {
+    t.Fatalf("expected zeroed monday bucket, got %+v", buckets[time.Monday])
+  }
+}
@@ -0,0 +1,98 @@
+package reports
+
+import (
+  "fmt"
+  "sort"
+  "time"
+)
+
+type AnomalyCode string
+
+const (
+  AnomalyRebalanceCostExceedsRevenue AnomalyCode = "rebalance_cost_exceeds_revenue"
+  AnomalyForwardsStopped AnomalyCode = "forwards_stopped"
+)
+
+type Anomaly struct {
+  Date time.Time
+  Code AnomalyCode
+  Reason string
+}
+
+type AnomalyThresholds struct {
+  // CostRevenueRatio flags a day whose rebalance cost is above revenue times
+  // this ratio (1 means cost > revenue).
+  CostRevenueRatio float64
+  // ActiveStreakDays flags a day with zero forwards that follows at least
+  // this many consecutive days with forwards.
+  ActiveStreakDays int
+}
+
+func DefaultAnomalyThresholds() AnomalyThresholds {
+  return AnomalyThresholds{CostRevenueRatio: 1, ActiveStreakDays: 3}
+}
+
+// NormalizeAnomalyThresholds fills zero fields with their defaults and
+// rejects negative ones.
+func NormalizeAnomalyThresholds(thresholds AnomalyThresholds) (AnomalyThresholds, error) {
+  if thresholds.CostRevenueRatio < 0 || thresholds.ActiveStreakDays < 0 {
+    return DefaultAnomalyThresholds(), fmt.Errorf("anomaly thresholds must be positive")
+  }
+  defaults := DefaultAnomalyThresholds()
+  if thresholds.CostRevenueRatio == 0 {
+    thresholds.CostRevenueRatio = defaults.CostRevenueRatio
+  }
+  if thresholds.ActiveStreakDays == 0 {
+    thresholds.ActiveStreakDays = defaults.ActiveStreakDays
+  }
+  return thresholds, nil
+}
+
+func DetectAnomalies(rows []Row, thresholds AnomalyThresholds) []Anomaly {
+  sorted := append([]Row(nil), rows...)
+  sort.Slice(sorted, func(i, j int) bool {
+    return sorted[i].ReportDate.Before(sorted[j].ReportDate)
+  })
+
+  anomalies := []Anomaly{}
+  streak := 0
+  var prev time.Time
+  for _, row := range sorted {
+    date := normalizeReportDate(row.ReportDate)
+    if !prev.IsZero() && !date.Equal(prev.AddDate(0, 0, 1)) {
+      streak = 0
+    }
+    prev = date
+
+    revenue := metricMsat(row.Metrics.ForwardFeeRevenueMsat, row.Metrics.ForwardFeeRevenueSat)
+    cost := metricMsat(row.Metrics.RebalanceFeeCostMsat, row.Metrics.RebalanceFeeCostSat)
+    if cost > 0 && float64(cost) > float64(revenue)*thresholds.CostRevenueRatio {
+      anomalies = append(anomalies, Anomaly{
+        Date: date,
+        Code: AnomalyRebalanceCostExceedsRevenue,
+        Reason: fmt.Sprintf("rebalance cost %d msat above %.2fx revenue %d msat", cost, thresholds.CostRevenueRatio, revenue),
+      })
+    }
+
+    if row.Metrics.ForwardCount > 0 {
+      streak++
+      continue
+    }
+    if thresholds.ActiveStreakDays > 0 && streak >= thresholds.ActiveStreakDays {
+      anomalies = append(anomalies, Anomaly{
+        Date: date,
+        Code: AnomalyForwardsStopped,
+        Reason: fmt.Sprintf("no forwards after %d active days", streak),
+      })
+    }
+    streak = 0
+  }
+  return anomalies
+}
+
+func metricMsat(msat int64, sat int64) int64 {
+  if msat != 0 {
+    return msat
+  }
+  return satToMsat(sat)
+}
@@ -0,0 +1,58 @@
+package reports
+
+import (
+  "testing"
+  "time"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+  day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+  rows := []Row{
+    {ReportDate: day(4), Metrics: Metrics{ForwardCount: 0}},
+    {ReportDate: day(1), Metrics: Metrics{ForwardCount: 5, ForwardFeeRevenueMsat: 10000}},
+    {ReportDate: day(2), Metrics: Metrics{ForwardCount: 3, ForwardFeeRevenueMsat: 2000, RebalanceFeeCostMsat: 5000}},
+    {ReportDate: day(3), Metrics: Metrics{ForwardCount: 4, ForwardFeeRevenueMsat: 8000, RebalanceFeeCostMsat: 8000}},
+  }
+
+  anomalies := DetectAnomalies(rows, DefaultAnomalyThresholds())
+  if len(anomalies) != 2 {
+    t.Fatalf("expected 2 anomalies, got %+v", anomalies)
+  }
+  if anomalies[0].Code != AnomalyRebalanceCostExceedsRevenue || !anomalies[0].Date.Equal(day(2)) {
+    t.Fatalf("unexpected first anomaly: %+v", anomalies[0])
+  }
+  if anomalies[1].Code != AnomalyForwardsStopped || !anomalies[1].Date.Equal(day(4)) {
+    t.Fatalf("unexpected second anomaly: %+v", anomalies[1])
+  }
+
+  strict := DetectAnomalies(rows, AnomalyThresholds{CostRevenueRatio: 0.5, ActiveStreakDays: 4})
+  if len(strict) != 2 || !strict[1].Date.Equal(day(3)) {
+    t.Fatalf("expected ratio 0.5 to flag day 2 and 3 and the streak of 3 to pass, got %+v", strict)
+  }
+}
+
+func TestDetectAnomaliesGapResetsStreak(t *testing.T) {
+  day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+  rows := []Row{
+    {ReportDate: day(1), Metrics: Metrics{ForwardCount: 1}},
+    {ReportDate: day(2), Metrics: Metrics{ForwardCount: 1}},
+    {ReportDate: day(3), Metrics: Metrics{ForwardCount: 1}},
+    {ReportDate: day(6), Metrics: Metrics{ForwardCount: 0}},
+  }
+  if anomalies := DetectAnomalies(rows, DefaultAnomalyThresholds()); len(anomalies) != 0 {
+    t.Fatalf("expected a missing day to reset the streak, got %+v", anomalies)
+  }
+}
+
+func TestNormalizeAnomalyThresholds(t *testing.T) {
+  if _, err := NormalizeAnomalyThresholds(AnomalyThresholds{CostRevenueRatio: -1}); err == nil {
+    t.Fatalf("expected negative ratio to be rejected")
+  }
+  got, err := NormalizeAnomalyThresholds(AnomalyThresholds{ActiveStreakDays: 7})
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if got.CostRevenueRatio != 1 || got.ActiveStreakDays != 7 {
+    t.Fatalf("unexpected thresholds: %+v", got)
+  }
+}
@@ -0,0 +1,181 @@
+package reports
+
+import (
+  "context"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Store interface {
+  EnsureSchema(ctx context.Context) error
+  UpsertDaily(ctx context.Context, row Row) error
+  UpsertDailyReturning(ctx context.Context, row Row) (Row, time.Time, error)
+  UpsertDailyBatch(ctx context.Context, rows []Row) error
+  UpsertBalancesOnly(ctx context.Context, date time.Time, metrics Metrics) error
+  BackfillBalances(ctx context.Context, date time.Time, onchain, lightning *int64) error
+  PruneOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
+  DeleteRange(ctx context.Context, startDate, endDate time.Time) (int64, error)
+  EnsureDaysExist(ctx context.Context, startDate, endDate time.Time) error
+  FetchRange(ctx context.Context, startDate, endDate time.Time) ([]Row, error)
+  FetchRangeOrdered(ctx context.Context, startDate, endDate time.Time, order SortOrder) ([]Row, error)
+  FetchAll(ctx context.Context) ([]Row, error)
+  FetchAllUnbounded(ctx context.Context) ([]Row, error)
+  FetchPage(ctx context.Context, beforeDate time.Time, limit int) ([]Row, time.Time, error)
+  FetchSummaryRange(ctx context.Context, startDate, endDate time.Time) (Summary, error)
+  FetchSummaryAll(ctx context.Context) (Summary, error)
+  FetchRollup(ctx context.Context, startDate, endDate time.Time, granularity Granularity) ([]RollupBucket, error)
+  FetchSummaryBucketed(ctx context.Context, startDate, endDate time.Time, interval string) ([]RollupBucket, error)
+  FetchByWeekday(ctx context.Context, startDate, endDate time.Time) ([7]Metrics, error)
+  LoadPriceTable(ctx context.Context, currency string, startDate, endDate time.Time) (PriceTable, error)
+  UpsertFiatRate(ctx context.Context, date time.Time, currency string, rate float64) error
+  RebuildDailyFromEvents(ctx context.Context, startDate, endDate time.Time, loc *time.Location) ([]Row, error)
+  RebuildChannelDaily(ctx context.Context, startDate, endDate time.Time, loc *time.Location) error
+}
+
+var _ Store = (*PgStore)(nil)
+
+// PgStore writes to db and serves Fetch* and price reads from read, which may
+// be a replica pool. read falls back to db when no replica is configured.
+type PgStore struct {
+  db *pgxpool.Pool
+  read *pgxpool.Pool
+  opts Options
+}
+
+func NewPgStore(db *pgxpool.Pool, opts Options) *PgStore {
+  return &PgStore{db: db, opts: opts}
+}
+
+func NewPgStoreWithReplica(db *pgxpool.Pool, read *pgxpool.Pool, opts Options) *PgStore {
+  return &PgStore{db: db, read: read, opts: opts}
+}
+
+func (p *PgStore) queryContext(ctx context.Context) context.Context {
+  return WithQueryTimeout(ctx, p.opts.QueryTimeout)
+}
+
+func (p *PgStore) reader() *pgxpool.Pool {
+  if p.read != nil {
+    return p.read
+  }
+  return p.db
+}
+
+func (p *PgStore) EnsureSchema(ctx context.Context) error {
+  if err := EnsureSchema(ctx, p.db); err != nil {
+    return err
+  }
+  if p.opts.StoreEvents {
+    if err := EnsureEventsSchema(ctx, p.db); err != nil {
+      return err
+    }
+  }
+  if p.opts.StoreChannels {
+    return EnsureChannelSchema(ctx, p.db)
+  }
+  return nil
+}
+
+func (p *PgStore) UpsertDaily(ctx context.Context, row Row) error {
+  if err := validateForWrite(row, p.opts.StrictValidation); err != nil {
+    return err
+  }
+  return UpsertDaily(p.queryContext(ctx), p.db, row)
+}
+
+func (p *PgStore) UpsertDailyReturning(ctx context.Context, row Row) (Row, time.Time, error) {
+  if err := validateForWrite(row, p.opts.StrictValidation); err != nil {
+    return Row{}, time.Time{}, err
+  }
+  return UpsertDailyReturning(p.queryContext(ctx), p.db, row)
+}
+
+func (p *PgStore) UpsertDailyBatch(ctx context.Context, rows []Row) error {
+  if err := validateBatchForWrite(rows, p.opts.StrictValidation); err != nil {
+    return err
+  }
+  return UpsertDailyBatch(p.queryContext(ctx), p.db, rows)
+}
+
+func (p *PgStore) UpsertBalancesOnly(ctx context.Context, date time.Time, metrics Metrics) error {
+  return UpsertBalancesOnly(p.queryContext(ctx), p.db, date, metrics)
+}
+
+func (p *PgStore) BackfillBalances(ctx context.Context, date time.Time, onchain, lightning *int64) error {
+  return BackfillBalances(p.queryContext(ctx), p.db, date, onchain, lightning)
+}
+
+func (p *PgStore) PruneOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+  return PruneOlderThan(p.queryContext(ctx), p.db, cutoff)
+}
+
+func (p *PgStore) DeleteRange(ctx context.Context, startDate, endDate time.Time) (int64, error) {
+  return DeleteRange(p.queryContext(ctx), p.db, startDate, endDate)
+}
+
+func (p *PgStore) EnsureDaysExist(ctx context.Context, startDate, endDate time.Time) error {
+  return EnsureDaysExist(p.queryContext(ctx), p.db, startDate, endDate)
+}
+
+func (p *PgStore) FetchRange(ctx context.Context, startDate, endDate time.Time) ([]Row, error) {
+  return FetchRange(p.queryContext(ctx), p.reader(), startDate, endDate)
+}
+
+func (p *PgStore) FetchRangeOrdered(ctx context.Context, startDate, endDate time.Time, order SortOrder) ([]Row, error) {
+  return FetchRangeOrdered(p.queryContext(ctx), p.reader(), startDate, endDate, order)
+}
+
+func (p *PgStore) FetchAll(ctx context.Context) ([]Row, error) {
+  return FetchAll(p.queryContext(ctx), p.reader(), p.opts.FetchAllMaxRows)
+}
+
+func (p *PgStore) FetchAllUnbounded(ctx context.Context) ([]Row, error) {
+  return FetchAllUnbounded(p.queryContext(ctx), p.reader())
+}
+
+func (p *PgStore) FetchPage(ctx context.Context, beforeDate time.Time, limit int) ([]Row, time.Time, error) {
+  return FetchPage(p.queryContext(ctx), p.reader(), beforeDate, limit)
+}
+
+func (p *PgStore) FetchSummaryRange(ctx context.Context, startDate, endDate time.Time) (Summary, error) {
+  return FetchSummaryRange(p.queryContext(ctx), p.reader(), startDate, endDate)
+}
+
+// FetchSummaryAll stays on the primary: the result is cached, and a lagging
+// replica would refill the cache with the summary from before the last write.
+func (p *PgStore) FetchSummaryAll(ctx context.Context) (Summary, error) {
+  return FetchSummaryAll(p.queryContext(ctx), p.summaryReader())
+}
+
+func (p *PgStore) summaryReader() *pgxpool.Pool {
+  return p.db
+}
+
+func (p *PgStore) FetchRollup(ctx context.Context, startDate, endDate time.Time, granularity Granularity) ([]RollupBucket, error) {
+  return FetchRollup(p.queryContext(ctx), p.reader(), startDate, endDate, granularity)
+}
+
+func (p *PgStore) FetchSummaryBucketed(ctx context.Context, startDate, endDate time.Time, interval string) ([]RollupBucket, error) {
+  return FetchSummaryBucketed(p.queryContext(ctx), p.reader(), startDate, endDate, interval)
+}
+
+func (p *PgStore) FetchByWeekday(ctx context.Context, startDate, endDate time.Time) ([7]Metrics, error) {
+  return FetchByWeekday(p.queryContext(ctx), p.reader(), startDate, endDate)
+}
+
+func (p *PgStore) LoadPriceTable(ctx context.Context, currency string, startDate, endDate time.Time) (PriceTable, error) {
+  return LoadPriceTable(p.queryContext(ctx), p.reader(), currency, startDate, endDate)
+}
+
+func (p *PgStore) UpsertFiatRate(ctx context.Context, date time.Time, currency string, rate float64) error {
+  return UpsertFiatRate(p.queryContext(ctx), p.db, date, currency, rate)
+}
+
+func (p *PgStore) RebuildDailyFromEvents(ctx context.Context, startDate, endDate time.Time, loc *time.Location) ([]Row, error) {
+  return RebuildDailyFromEvents(p.queryContext(ctx), p.db, startDate, endDate, loc)
+}
+
+func (p *PgStore) RebuildChannelDaily(ctx context.Context, startDate, endDate time.Time, loc *time.Location) error {
+  return RebuildChannelDaily(p.queryContext(ctx), p.db, startDate, endDate, loc)
+}
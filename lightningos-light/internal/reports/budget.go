@@ -0,0 +1,58 @@
+package reports
+
+import (
+  "context"
+  "fmt"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+const MaxRebalanceBudgetWindowDays = 90
+
+// CheckRebalanceBudget compares rebalance spend over the last windowDays days,
+// today included, with budgetSat. Closed days come from reports_daily and
+// todaySat is today's in-progress spend (Service.Today), so window_days=1
+// covers today alone. The returned delta is spend minus budget: positive is
+// the overage, zero or negative is the remaining headroom.
+func CheckRebalanceBudget(ctx context.Context, db *pgxpool.Pool, loc *time.Location, windowDays int, budgetSat int64, todaySat int64) (exceeded bool, delta int64, err error) {
+  if windowDays < 1 || windowDays > MaxRebalanceBudgetWindowDays {
+    return false, 0, fmt.Errorf("window must be between 1 and %d days", MaxRebalanceBudgetWindowDays)
+  }
+  start, end, ok := rebalanceBudgetWindow(time.Now(), loc, windowDays)
+  if db == nil || !ok {
+    exceeded, delta = compareRebalanceBudget(todaySat, budgetSat)
+    return exceeded, delta, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+
+  var spent int64
+  err = db.QueryRow(ctx, `
+select coalesce(sum(rebalance_fee_cost_sats), 0)
+from reports_daily
+where report_date >= $1 and report_date <= $2
+`, start, end).Scan(&spent)
+  if err != nil {
+    return false, 0, err
+  }
+  exceeded, delta = compareRebalanceBudget(spent+todaySat, budgetSat)
+  return exceeded, delta, nil
+}
+
+// rebalanceBudgetWindow returns the closed days of the window: the
+// windowDays-1 days before today. ok is false when there are none.
+func rebalanceBudgetWindow(now time.Time, loc *time.Location, windowDays int) (time.Time, time.Time, bool) {
+  today := dateOnly(now, loc)
+  if windowDays <= 1 {
+    return time.Time{}, time.Time{}, false
+  }
+  end := today.AddDate(0, 0, -1)
+  start := today.AddDate(0, 0, -(windowDays - 1))
+  return normalizeReportDate(start), normalizeReportDate(end), true
+}
+
+func compareRebalanceBudget(spentSat int64, budgetSat int64) (bool, int64) {
+  delta := spentSat - budgetSat
+  return delta > 0, delta
+}
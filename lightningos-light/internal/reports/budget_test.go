@@ -0,0 +1,44 @@
+package reports
+
+import (
+  "context"
+  "testing"
+  "time"
+)
+
+func TestCheckRebalanceBudgetNilDB(t *testing.T) {
+  exceeded, delta, err := CheckRebalanceBudget(context.Background(), nil, time.UTC, 1, 500, 0)
+  if err != nil || exceeded || delta != -500 {
+    t.Fatalf("unexpected nil-db result: %v %d %v", exceeded, delta, err)
+  }
+  exceeded, delta, err = CheckRebalanceBudget(context.Background(), nil, time.UTC, 1, 500, 650)
+  if err != nil || !exceeded || delta != 150 {
+    t.Fatalf("expected today's spend to count against a 1-day window: %v %d %v", exceeded, delta, err)
+  }
+  if _, _, err := CheckRebalanceBudget(context.Background(), nil, time.UTC, 0, 500, 0); err == nil {
+    t.Fatalf("expected error for zero window")
+  }
+}
+
+func TestRebalanceBudgetWindow(t *testing.T) {
+  now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+  if _, _, ok := rebalanceBudgetWindow(now, time.UTC, 1); ok {
+    t.Fatalf("expected a 1-day window to have no closed days")
+  }
+  start, end, ok := rebalanceBudgetWindow(now, time.UTC, 7)
+  if !ok || start.Format("2006-01-02") != "2026-03-04" || end.Format("2006-01-02") != "2026-03-09" {
+    t.Fatalf("unexpected closed days for a 7-day window: %s..%s", start, end)
+  }
+}
+
+func TestCompareRebalanceBudget(t *testing.T) {
+  if exceeded, delta := compareRebalanceBudget(1200, 1000); !exceeded || delta != 200 {
+    t.Fatalf("expected overage of 200, got %v %d", exceeded, delta)
+  }
+  if exceeded, delta := compareRebalanceBudget(1000, 1000); exceeded || delta != 0 {
+    t.Fatalf("expected spend at budget not to exceed, got %v %d", exceeded, delta)
+  }
+  if exceeded, delta := compareRebalanceBudget(300, 1000); exceeded || delta != -700 {
+    t.Fatalf("expected headroom of 700, got %v %d", exceeded, delta)
+  }
+}
@@ -0,0 +1,225 @@
+package reports
+
+import (
+  "context"
+  "fmt"
+  "sort"
+  "time"
+
+  "github.com/jackc/pgx/v5"
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ChannelRow struct {
+  ReportDate time.Time
+  ChannelID uint64
+  ForwardFeeRevenueMsat int64
+  RebalanceFeeCostMsat int64
+  RoutedVolumeMsat int64
+  ForwardCount int64
+  RebalanceCount int64
+}
+
+// ChannelTotal is one channel's sums over a range.
+type ChannelTotal struct {
+  ChannelID uint64
+  Days int64
+  ForwardFeeRevenueMsat int64
+  RebalanceFeeCostMsat int64
+  RoutedVolumeMsat int64
+  ForwardCount int64
+  RebalanceCount int64
+}
+
+func EnsureChannelSchema(ctx context.Context, db *pgxpool.Pool) error {
+  if db == nil {
+    return nil
+  }
+  _, err := db.Exec(ctx, `
+create table if not exists reports_channel_daily (
+  report_date date not null,
+  channel_id bigint not null,
+  forward_fee_revenue_msat bigint not null default 0,
+  rebalance_fee_cost_msat bigint not null default 0,
+  routed_volume_msat bigint not null default 0,
+  forward_count bigint not null default 0,
+  rebalance_count bigint not null default 0,
+  updated_at timestamptz not null default now(),
+  primary key (report_date, channel_id)
+);
+`)
+  return err
+}
+
+// UpsertChannelDaily replaces the stored sums for each (day, channel) in rows.
+func UpsertChannelDaily(ctx context.Context, db *pgxpool.Pool, rows []ChannelRow) (err error) {
+  if db == nil || len(rows) == 0 {
+    return nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  for _, row := range rows {
+    if row.ChannelID == 0 {
+      return fmt.Errorf("channel id required")
+    }
+  }
+
+  tx, err := db.Begin(ctx)
+  if err != nil {
+    return err
+  }
+  defer tx.Rollback(ctx)
+
+  batch := &pgx.Batch{}
+  for _, row := range rows {
+    batch.Queue(`
+insert into reports_channel_daily (
+  report_date, channel_id, forward_fee_revenue_msat, rebalance_fee_cost_msat,
+  routed_volume_msat, forward_count, rebalance_count, updated_at
+) values ($1, $2, $3, $4, $5, $6, $7, now())
+on conflict (report_date, channel_id) do update set
+  forward_fee_revenue_msat = excluded.forward_fee_revenue_msat,
+  rebalance_fee_cost_msat = excluded.rebalance_fee_cost_msat,
+  routed_volume_msat = excluded.routed_volume_msat,
+  forward_count = excluded.forward_count,
+  rebalance_count = excluded.rebalance_count,
+  updated_at = now()
+`, normalizeReportDate(row.ReportDate), int64(row.ChannelID), row.ForwardFeeRevenueMsat, row.RebalanceFeeCostMsat,
+      row.RoutedVolumeMsat, row.ForwardCount, row.RebalanceCount)
+  }
+
+  results := tx.SendBatch(ctx, batch)
+  for range rows {
+    if _, err := results.Exec(); err != nil {
+      _ = results.Close()
+      return err
+    }
+  }
+  if err := results.Close(); err != nil {
+    return err
+  }
+  return tx.Commit(ctx)
+}
+
+func FetchChannelRange(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time) (items []ChannelRow, err error) {
+  if db == nil {
+    return nil, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  rows, err := db.Query(ctx, `
+select report_date, channel_id, forward_fee_revenue_msat, rebalance_fee_cost_msat,
+  routed_volume_msat, forward_count, rebalance_count
+from reports_channel_daily
+where report_date >= $1 and report_date <= $2
+order by report_date asc, channel_id asc
+`, normalizeReportDate(startDate), normalizeReportDate(endDate))
+  if err != nil {
+    return nil, err
+  }
+  defer rows.Close()
+
+  for rows.Next() {
+    var item ChannelRow
+    var channelID int64
+    if err := rows.Scan(&item.ReportDate, &channelID, &item.ForwardFeeRevenueMsat, &item.RebalanceFeeCostMsat,
+      &item.RoutedVolumeMsat, &item.ForwardCount, &item.RebalanceCount); err != nil {
+      return nil, err
+    }
+    item.ChannelID = uint64(channelID)
+    items = append(items, item)
+  }
+  return items, rows.Err()
+}
+
+// TopChannels ranks channels by forward fee revenue over [startDate, endDate].
+func TopChannels(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time, limit int) (items []ChannelTotal, err error) {
+  if db == nil {
+    return nil, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  limit, err = normalizePageLimit(limit)
+  if err != nil {
+    return nil, err
+  }
+  rows, err := db.Query(ctx, `
+select channel_id,
+  count(*),
+  coalesce(sum(forward_fee_revenue_msat), 0)::bigint,
+  coalesce(sum(rebalance_fee_cost_msat), 0)::bigint,
+  coalesce(sum(routed_volume_msat), 0)::bigint,
+  coalesce(sum(forward_count), 0)::bigint,
+  coalesce(sum(rebalance_count), 0)::bigint
+from reports_channel_daily
+where report_date >= $1 and report_date <= $2
+group by channel_id
+order by 3 desc, channel_id asc
+limit $3
+`, normalizeReportDate(startDate), normalizeReportDate(endDate), limit)
+  if err != nil {
+    return nil, err
+  }
+  defer rows.Close()
+
+  for rows.Next() {
+    var item ChannelTotal
+    var channelID int64
+    if err := rows.Scan(&channelID, &item.Days, &item.ForwardFeeRevenueMsat, &item.RebalanceFeeCostMsat,
+      &item.RoutedVolumeMsat, &item.ForwardCount, &item.RebalanceCount); err != nil {
+      return nil, err
+    }
+    item.ChannelID = uint64(channelID)
+    items = append(items, item)
+  }
+  return items, rows.Err()
+}
+
+// channelRowsFromEvents groups events by local day and channel. Forwards are
+// credited to the outgoing channel since its policy set the fee; rebalances
+// are charged to the channel the payment left through. Events without a
+// channel id are skipped.
+func channelRowsFromEvents(events []Event, loc *time.Location) []ChannelRow {
+  if loc == nil {
+    loc = time.Local
+  }
+  type key struct {
+    day time.Time
+    channel uint64
+  }
+  byKey := map[key]*ChannelRow{}
+  var keys []key
+  for _, event := range events {
+    if event.ChanIDOut == 0 {
+      continue
+    }
+    k := key{day: dateOnly(event.OccurredAt, loc), channel: event.ChanIDOut}
+    row, ok := byKey[k]
+    if !ok {
+      row = &ChannelRow{ReportDate: k.day, ChannelID: k.channel}
+      byKey[k] = row
+      keys = append(keys, k)
+    }
+    switch event.Type {
+    case EventForward:
+      row.ForwardFeeRevenueMsat += event.FeeMsat
+      row.RoutedVolumeMsat += event.AmountMsat
+      row.ForwardCount++
+    case EventRebalance:
+      row.RebalanceFeeCostMsat += event.FeeMsat
+      row.RebalanceCount++
+    }
+  }
+  sort.Slice(keys, func(i, j int) bool {
+    if !keys[i].day.Equal(keys[j].day) {
+      return keys[i].day.Before(keys[j].day)
+    }
+    return keys[i].channel < keys[j].channel
+  })
+
+  rows := make([]ChannelRow, 0, len(keys))
+  for _, k := range keys {
+    rows = append(rows, *byKey[k])
+  }
+  return rows
+}
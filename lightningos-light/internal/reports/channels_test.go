@@ -0,0 +1,42 @@
+package reports
+
+import (
+  "context"
+  "testing"
+  "time"
+)
+
+func TestChannelRowsFromEvents(t *testing.T) {
+  events := []Event{
+    {Key: "forward:1", OccurredAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), Type: EventForward, FeeMsat: 1500, AmountMsat: 2000000, ChanIDIn: 7, ChanIDOut: 9},
+    {Key: "forward:2", OccurredAt: time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC), Type: EventForward, FeeMsat: 500, AmountMsat: 1000000, ChanIDIn: 9, ChanIDOut: 3},
+    {Key: "forward:3", OccurredAt: time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC), Type: EventForward, FeeMsat: 2500, AmountMsat: 3000000, ChanIDIn: 3, ChanIDOut: 9},
+    {Key: "payment:a", OccurredAt: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), Type: EventRebalance, FeeMsat: 1000, ChanIDOut: 3},
+    {Key: "payment:b", OccurredAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), Type: EventRebalance, FeeMsat: 200},
+  }
+
+  rows := channelRowsFromEvents(events, time.UTC)
+  if len(rows) != 2 {
+    t.Fatalf("expected 2 rows, got %d: %+v", len(rows), rows)
+  }
+  if rows[0].ChannelID != 3 || rows[1].ChannelID != 9 {
+    t.Fatalf("expected rows sorted by channel, got %+v", rows)
+  }
+  if got := rows[1]; got.ForwardCount != 2 || got.ForwardFeeRevenueMsat != 4000 || got.RoutedVolumeMsat != 5000000 {
+    t.Fatalf("unexpected totals for channel 9: %+v", got)
+  }
+  if got := rows[0]; got.ForwardCount != 1 || got.RebalanceCount != 1 || got.RebalanceFeeCostMsat != 1000 {
+    t.Fatalf("unexpected totals for channel 3: %+v", got)
+  }
+}
+
+func TestChannelMetricsNilDB(t *testing.T) {
+  ctx := context.Background()
+  if err := UpsertChannelDaily(ctx, nil, []ChannelRow{{ChannelID: 1}}); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  items, err := TopChannels(ctx, nil, time.Now(), time.Now(), 10)
+  if err != nil || items != nil {
+    t.Fatalf("expected no results without a database, got %v %v", items, err)
+  }
+}
@@ -0,0 +1,237 @@
+package reports
+
+import (
+  "context"
+  "fmt"
+  "sort"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EventType string
+
+const (
+  EventForward EventType = "forward"
+  EventRebalance EventType = "rebalance"
+)
+
+type Event struct {
+  ID int64
+  Key string
+  OccurredAt time.Time
+  Type EventType
+  FeeMsat int64
+  AmountMsat int64
+  ChanIDIn uint64
+  ChanIDOut uint64
+}
+
+func EnsureEventsSchema(ctx context.Context, db *pgxpool.Pool) error {
+  if db == nil {
+    return nil
+  }
+  _, err := db.Exec(ctx, `
+create table if not exists reports_events (
+  id bigserial primary key,
+  event_key text not null unique,
+  occurred_at timestamptz not null,
+  event_type text not null,
+  fee_msat bigint not null default 0,
+  amount_msat bigint not null default 0,
+  chan_id_in bigint not null default 0,
+  chan_id_out bigint not null default 0
+);
+
+create index if not exists reports_events_occurred_at_idx on reports_events (occurred_at);
+`)
+  return err
+}
+
+func InsertEvent(ctx context.Context, db *pgxpool.Pool, event Event) (err error) {
+  if db == nil {
+    return nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  if err := event.validate(); err != nil {
+    return err
+  }
+  _, err = db.Exec(ctx, `
+insert into reports_events (event_key, occurred_at, event_type, fee_msat, amount_msat, chan_id_in, chan_id_out)
+values ($1, $2, $3, $4, $5, $6, $7)
+on conflict (event_key) do update set
+  occurred_at = excluded.occurred_at,
+  event_type = excluded.event_type,
+  fee_msat = excluded.fee_msat,
+  amount_msat = excluded.amount_msat,
+  chan_id_in = excluded.chan_id_in,
+  chan_id_out = excluded.chan_id_out
+`, event.Key, event.OccurredAt.UTC(), string(event.Type), event.FeeMsat, event.AmountMsat, int64(event.ChanIDIn), int64(event.ChanIDOut))
+  return err
+}
+
+func (e Event) validate() error {
+  if e.Key == "" {
+    return fmt.Errorf("event key required")
+  }
+  if e.Type != EventForward && e.Type != EventRebalance {
+    return fmt.Errorf("invalid event type: %q", e.Type)
+  }
+  return nil
+}
+
+// FetchEvents returns events in [start, end) ordered by id. afterID is the
+// cursor from the previous page (0 for the first page); eventType "" matches
+// all types. The returned cursor is 0 when there are no more pages.
+func FetchEvents(ctx context.Context, db *pgxpool.Pool, start, end time.Time, eventType EventType, afterID int64, limit int) (items []Event, next int64, err error) {
+  if db == nil {
+    return nil, 0, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  limit, err = normalizePageLimit(limit)
+  if err != nil {
+    return nil, 0, err
+  }
+  rows, err := db.Query(ctx, `
+select id, event_key, occurred_at, event_type, fee_msat, amount_msat, chan_id_in, chan_id_out
+from reports_events
+where occurred_at >= $1 and occurred_at < $2
+  and ($3 = '' or event_type = $3)
+  and id > $4
+order by id asc
+limit $5
+`, start.UTC(), end.UTC(), string(eventType), afterID, limit)
+  if err != nil {
+    return nil, 0, err
+  }
+  defer rows.Close()
+
+  for rows.Next() {
+    var event Event
+    var kind string
+    var chanIn, chanOut int64
+    if err := rows.Scan(&event.ID, &event.Key, &event.OccurredAt, &kind, &event.FeeMsat, &event.AmountMsat, &chanIn, &chanOut); err != nil {
+      return nil, 0, err
+    }
+    event.Type = EventType(kind)
+    event.ChanIDIn = uint64(chanIn)
+    event.ChanIDOut = uint64(chanOut)
+    items = append(items, event)
+  }
+  if err := rows.Err(); err != nil {
+    return nil, 0, err
+  }
+  if len(items) == limit {
+    next = items[len(items)-1].ID
+  }
+  return items, next, nil
+}
+
+// RebuildDailyFromEvents recomputes reports_daily for the local days in
+// [startDate, endDate] that have stored events. Days without events are left
+// untouched and existing balance columns are preserved.
+func RebuildDailyFromEvents(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time, loc *time.Location) ([]Row, error) {
+  if db == nil {
+    return nil, nil
+  }
+  if loc == nil {
+    loc = time.Local
+  }
+  start := dateOnly(startDate, loc)
+  end := dateOnly(endDate, loc).AddDate(0, 0, 1)
+  events, err := fetchAllEvents(ctx, db, start, end)
+  if err != nil {
+    return nil, err
+  }
+
+  rebuilt := rowsFromEvents(events, loc)
+  if len(rebuilt) == 0 {
+    return nil, nil
+  }
+
+  existing, err := FetchRange(ctx, db, start, end.AddDate(0, 0, -1))
+  if err != nil {
+    return nil, err
+  }
+  balances := make(map[string]Metrics, len(existing))
+  for _, row := range existing {
+    balances[row.ReportDate.Format("2006-01-02")] = row.Metrics
+  }
+  for i := range rebuilt {
+    if prev, ok := balances[rebuilt[i].ReportDate.Format("2006-01-02")]; ok {
+      rebuilt[i].Metrics.OnchainBalanceSat = prev.OnchainBalanceSat
+      rebuilt[i].Metrics.LightningBalanceSat = prev.LightningBalanceSat
+      rebuilt[i].Metrics.TotalBalanceSat = prev.TotalBalanceSat
+    }
+  }
+  if err := UpsertDailyBatch(ctx, db, rebuilt); err != nil {
+    return nil, err
+  }
+  return rebuilt, nil
+}
+
+// RebuildChannelDaily recomputes reports_channel_daily for the local days in
+// [startDate, endDate] from stored events.
+func RebuildChannelDaily(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time, loc *time.Location) error {
+  if db == nil {
+    return nil
+  }
+  if loc == nil {
+    loc = time.Local
+  }
+  start := dateOnly(startDate, loc)
+  end := dateOnly(endDate, loc).AddDate(0, 0, 1)
+  events, err := fetchAllEvents(ctx, db, start, end)
+  if err != nil {
+    return err
+  }
+  return UpsertChannelDaily(ctx, db, channelRowsFromEvents(events, loc))
+}
+
+func fetchAllEvents(ctx context.Context, db *pgxpool.Pool, start, end time.Time) ([]Event, error) {
+  var events []Event
+  var cursor int64
+  for {
+    page, next, err := FetchEvents(ctx, db, start, end, "", cursor, maxPageLimit)
+    if err != nil {
+      return nil, err
+    }
+    events = append(events, page...)
+    if next == 0 {
+      return events, nil
+    }
+    cursor = next
+  }
+}
+
+func rowsFromEvents(events []Event, loc *time.Location) []Row {
+  if loc == nil {
+    loc = time.Local
+  }
+  var days []time.Time
+  byDay := map[time.Time]*Accumulator{}
+  for _, event := range events {
+    day := dateOnly(event.OccurredAt, loc)
+    acc, ok := byDay[day]
+    if !ok {
+      acc = NewAccumulator()
+      byDay[day] = acc
+      days = append(days, day)
+    }
+    switch event.Type {
+    case EventForward:
+      acc.AddForward(event.FeeMsat, event.AmountMsat)
+    case EventRebalance:
+      acc.AddRebalance(event.FeeMsat)
+    }
+  }
+  sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+  rows := make([]Row, 0, len(days))
+  for _, day := range days {
+    rows = append(rows, Row{ReportDate: day, Metrics: byDay[day].Snapshot()})
+  }
+  return rows
+}
@@ -0,0 +1,42 @@
+package reports
+
+import (
+  "testing"
+  "time"
+)
+
+func TestRowsFromEvents(t *testing.T) {
+  events := []Event{
+    {Key: "forward:1", OccurredAt: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC), Type: EventForward, FeeMsat: 1500, AmountMsat: 2000000},
+    {Key: "forward:2", OccurredAt: time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC), Type: EventForward, FeeMsat: 500, AmountMsat: 1000000},
+    {Key: "payment:a", OccurredAt: time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), Type: EventRebalance, FeeMsat: 1000},
+    {Key: "forward:3", OccurredAt: time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC), Type: EventForward, FeeMsat: 2500, AmountMsat: 3000000},
+  }
+
+  rows := rowsFromEvents(events, time.UTC)
+  if len(rows) != 2 {
+    t.Fatalf("expected 2 rows, got %d", len(rows))
+  }
+  if got := rows[0].ReportDate.Format("2006-01-02"); got != "2026-03-01" {
+    t.Fatalf("expected rows sorted by date, first is %s", got)
+  }
+  day := rows[1].Metrics
+  if day.ForwardCount != 2 || day.ForwardFeeRevenueMsat != 4000 || day.RoutedVolumeSat != 5000 {
+    t.Fatalf("unexpected forward totals: %+v", day)
+  }
+  if day.RebalanceCount != 1 || day.RebalanceFeeCostMsat != 1000 {
+    t.Fatalf("unexpected rebalance totals: %+v", day)
+  }
+}
+
+func TestEventValidate(t *testing.T) {
+  if err := (Event{Type: EventForward}).validate(); err == nil {
+    t.Fatalf("expected error for missing key")
+  }
+  if err := (Event{Key: "x", Type: "swap"}).validate(); err == nil {
+    t.Fatalf("expected error for unknown type")
+  }
+  if err := (Event{Key: "x", Type: EventRebalance}).validate(); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+}
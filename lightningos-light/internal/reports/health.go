@@ -0,0 +1,50 @@
+package reports
+
+import (
+  "context"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgtype"
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CheckWritable opens a transaction on db and asks Postgres whether it could
+// write: a hot standby reports pg_is_in_recovery and a read-only session has
+// transaction_read_only on. The transaction is always rolled back.
+func CheckWritable(ctx context.Context, db *pgxpool.Pool) (writable bool, err error) {
+  if db == nil {
+    return false, ErrNoDatabase
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  tx, err := db.Begin(ctx)
+  if err != nil {
+    return false, err
+  }
+  defer tx.Rollback(ctx)
+
+  var inRecovery bool
+  var readOnly string
+  if err := tx.QueryRow(ctx, "select pg_is_in_recovery(), current_setting('transaction_read_only')").Scan(&inRecovery, &readOnly); err != nil {
+    return false, err
+  }
+  return !inRecovery && readOnly == "off", nil
+}
+
+// LatestUpdatedAt returns the newest reports_daily updated_at, or the zero
+// time when the table is empty.
+func LatestUpdatedAt(ctx context.Context, db *pgxpool.Pool) (latest time.Time, err error) {
+  if db == nil {
+    return time.Time{}, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  var value pgtype.Timestamptz
+  if err := db.QueryRow(ctx, "select max(updated_at) from reports_daily").Scan(&value); err != nil {
+    return time.Time{}, err
+  }
+  if !value.Valid {
+    return time.Time{}, nil
+  }
+  return value.Time, nil
+}
@@ -0,0 +1,18 @@
+package reports
+
+import (
+  "context"
+  "errors"
+  "testing"
+)
+
+func TestHealthChecksNilDB(t *testing.T) {
+  writable, err := CheckWritable(context.Background(), nil)
+  if writable || !errors.Is(err, ErrNoDatabase) {
+    t.Fatalf("expected ErrNoDatabase, got %v %v", writable, err)
+  }
+  latest, err := LatestUpdatedAt(context.Background(), nil)
+  if err != nil || !latest.IsZero() {
+    t.Fatalf("expected zero time, got %v %v", latest, err)
+  }
+}
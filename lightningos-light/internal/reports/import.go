@@ -0,0 +1,38 @@
+package reports
+
+import (
+  "context"
+  "sort"
+)
+
+// ImportRows upserts historical rows in one batch and reports how many dates
+// were new versus already stored. Rows replace existing days wholesale, like
+// UpsertDailyBatch.
+func (s *Service) ImportRows(ctx context.Context, rows []Row) (inserted int, updated int, err error) {
+  if len(rows) == 0 {
+    return 0, 0, nil
+  }
+  sorted := append([]Row(nil), rows...)
+  sort.Slice(sorted, func(i, j int) bool { return sorted[i].ReportDate.Before(sorted[j].ReportDate) })
+
+  existing, err := s.store.FetchRange(ctx, sorted[0].ReportDate, sorted[len(sorted)-1].ReportDate)
+  if err != nil {
+    return 0, 0, err
+  }
+  stored := make(map[string]bool, len(existing))
+  for _, row := range existing {
+    stored[row.ReportDate.Format("2006-01-02")] = true
+  }
+
+  if err := s.store.UpsertDailyBatch(ctx, sorted); err != nil {
+    return 0, 0, err
+  }
+  for _, row := range sorted {
+    if stored[row.ReportDate.Format("2006-01-02")] {
+      updated++
+    } else {
+      inserted++
+    }
+  }
+  return inserted, updated, nil
+}
@@ -45,20 +45,7 @@ func ComputeMetrics(ctx context.Context, lnd *lndclient.Client, tr TimeRange, me
     }
   }
 
-  netMsat := forwardRevenueMsat - rebalanceCostMsat
-  metrics := Metrics{
-    ForwardFeeRevenueSat: forwardRevenueMsat / 1000,
-    ForwardFeeRevenueMsat: forwardRevenueMsat,
-    RebalanceFeeCostSat: rebalanceCostMsat / 1000,
-    RebalanceFeeCostMsat: rebalanceCostMsat,
-    NetRoutingProfitSat: netMsat / 1000,
-    NetRoutingProfitMsat: netMsat,
-    ForwardCount: forwardCount,
-    RebalanceCount: rebalanceCount,
-    RoutedVolumeSat: routedVolumeMsat / 1000,
-    RoutedVolumeMsat: routedVolumeMsat,
-  }
-  return metrics, nil
+  return metricsFromMsat(forwardRevenueMsat, rebalanceCostMsat, routedVolumeMsat, forwardCount, rebalanceCount), nil
 }
 
 func fetchForwardingMetrics(ctx context.Context, lnd *lndclient.Client, startUnix uint64, endUnix uint64) (int64, int64, int64, error) {
@@ -0,0 +1,164 @@
+package reports
+
+import (
+  "bytes"
+  "context"
+  "crypto/hmac"
+  "crypto/sha256"
+  "encoding/hex"
+  "encoding/json"
+  "fmt"
+  "log"
+  "net/http"
+  "strings"
+  "sync"
+  "time"
+)
+
+const (
+  webhookSignatureHeader = "X-LightningOS-Signature"
+  webhookAttempts = 3
+  webhookTimeout = 10 * time.Second
+  webhookBaseDelay = 2 * time.Second
+)
+
+type Notifier interface {
+  NotifyDaily(row Row)
+}
+
+type WebhookNotifier struct {
+  url string
+  secret string
+  client *http.Client
+  logger *log.Logger
+  attempts int
+  baseDelay time.Duration
+  wg sync.WaitGroup
+}
+
+type webhookPayload struct {
+  ReportDate string `json:"report_date"`
+  ForwardFeeRevenueSat int64 `json:"forward_fee_revenue_sats"`
+  ForwardFeeRevenueMsat int64 `json:"forward_fee_revenue_msat"`
+  RebalanceFeeCostSat int64 `json:"rebalance_fee_cost_sats"`
+  RebalanceFeeCostMsat int64 `json:"rebalance_fee_cost_msat"`
+  NetRoutingProfitSat int64 `json:"net_routing_profit_sats"`
+  NetRoutingProfitMsat int64 `json:"net_routing_profit_msat"`
+  ForwardCount int64 `json:"forward_count"`
+  RebalanceCount int64 `json:"rebalance_count"`
+  RoutedVolumeSat int64 `json:"routed_volume_sats"`
+  RoutedVolumeMsat int64 `json:"routed_volume_msat"`
+  OnchainBalanceSat *int64 `json:"onchain_balance_sats"`
+  LightningBalanceSat *int64 `json:"lightning_balance_sats"`
+  TotalBalanceSat *int64 `json:"total_balance_sats"`
+  OnchainRatio *float64 `json:"onchain_ratio"`
+}
+
+func NewWebhookNotifier(url, secret string, logger *log.Logger) *WebhookNotifier {
+  return &WebhookNotifier{
+    url: strings.TrimSpace(url),
+    secret: secret,
+    client: &http.Client{Timeout: webhookTimeout},
+    logger: logger,
+    attempts: webhookAttempts,
+    baseDelay: webhookBaseDelay,
+  }
+}
+
+func (n *WebhookNotifier) NotifyDaily(row Row) {
+  if n == nil || n.url == "" {
+    return
+  }
+  body, err := json.Marshal(newWebhookPayload(row))
+  if err != nil {
+    n.logf("reports: webhook payload failed: %v", err)
+    return
+  }
+  n.wg.Add(1)
+  go func() {
+    defer n.wg.Done()
+    if err := n.deliver(body); err != nil {
+      n.logf("reports: webhook delivery failed: %v", err)
+    }
+  }()
+}
+
+func (n *WebhookNotifier) Wait() {
+  if n == nil {
+    return
+  }
+  n.wg.Wait()
+}
+
+func (n *WebhookNotifier) deliver(body []byte) error {
+  var lastErr error
+  delay := n.baseDelay
+  for attempt := 1; attempt <= n.attempts; attempt++ {
+    lastErr = n.post(body)
+    if lastErr == nil {
+      return nil
+    }
+    if attempt < n.attempts {
+      time.Sleep(delay)
+      delay *= 2
+    }
+  }
+  return lastErr
+}
+
+func (n *WebhookNotifier) post(body []byte) error {
+  ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+  defer cancel()
+
+  req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+  if err != nil {
+    return err
+  }
+  req.Header.Set("Content-Type", "application/json")
+  if n.secret != "" {
+    req.Header.Set(webhookSignatureHeader, "sha256="+signWebhookBody(n.secret, body))
+  }
+
+  resp, err := n.client.Do(req)
+  if err != nil {
+    return err
+  }
+  defer resp.Body.Close()
+  if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+    return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+  }
+  return nil
+}
+
+func (n *WebhookNotifier) logf(format string, args ...any) {
+  if n.logger != nil {
+    n.logger.Printf(format, args...)
+  }
+}
+
+func signWebhookBody(secret string, body []byte) string {
+  mac := hmac.New(sha256.New, []byte(secret))
+  mac.Write(body)
+  return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookPayload(row Row) webhookPayload {
+  metrics := row.Metrics
+  return webhookPayload{
+    ReportDate: row.ReportDate.Format("2006-01-02"),
+    ForwardFeeRevenueSat: metrics.ForwardFeeRevenueSat,
+    ForwardFeeRevenueMsat: metrics.ForwardFeeRevenueMsat,
+    RebalanceFeeCostSat: metrics.RebalanceFeeCostSat,
+    RebalanceFeeCostMsat: metrics.RebalanceFeeCostMsat,
+    NetRoutingProfitSat: metrics.NetRoutingProfitSat,
+    NetRoutingProfitMsat: metrics.NetRoutingProfitMsat,
+    ForwardCount: metrics.ForwardCount,
+    RebalanceCount: metrics.RebalanceCount,
+    RoutedVolumeSat: metrics.RoutedVolumeSat,
+    RoutedVolumeMsat: metrics.RoutedVolumeMsat,
+    OnchainBalanceSat: metrics.OnchainBalanceSat,
+    LightningBalanceSat: metrics.LightningBalanceSat,
+    TotalBalanceSat: metrics.TotalBalanceSat,
+    OnchainRatio: metrics.OnchainRatio,
+  }
+}
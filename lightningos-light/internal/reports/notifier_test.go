@@ -0,0 +1,67 @@
+package reports
+
+import (
+  "encoding/json"
+  "io"
+  "net/http"
+  "net/http/httptest"
+  "sync/atomic"
+  "testing"
+  "time"
+)
+
+func TestWebhookNotifierSignsAndRetries(t *testing.T) {
+  var calls int32
+  var gotSignature string
+  var gotBody []byte
+  srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if atomic.AddInt32(&calls, 1) == 1 {
+      w.WriteHeader(http.StatusInternalServerError)
+      return
+    }
+    gotSignature = r.Header.Get(webhookSignatureHeader)
+    gotBody, _ = io.ReadAll(r.Body)
+    w.WriteHeader(http.StatusNoContent)
+  }))
+  defer srv.Close()
+
+  notifier := NewWebhookNotifier(srv.URL, "s3cret", nil)
+  notifier.baseDelay = time.Millisecond
+  notifier.NotifyDaily(Row{
+    ReportDate: time.Date(2026, 4, 2, 0, 0, 0, 0, time.UTC),
+    Metrics: Metrics{ForwardFeeRevenueSat: 12, ForwardCount: 3},
+  })
+  notifier.Wait()
+
+  if atomic.LoadInt32(&calls) != 2 {
+    t.Fatalf("expected 2 attempts, got %d", calls)
+  }
+  if gotSignature != "sha256="+signWebhookBody("s3cret", gotBody) {
+    t.Fatalf("unexpected signature %q", gotSignature)
+  }
+  var payload webhookPayload
+  if err := json.Unmarshal(gotBody, &payload); err != nil {
+    t.Fatalf("invalid payload: %v", err)
+  }
+  if payload.ReportDate != "2026-04-02" || payload.ForwardFeeRevenueSat != 12 || payload.ForwardCount != 3 {
+    t.Fatalf("unexpected payload: %+v", payload)
+  }
+}
+
+func TestWebhookNotifierGivesUpAfterAttempts(t *testing.T) {
+  var calls int32
+  srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    atomic.AddInt32(&calls, 1)
+    w.WriteHeader(http.StatusBadGateway)
+  }))
+  defer srv.Close()
+
+  notifier := NewWebhookNotifier(srv.URL, "", nil)
+  notifier.baseDelay = time.Millisecond
+  notifier.NotifyDaily(Row{ReportDate: time.Now()})
+  notifier.Wait()
+
+  if atomic.LoadInt32(&calls) != webhookAttempts {
+    t.Fatalf("expected %d attempts, got %d", webhookAttempts, calls)
+  }
+}
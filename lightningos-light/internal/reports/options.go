@@ -0,0 +1,76 @@
+package reports
+
+import (
+  "fmt"
+  "time"
+)
+
+const (
+  defaultQueryTimeout = 60 * time.Second
+  defaultMaxRangeDays = 730
+  defaultFetchAllMaxRows = 3650
+)
+
+// Options carries the reports section of config.yaml. Service and PgStore each
+// hold their own copy, so nothing here is process-wide state.
+type Options struct {
+  // Timezone is the zone whose midnight starts a report day; nil means the
+  // server's local zone.
+  Timezone *time.Location
+  // QueryTimeout bounds every reports query. Zero disables the limit.
+  QueryTimeout time.Duration
+  // MaxRangeDays caps custom report ranges (inclusive days).
+  MaxRangeDays int
+  // FetchAllMaxRows caps how many rows FetchAll will load. Zero or less
+  // disables the check.
+  FetchAllMaxRows int
+  // StrictValidation rejects rows that fail Row.Validate on write.
+  StrictValidation bool
+  // StoreEvents keeps every forward and rebalance in reports_events. It is
+  // off by default so low-resource nodes only keep the daily sums.
+  StoreEvents bool
+  // StoreChannels keeps per-channel daily sums in reports_channel_daily.
+  StoreChannels bool
+  Anomalies AnomalyThresholds
+  // FiatCurrencies are the currencies whose daily BTC price the nightly run
+  // stores in reports_fiat_rates; PriceURL is where it is fetched from.
+  FiatCurrencies []string
+  PriceURL string
+}
+
+func DefaultOptions() Options {
+  return Options{
+    QueryTimeout: defaultQueryTimeout,
+    MaxRangeDays: defaultMaxRangeDays,
+    FetchAllMaxRows: defaultFetchAllMaxRows,
+    Anomalies: DefaultAnomalyThresholds(),
+  }
+}
+
+// Location is the zone report days are cut in.
+func (o Options) Location() *time.Location {
+  if o.Timezone != nil {
+    return o.Timezone
+  }
+  return time.Local
+}
+
+func (o Options) RangeDaysLimit() int {
+  if o.MaxRangeDays < 1 {
+    return defaultMaxRangeDays
+  }
+  return o.MaxRangeDays
+}
+
+// LoadTimezone resolves reports.timezone. An empty name returns nil, which
+// Options treats as the server's local zone.
+func LoadTimezone(name string) (*time.Location, error) {
+  if name == "" {
+    return nil, nil
+  }
+  loc, err := time.LoadLocation(name)
+  if err != nil {
+    return nil, fmt.Errorf("invalid reports timezone %q: %w", name, err)
+  }
+  return loc, nil
+}
@@ -0,0 +1,101 @@
+package reports
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "net/url"
+  "strconv"
+  "time"
+)
+
+// DefaultPriceURL is mempool.space's historical price endpoint. Any service
+// answering the same query and JSON shape can be configured instead.
+const DefaultPriceURL = "https://mempool.space/api/v1/historical-price"
+
+const priceFetchTimeout = 15 * time.Second
+
+// PriceFetcher loads the BTC closing price of a report day.
+type PriceFetcher struct {
+  URL string
+  client *http.Client
+}
+
+func NewPriceFetcher(rawURL string) *PriceFetcher {
+  if rawURL == "" {
+    rawURL = DefaultPriceURL
+  }
+  return &PriceFetcher{URL: rawURL, client: &http.Client{Timeout: priceFetchTimeout}}
+}
+
+// FetchRate asks for the price at noon UTC of date, which the endpoint
+// resolves to that day's sample.
+func (f *PriceFetcher) FetchRate(ctx context.Context, date time.Time, currency string) (float64, error) {
+  target, err := url.Parse(f.URL)
+  if err != nil {
+    return 0, fmt.Errorf("invalid price url: %w", err)
+  }
+  query := target.Query()
+  query.Set("currency", currency)
+  query.Set("timestamp", strconv.FormatInt(normalizeReportDate(date).Add(12*time.Hour).Unix(), 10))
+  target.RawQuery = query.Encode()
+
+  req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+  if err != nil {
+    return 0, err
+  }
+  resp, err := f.client.Do(req)
+  if err != nil {
+    return 0, err
+  }
+  defer resp.Body.Close()
+  body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+  if err != nil {
+    return 0, err
+  }
+  if resp.StatusCode != http.StatusOK {
+    return 0, fmt.Errorf("price lookup failed: %s", resp.Status)
+  }
+  return parseHistoricalPrice(body, currency)
+}
+
+func parseHistoricalPrice(body []byte, currency string) (float64, error) {
+  var payload struct {
+    Prices []map[string]float64 `json:"prices"`
+  }
+  if err := json.Unmarshal(body, &payload); err != nil {
+    return 0, fmt.Errorf("invalid price response: %w", err)
+  }
+  if len(payload.Prices) == 0 {
+    return 0, fmt.Errorf("%w: no %s price returned", ErrRateUnavailable, currency)
+  }
+  rate, ok := payload.Prices[0][currency]
+  if !ok || rate <= 0 {
+    return 0, fmt.Errorf("%w: no %s price returned", ErrRateUnavailable, currency)
+  }
+  return rate, nil
+}
+
+// StoreFiatRates fetches and stores the rate of each currency for date. A
+// failed currency does not stop the others; the errors are joined.
+func StoreFiatRates(ctx context.Context, store Store, fetcher *PriceFetcher, date time.Time, currencies []string) error {
+  var errs []error
+  for _, raw := range currencies {
+    currency, err := NormalizeCurrency(raw)
+    if err != nil {
+      errs = append(errs, err)
+      continue
+    }
+    rate, err := fetcher.FetchRate(ctx, date, currency)
+    if err == nil {
+      err = store.UpsertFiatRate(ctx, date, currency, rate)
+    }
+    if err != nil {
+      errs = append(errs, fmt.Errorf("%s: %w", currency, err))
+    }
+  }
+  return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package reports
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "strings"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrRateUnavailable = errors.New("fiat rate unavailable")
+
+type PriceProvider interface {
+  RateFor(date time.Time) (float64, error)
+}
+
+type PriceTable map[string]float64
+
+func (t PriceTable) RateFor(date time.Time) (float64, error) {
+  rate, ok := t[normalizeReportDate(date).Format("2006-01-02")]
+  if !ok || rate <= 0 {
+    return 0, ErrRateUnavailable
+  }
+  return rate, nil
+}
+
+type FiatMetrics struct {
+  ForwardFeeRevenue float64
+  RebalanceFeeCost float64
+  NetRoutingProfit float64
+  RoutedVolume float64
+}
+
+func NormalizeCurrency(value string) (string, error) {
+  currency := strings.ToUpper(strings.TrimSpace(value))
+  if len(currency) != 3 {
+    return "", fmt.Errorf("invalid currency: %s", value)
+  }
+  for _, ch := range currency {
+    if ch < 'A' || ch > 'Z' {
+      return "", fmt.Errorf("invalid currency: %s", value)
+    }
+  }
+  return currency, nil
+}
+
+func ConvertToFiat(row Row, provider PriceProvider) (FiatMetrics, float64, bool) {
+  if provider == nil {
+    return FiatMetrics{}, 0, false
+  }
+  rate, err := provider.RateFor(row.ReportDate)
+  if err != nil {
+    return FiatMetrics{}, 0, false
+  }
+  metrics := row.Metrics
+  fillMsatFromSat(&metrics)
+  return FiatMetrics{
+    ForwardFeeRevenue: msatToFiat(metrics.ForwardFeeRevenueMsat, rate),
+    RebalanceFeeCost: msatToFiat(metrics.RebalanceFeeCostMsat, rate),
+    NetRoutingProfit: msatToFiat(metrics.NetRoutingProfitMsat, rate),
+    RoutedVolume: msatToFiat(metrics.RoutedVolumeMsat, rate),
+  }, rate, true
+}
+
+func msatToFiat(msat int64, rate float64) float64 {
+  return float64(msat) / 1000 / 100000000 * rate
+}
+
+func LoadPriceTable(ctx context.Context, db *pgxpool.Pool, currency string, startDate, endDate time.Time) (prices PriceTable, err error) {
+  table := PriceTable{}
+  if db == nil {
+    return table, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  rows, err := db.Query(ctx, `
+select rate_date, rate
+from reports_fiat_rates
+where currency = $1 and rate_date >= $2 and rate_date <= $3
+`, currency, normalizeReportDate(startDate), normalizeReportDate(endDate))
+  if err != nil {
+    return nil, err
+  }
+  defer rows.Close()
+
+  for rows.Next() {
+    var rateDate time.Time
+    var rate float64
+    if err := rows.Scan(&rateDate, &rate); err != nil {
+      return nil, err
+    }
+    table[rateDate.Format("2006-01-02")] = rate
+  }
+  return table, rows.Err()
+}
+
+func UpsertFiatRate(ctx context.Context, db *pgxpool.Pool, date time.Time, currency string, rate float64) (err error) {
+  if db == nil {
+    return nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  _, err = db.Exec(ctx, `
+insert into reports_fiat_rates (rate_date, currency, rate)
+values ($1, $2, $3)
+on conflict (rate_date, currency) do update set
+  rate = excluded.rate,
+  updated_at = now()
+`, normalizeReportDate(date), currency, rate)
+  return err
+}
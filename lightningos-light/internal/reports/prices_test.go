@@ -0,0 +1,90 @@
+package reports
+
+import (
+  "context"
+  "errors"
+  "math"
+  "net/http"
+  "net/http/httptest"
+  "net/url"
+  "testing"
+  "time"
+)
+
+func TestConvertToFiat(t *testing.T) {
+  day := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+  table := PriceTable{"2026-01-15": 100000}
+  row := Row{
+    ReportDate: day,
+    Metrics: Metrics{NetRoutingProfitSat: 1000, RoutedVolumeMsat: 50000000},
+  }
+
+  fiat, rate, ok := ConvertToFiat(row, table)
+  if !ok || rate != 100000 {
+    t.Fatalf("expected rate for %v", day)
+  }
+  if math.Abs(fiat.NetRoutingProfit-1) > 1e-9 {
+    t.Fatalf("expected 1.00 profit, got %f", fiat.NetRoutingProfit)
+  }
+  if math.Abs(fiat.RoutedVolume-50) > 1e-9 {
+    t.Fatalf("expected 50.00 volume, got %f", fiat.RoutedVolume)
+  }
+
+  if _, _, ok := ConvertToFiat(Row{ReportDate: day.AddDate(0, 0, 1)}, table); ok {
+    t.Fatalf("expected missing rate for next day")
+  }
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+  if got, err := NormalizeCurrency(" usd "); err != nil || got != "USD" {
+    t.Fatalf("expected USD, got %q (%v)", got, err)
+  }
+  if _, err := NormalizeCurrency("us1"); err == nil {
+    t.Fatalf("expected invalid currency error")
+  }
+}
+
+type fiatRateStore struct {
+  Store
+  rates map[string]float64
+}
+
+func (f *fiatRateStore) UpsertFiatRate(ctx context.Context, date time.Time, currency string, rate float64) error {
+  f.rates[date.Format("2006-01-02")+" "+currency] = rate
+  return nil
+}
+
+func TestStoreFiatRates(t *testing.T) {
+  var gotQuery url.Values
+  srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    gotQuery = r.URL.Query()
+    switch r.URL.Query().Get("currency") {
+    case "USD":
+      _, _ = w.Write([]byte(`{"prices":[{"time":1773489600,"USD":71234.5}],"exchangeRates":{"USDEUR":0.92}}`))
+    default:
+      _, _ = w.Write([]byte(`{"prices":[],"exchangeRates":{}}`))
+    }
+  }))
+  defer srv.Close()
+
+  store := &fiatRateStore{rates: map[string]float64{}}
+  day := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+  err := StoreFiatRates(context.Background(), store, NewPriceFetcher(srv.URL), day, []string{"usd", "XYZ"})
+  if !errors.Is(err, ErrRateUnavailable) {
+    t.Fatalf("expected the missing currency to be reported, got %v", err)
+  }
+  if got := store.rates["2026-03-14 USD"]; got != 71234.5 {
+    t.Fatalf("expected the USD rate to be stored, got %+v", store.rates)
+  }
+  if len(store.rates) != 1 {
+    t.Fatalf("expected only USD to be stored, got %+v", store.rates)
+  }
+  if ts := gotQuery.Get("timestamp"); ts != "1773489600" {
+    t.Fatalf("expected noon UTC of the report day, got %s", ts)
+  }
+
+  table := PriceTable{"2026-03-14": store.rates["2026-03-14 USD"]}
+  if _, rate, ok := ConvertToFiat(Row{ReportDate: day}, table); !ok || rate != 71234.5 {
+    t.Fatalf("expected the stored rate to convert the day")
+  }
+}
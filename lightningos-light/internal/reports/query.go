@@ -0,0 +1,50 @@
+package reports
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "time"
+)
+
+// ErrQueryTimeout wraps errors from queries that ran past the query timeout
+// (or the caller's deadline), so callers can tell them apart from SQL errors.
+var ErrQueryTimeout = errors.New("reports query timed out")
+
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout sets the timeout startQuery applies to reports queries run
+// with ctx, so a slow scan cannot hold a pool connection forever. Zero
+// disables the limit; without it queries use the 60s default. PgStore applies
+// Options.QueryTimeout this way.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+  return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+func queryTimeout(ctx context.Context) time.Duration {
+  if timeout, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok {
+    return timeout
+  }
+  return defaultQueryTimeout
+}
+
+func startQuery(ctx context.Context) (context.Context, func(*error)) {
+  cancel := func() {}
+  if timeout := queryTimeout(ctx); timeout > 0 {
+    ctx, cancel = context.WithTimeout(ctx, timeout)
+  }
+  return ctx, func(err *error) {
+    defer cancel()
+    *err = queryError(ctx, *err)
+  }
+}
+
+func queryError(ctx context.Context, err error) error {
+  if err == nil || errors.Is(err, ErrQueryTimeout) {
+    return err
+  }
+  if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+    return fmt.Errorf("%w: %v", ErrQueryTimeout, err)
+  }
+  return err
+}
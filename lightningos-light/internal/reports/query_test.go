@@ -0,0 +1,46 @@
+package reports
+
+import (
+  "context"
+  "errors"
+  "testing"
+  "time"
+)
+
+func TestStartQueryMapsTimeout(t *testing.T) {
+  ctx, done := startQuery(WithQueryTimeout(context.Background(), time.Millisecond))
+  <-ctx.Done()
+  err := ctx.Err()
+  done(&err)
+  if !errors.Is(err, ErrQueryTimeout) {
+    t.Fatalf("expected ErrQueryTimeout, got %v", err)
+  }
+}
+
+func TestStartQueryKeepsSQLErrors(t *testing.T) {
+  _, done := startQuery(context.Background())
+  err := errors.New("syntax error at or near \"selec\"")
+  done(&err)
+  if errors.Is(err, ErrQueryTimeout) {
+    t.Fatalf("SQL error must not be reported as a timeout: %v", err)
+  }
+
+  var nilErr error
+  _, done = startQuery(context.Background())
+  done(&nilErr)
+  if nilErr != nil {
+    t.Fatalf("expected nil error, got %v", nilErr)
+  }
+}
+
+func TestQueryTimeoutFromContext(t *testing.T) {
+  if got := queryTimeout(context.Background()); got != defaultQueryTimeout {
+    t.Fatalf("expected default timeout, got %s", got)
+  }
+  ctx, done := startQuery(WithQueryTimeout(context.Background(), 0))
+  if _, ok := ctx.Deadline(); ok {
+    t.Fatalf("expected zero timeout to disable the deadline")
+  }
+  var err error
+  done(&err)
+}
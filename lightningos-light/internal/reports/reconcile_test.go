@@ -0,0 +1,48 @@
+package reports
+
+import (
+  "context"
+  "testing"
+  "time"
+)
+
+func TestReconcileRows(t *testing.T) {
+  good := Row{
+    ReportDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+    Metrics: Metrics{
+      ForwardFeeRevenueSat: 10, ForwardFeeRevenueMsat: 10500,
+      RebalanceFeeCostSat: 2, RebalanceFeeCostMsat: 2900,
+      NetRoutingProfitSat: 7, NetRoutingProfitMsat: 7600,
+      RoutedVolumeSat: 1000, RoutedVolumeMsat: 1000999,
+    },
+  }
+  legacy := Row{ReportDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)}
+  legacy.Metrics = Metrics{ForwardFeeRevenueSat: 10, RebalanceFeeCostSat: 3, NetRoutingProfitSat: 7}
+  fillMsatFromSat(&legacy.Metrics)
+  bad := Row{
+    ReportDate: time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC),
+    Metrics: Metrics{
+      ForwardFeeRevenueSat: 10, ForwardFeeRevenueMsat: 10000,
+      RebalanceFeeCostSat: 4, RebalanceFeeCostMsat: 3000,
+      NetRoutingProfitSat: 9, NetRoutingProfitMsat: 9000,
+    },
+  }
+
+  got := reconcileRows([]Row{good, legacy, bad})
+  if len(got) != 2 {
+    t.Fatalf("expected 2 discrepancies, got %+v", got)
+  }
+  if got[0].Field != "net_routing_profit_msat" || got[0].Expected != 7000 || got[0].Actual != 9000 || !got[0].ReportDate.Equal(bad.ReportDate) {
+    t.Fatalf("unexpected msat discrepancy: %+v", got[0])
+  }
+  if got[1].Field != "rebalance_fee_cost_sats" || got[1].Expected != 3 || got[1].Actual != 4 {
+    t.Fatalf("unexpected sat discrepancy: %+v", got[1])
+  }
+}
+
+func TestReconcileDailyNilDB(t *testing.T) {
+  items, err := ReconcileDaily(context.Background(), nil, time.Now(), time.Now())
+  if err != nil || items != nil {
+    t.Fatalf("expected nil-db no-op, got %v %v", items, err)
+  }
+}
@@ -0,0 +1,26 @@
+package reports
+
+import "time"
+
+func FillDailyGaps(items []Row, startDate, endDate time.Time) []Row {
+  start := normalizeReportDate(startDate)
+  end := normalizeReportDate(endDate)
+  if startDate.IsZero() || endDate.IsZero() || end.Before(start) {
+    return items
+  }
+
+  byDate := make(map[string]Row, len(items))
+  for _, item := range items {
+    byDate[item.ReportDate.Format("2006-01-02")] = item
+  }
+
+  filled := make([]Row, 0, int(end.Sub(start).Hours()/24)+1)
+  for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+    if item, ok := byDate[day.Format("2006-01-02")]; ok {
+      filled = append(filled, item)
+      continue
+    }
+    filled = append(filled, Row{ReportDate: day})
+  }
+  return filled
+}
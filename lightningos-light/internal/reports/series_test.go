@@ -0,0 +1,37 @@
+package reports
+
+import (
+  "testing"
+  "time"
+)
+
+func TestFillDailyGaps(t *testing.T) {
+  start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+  end := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+  items := []Row{
+    {ReportDate: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Metrics: Metrics{NetRoutingProfitSat: 10}},
+    {ReportDate: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC), Metrics: Metrics{NetRoutingProfitSat: 20}},
+  }
+
+  filled := FillDailyGaps(items, start, end)
+  if len(filled) != 5 {
+    t.Fatalf("expected 5 days, got %d", len(filled))
+  }
+  want := []int64{0, 10, 0, 20, 0}
+  for i, row := range filled {
+    if !sameDate(row.ReportDate, start.AddDate(0, 0, i)) {
+      t.Fatalf("unexpected date at %d: %v", i, row.ReportDate)
+    }
+    if row.Metrics.NetRoutingProfitSat != want[i] {
+      t.Fatalf("unexpected profit at %d: %d", i, row.Metrics.NetRoutingProfitSat)
+    }
+  }
+}
+
+func TestFillDailyGapsInvalidRange(t *testing.T) {
+  start := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+  end := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+  if filled := FillDailyGaps(nil, start, end); len(filled) != 0 {
+    t.Fatalf("expected no rows for inverted range, got %d", len(filled))
+  }
+}
@@ -2,21 +2,22 @@ package reports
 
 import (
   "context"
+  "errors"
   "log"
   "sync"
   "time"
 
   "lightningos-light/internal/lndclient"
-
-  "github.com/jackc/pgx/v5/pgxpool"
 )
 
 const defaultLiveTTL = 60 * time.Second
 
 type Service struct {
-  db *pgxpool.Pool
+  store Store
   lnd *lndclient.Client
   logger *log.Logger
+  notifier Notifier
+  opts Options
 
   liveTTL time.Duration
   liveMu sync.Mutex
@@ -30,17 +31,45 @@ type liveSnapshot struct {
   LookbackHours int
 }
 
-func NewService(db *pgxpool.Pool, lnd *lndclient.Client, logger *log.Logger) *Service {
+func NewService(store Store, lnd *lndclient.Client, logger *log.Logger, opts Options) *Service {
   return &Service{
-    db: db,
+    store: store,
     lnd: lnd,
     logger: logger,
+    opts: opts,
     liveTTL: defaultLiveTTL,
   }
 }
 
+func (s *Service) Options() Options {
+  return s.opts
+}
+
+// Location is the zone report days are cut in.
+func (s *Service) Location() *time.Location {
+  return s.opts.Location()
+}
+
+// QueryContext applies the configured query timeout for callers that run
+// the package's free functions (TopChannels, ReconcileDaily, ...) directly.
+func (s *Service) QueryContext(ctx context.Context) context.Context {
+  return WithQueryTimeout(ctx, s.opts.QueryTimeout)
+}
+
+func (s *Service) ValidateCustomRange(startDate, endDate time.Time) error {
+  return ValidateCustomRange(startDate, endDate, s.Location(), s.opts.RangeDaysLimit())
+}
+
+func (s *Service) DetectAnomalies(rows []Row) []Anomaly {
+  return DetectAnomalies(rows, s.opts.Anomalies)
+}
+
+func (s *Service) SetNotifier(notifier Notifier) {
+  s.notifier = notifier
+}
+
 func (s *Service) EnsureSchema(ctx context.Context) error {
-  return EnsureSchema(ctx, s.db)
+  return s.store.EnsureSchema(ctx)
 }
 
 func (s *Service) RunDaily(ctx context.Context, reportDate time.Time, loc *time.Location, override *RebalanceOverride) (Row, error) {
@@ -54,22 +83,79 @@ func (s *Service) RunDaily(ctx context.Context, reportDate time.Time, loc *time.
   }
 
   row := Row{ReportDate: dateOnly(reportDate, loc), Metrics: metrics}
-  if err := UpsertDaily(ctx, s.db, row); err != nil {
+  if err := s.store.UpsertDaily(ctx, row); err != nil {
     return Row{}, err
   }
+  if s.opts.StoreChannels {
+    if err := s.store.RebuildChannelDaily(ctx, reportDate, reportDate, loc); err != nil {
+      return Row{}, err
+    }
+  }
+  if s.notifier != nil {
+    s.notifier.NotifyDaily(row)
+  }
   return row, nil
 }
 
+// ErrEventsDisabled is returned by RebuildFromEvents when reports.store_events
+// is off, since there are no stored events to rebuild from.
+var ErrEventsDisabled = errors.New("reports.store_events is disabled")
+
+// RebuildFromEvents recomputes the daily rows (and channel rows when enabled)
+// for [startDate, endDate] from reports_events instead of asking LND. Days
+// without events get a zero row so the rebuilt range has no gaps.
+func (s *Service) RebuildFromEvents(ctx context.Context, startDate, endDate time.Time, loc *time.Location) ([]Row, error) {
+  if !s.opts.StoreEvents {
+    return nil, ErrEventsDisabled
+  }
+  rows, err := s.store.RebuildDailyFromEvents(ctx, startDate, endDate, loc)
+  if err != nil {
+    return nil, err
+  }
+  if err := s.store.EnsureDaysExist(ctx, dateOnly(startDate, loc), dateOnly(endDate, loc)); err != nil {
+    return nil, err
+  }
+  if s.opts.StoreChannels {
+    if err := s.store.RebuildChannelDaily(ctx, startDate, endDate, loc); err != nil {
+      return nil, err
+    }
+  }
+  return rows, nil
+}
+
+// RecordFiatRates stores the BTC price of reportDate for each configured fiat
+// currency so ?currency= conversions have a rate for the day.
+func (s *Service) RecordFiatRates(ctx context.Context, reportDate time.Time, loc *time.Location) error {
+  if len(s.opts.FiatCurrencies) == 0 {
+    return nil
+  }
+  return StoreFiatRates(ctx, s.store, NewPriceFetcher(s.opts.PriceURL), dateOnly(reportDate, loc), s.opts.FiatCurrencies)
+}
+
+// Flush persists the partial metrics for the current local day so a restart
+// does not drop them. The nightly run overwrites the row once the day closes.
+func (s *Service) Flush(ctx context.Context, now time.Time, loc *time.Location) error {
+  if s == nil || s.store == nil || s.lnd == nil {
+    return nil
+  }
+  tr := BuildTimeRangeForToday(now, loc)
+  metrics, err := ComputeMetrics(ctx, s.lnd, tr, false, nil)
+  if err != nil {
+    return err
+  }
+  return s.store.UpsertDaily(ctx, Row{ReportDate: tr.StartLocal, Metrics: metrics})
+}
+
 func (s *Service) Range(ctx context.Context, key string, now time.Time, loc *time.Location) ([]Row, DateRange, error) {
   dr, err := ResolveRangeWindow(now, loc, key)
   if err != nil {
     return nil, dr, err
   }
   if dr.All {
-    items, err := FetchAll(ctx, s.db)
+    items, err := s.store.FetchAll(ctx)
     return items, dr, err
   }
-  items, err := FetchRange(ctx, s.db, dr.StartDate, dr.EndDate)
+  items, err := s.store.FetchRange(ctx, dr.StartDate, dr.EndDate)
   return items, dr, err
 }
 
@@ -79,19 +165,27 @@ func (s *Service) Summary(ctx context.Context, key string, now time.Time, loc *t
     return Summary{}, dr, err
   }
   if dr.All {
-    summary, err := FetchSummaryAll(ctx, s.db)
+    summary, err := s.store.FetchSummaryAll(ctx)
     return summary, dr, err
   }
-  summary, err := FetchSummaryRange(ctx, s.db, dr.StartDate, dr.EndDate)
+  summary, err := s.store.FetchSummaryRange(ctx, dr.StartDate, dr.EndDate)
   return summary, dr, err
 }
 
 func (s *Service) CustomRange(ctx context.Context, startDate, endDate time.Time) ([]Row, error) {
-  return FetchRange(ctx, s.db, startDate, endDate)
+  return s.store.FetchRange(ctx, startDate, endDate)
+}
+
+func (s *Service) CustomRangeOrdered(ctx context.Context, startDate, endDate time.Time, order SortOrder) ([]Row, error) {
+  return s.store.FetchRangeOrdered(ctx, startDate, endDate, order)
 }
 
 func (s *Service) CustomSummary(ctx context.Context, startDate, endDate time.Time) (Summary, error) {
-  return FetchSummaryRange(ctx, s.db, startDate, endDate)
+  return s.store.FetchSummaryRange(ctx, startDate, endDate)
+}
+
+func (s *Service) PriceTable(ctx context.Context, currency string, startDate, endDate time.Time) (PriceTable, error) {
+  return s.store.LoadPriceTable(ctx, currency, startDate, endDate)
 }
 
 func (s *Service) Live(ctx context.Context, now time.Time, loc *time.Location, lookbackHours int) (TimeRange, Metrics, error) {
@@ -125,6 +219,41 @@ func (s *Service) Live(ctx context.Context, now time.Time, loc *time.Location, l
   return tr, metrics, nil
 }
 
+// Today merges the stored row for the current UTC day (written by Flush on
+// shutdown, if any) with the live totals counted since the process started.
+// Flush stores the report-zone day, so the row is only merged when that day
+// is the UTC day; otherwise the live totals are returned alone.
+func (s *Service) Today(ctx context.Context, now time.Time) (Today, error) {
+  day, since, live := liveToday.Snapshot(now)
+  result := Today{Date: day, LiveSince: since, Metrics: live}
+  if !reportDayIsUTCDay(day, s.Location()) {
+    return result, nil
+  }
+  rows, err := s.store.FetchRange(ctx, day, day)
+  if err != nil {
+    return Today{}, err
+  }
+  if len(rows) == 0 {
+    return result, nil
+  }
+  stored := rows[0].Metrics
+  result.Persisted = true
+  result.Metrics = mergeTodayMetrics(stored, live)
+  return result, nil
+}
+
+// reportDayIsUTCDay reports whether the loc day starting on the UTC date day
+// covers the same 24 hours as the UTC day, i.e. loc is at UTC offset 0 at
+// both midnights.
+func reportDayIsUTCDay(day time.Time, loc *time.Location) bool {
+  if loc == nil {
+    loc = time.Local
+  }
+  _, startOffset := day.In(loc).Zone()
+  _, endOffset := day.AddDate(0, 0, 1).In(loc).Zone()
+  return startOffset == 0 && endOffset == 0
+}
+
 func shouldAttachBalances(reportDate time.Time, loc *time.Location) bool {
   if loc == nil {
     loc = time.Local
@@ -156,5 +285,6 @@ func (s *Service) attachBalances(ctx context.Context, metrics Metrics) Metrics {
   metrics.OnchainBalanceSat = &onchain
   metrics.LightningBalanceSat = &lightning
   metrics.TotalBalanceSat = &total
+  fillOnchainRatio(&metrics)
   return metrics
 }
@@ -0,0 +1,67 @@
+package reports
+
+import (
+  "context"
+  "errors"
+  "testing"
+  "time"
+)
+
+func TestFlushWithoutBackends(t *testing.T) {
+  var nilService *Service
+  if err := nilService.Flush(context.Background(), time.Now(), time.UTC); err != nil {
+    t.Fatalf("expected nil service flush to be a no-op, got %v", err)
+  }
+  svc := NewService(NewPgStore(nil, DefaultOptions()), nil, nil, DefaultOptions())
+  if err := svc.Flush(context.Background(), time.Now(), time.UTC); err != nil {
+    t.Fatalf("expected flush without db/lnd to be a no-op, got %v", err)
+  }
+}
+
+type rebuildStore struct {
+  Store
+  daily int
+  channels int
+  filled int
+}
+
+func (r *rebuildStore) RebuildDailyFromEvents(ctx context.Context, startDate, endDate time.Time, loc *time.Location) ([]Row, error) {
+  r.daily++
+  return []Row{{ReportDate: normalizeReportDate(startDate)}}, nil
+}
+
+func (r *rebuildStore) EnsureDaysExist(ctx context.Context, startDate, endDate time.Time) error {
+  r.filled++
+  return nil
+}
+
+func (r *rebuildStore) RebuildChannelDaily(ctx context.Context, startDate, endDate time.Time, loc *time.Location) error {
+  r.channels++
+  return nil
+}
+
+func TestRebuildFromEvents(t *testing.T) {
+  day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+  store := &rebuildStore{}
+  if _, err := NewService(store, nil, nil, DefaultOptions()).RebuildFromEvents(context.Background(), day, day, time.UTC); !errors.Is(err, ErrEventsDisabled) {
+    t.Fatalf("expected ErrEventsDisabled, got %v", err)
+  }
+
+  opts := DefaultOptions()
+  opts.StoreEvents = true
+  rows, err := NewService(store, nil, nil, opts).RebuildFromEvents(context.Background(), day, day, time.UTC)
+  if err != nil || len(rows) != 1 {
+    t.Fatalf("unexpected rebuild result %v %v", rows, err)
+  }
+  if store.daily != 1 || store.channels != 0 || store.filled != 1 {
+    t.Fatalf("expected only daily rows without store_channels, got %+v", store)
+  }
+
+  opts.StoreChannels = true
+  if _, err := NewService(store, nil, nil, opts).RebuildFromEvents(context.Background(), day, day, time.UTC); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if store.daily != 2 || store.channels != 1 {
+    t.Fatalf("expected channel rows to be rebuilt too, got %+v", store)
+  }
+}
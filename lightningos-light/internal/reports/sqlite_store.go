@@ -0,0 +1,564 @@
+package reports
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "fmt"
+  "time"
+)
+
+const sqliteDateLayout = "2006-01-02"
+
+var sqliteSchema = []string{
+  `
+create table if not exists reports_daily (
+  report_date text primary key,
+  forward_fee_revenue_sats integer not null default 0,
+  forward_fee_revenue_msat integer not null default 0,
+  rebalance_fee_cost_sats integer not null default 0,
+  rebalance_fee_cost_msat integer not null default 0,
+  net_routing_profit_sats integer not null default 0,
+  net_routing_profit_msat integer not null default 0,
+  forward_count integer not null default 0,
+  rebalance_count integer not null default 0,
+  routed_volume_sats integer not null default 0,
+  routed_volume_msat integer not null default 0,
+  onchain_balance_sats integer null,
+  lightning_balance_sats integer null,
+  total_balance_sats integer null,
+  created_at text not null default current_timestamp,
+  updated_at text not null default current_timestamp
+)`,
+  `
+create table if not exists reports_fiat_rates (
+  rate_date text not null,
+  currency text not null,
+  rate real not null,
+  updated_at text not null default current_timestamp,
+  primary key (rate_date, currency)
+)`,
+}
+
+const sqliteRowColumns = `report_date,
+  forward_fee_revenue_sats,
+  forward_fee_revenue_msat,
+  rebalance_fee_cost_sats,
+  rebalance_fee_cost_msat,
+  net_routing_profit_sats,
+  net_routing_profit_msat,
+  forward_count,
+  rebalance_count,
+  routed_volume_sats,
+  routed_volume_msat,
+  onchain_balance_sats,
+  lightning_balance_sats,
+  total_balance_sats`
+
+const sqliteUpsertDaily = `
+insert into reports_daily (
+  report_date,
+  forward_fee_revenue_sats,
+  forward_fee_revenue_msat,
+  rebalance_fee_cost_sats,
+  rebalance_fee_cost_msat,
+  net_routing_profit_sats,
+  net_routing_profit_msat,
+  forward_count,
+  rebalance_count,
+  routed_volume_sats,
+  routed_volume_msat,
+  onchain_balance_sats,
+  lightning_balance_sats,
+  total_balance_sats
+) values (?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+on conflict (report_date) do update set
+  forward_fee_revenue_sats = excluded.forward_fee_revenue_sats,
+  forward_fee_revenue_msat = excluded.forward_fee_revenue_msat,
+  rebalance_fee_cost_sats = excluded.rebalance_fee_cost_sats,
+  rebalance_fee_cost_msat = excluded.rebalance_fee_cost_msat,
+  net_routing_profit_sats = excluded.net_routing_profit_sats,
+  net_routing_profit_msat = excluded.net_routing_profit_msat,
+  forward_count = excluded.forward_count,
+  rebalance_count = excluded.rebalance_count,
+  routed_volume_sats = excluded.routed_volume_sats,
+  routed_volume_msat = excluded.routed_volume_msat,
+  onchain_balance_sats = excluded.onchain_balance_sats,
+  lightning_balance_sats = excluded.lightning_balance_sats,
+  total_balance_sats = excluded.total_balance_sats,
+  updated_at = current_timestamp
+`
+
+// SQLiteStore expects a *sql.DB opened with a registered SQLite driver
+// (e.g. modernc.org/sqlite or mattn/go-sqlite3) by the caller. It keeps the
+// daily rows and fiat rates only; reports_events stays on Postgres.
+type SQLiteStore struct {
+  db *sql.DB
+  opts Options
+}
+
+var _ Store = (*SQLiteStore)(nil)
+
+var errSQLiteNoEvents = errors.New("reports events are not stored in SQLite")
+
+func NewSQLiteStore(db *sql.DB, opts Options) *SQLiteStore {
+  return &SQLiteStore{db: db, opts: opts}
+}
+
+func (s *SQLiteStore) queryContext(ctx context.Context) context.Context {
+  return WithQueryTimeout(ctx, s.opts.QueryTimeout)
+}
+
+func (s *SQLiteStore) EnsureSchema(ctx context.Context) error {
+  if s.db == nil {
+    return nil
+  }
+  for _, stmt := range sqliteSchema {
+    if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+      return err
+    }
+  }
+  return nil
+}
+
+func (s *SQLiteStore) UpsertDaily(ctx context.Context, row Row) (err error) {
+  if s.db == nil {
+    return nil
+  }
+  if err := validateForWrite(row, s.opts.StrictValidation); err != nil {
+    return err
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  _, err = s.db.ExecContext(ctx, sqliteUpsertDaily, sqliteUpsertArgs(row)...)
+  return err
+}
+
+func (s *SQLiteStore) UpsertDailyReturning(ctx context.Context, row Row) (stored Row, updatedAt time.Time, err error) {
+  if s.db == nil {
+    return Row{}, time.Time{}, nil
+  }
+  if err := validateForWrite(row, s.opts.StrictValidation); err != nil {
+    return Row{}, time.Time{}, err
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  query := sqliteUpsertDaily + "returning " + sqliteRowColumns + ",\n  updated_at\n"
+  var updatedRaw string
+  stored, err = scanSQLiteRow(trailingScanner{scanner: s.db.QueryRowContext(ctx, query, sqliteUpsertArgs(row)...), extra: []any{&updatedRaw}})
+  if err != nil {
+    return Row{}, time.Time{}, err
+  }
+  updatedAt, err = parseSQLiteTimestamp(updatedRaw)
+  if err != nil {
+    return Row{}, time.Time{}, err
+  }
+  return stored, updatedAt, nil
+}
+
+func (s *SQLiteStore) UpsertDailyBatch(ctx context.Context, rows []Row) (err error) {
+  if s.db == nil || len(rows) == 0 {
+    return nil
+  }
+  if err := validateBatchForWrite(rows, s.opts.StrictValidation); err != nil {
+    return err
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  tx, err := s.db.BeginTx(ctx, nil)
+  if err != nil {
+    return err
+  }
+  defer tx.Rollback()
+
+  stmt, err := tx.PrepareContext(ctx, sqliteUpsertDaily)
+  if err != nil {
+    return err
+  }
+  defer stmt.Close()
+
+  for _, row := range rows {
+    if _, err := stmt.ExecContext(ctx, sqliteUpsertArgs(row)...); err != nil {
+      return err
+    }
+  }
+  return tx.Commit()
+}
+
+func (s *SQLiteStore) UpsertBalancesOnly(ctx context.Context, date time.Time, metrics Metrics) (err error) {
+  if s.db == nil {
+    return nil
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  _, err = s.db.ExecContext(ctx, `
+insert into reports_daily (
+  report_date,
+  onchain_balance_sats,
+  lightning_balance_sats,
+  total_balance_sats
+) values (?,?,?,?)
+on conflict (report_date) do update set
+  onchain_balance_sats = excluded.onchain_balance_sats,
+  lightning_balance_sats = excluded.lightning_balance_sats,
+  total_balance_sats = excluded.total_balance_sats,
+  updated_at = current_timestamp
+`, sqliteDate(date),
+    nullableInt64(metrics.OnchainBalanceSat),
+    nullableInt64(metrics.LightningBalanceSat),
+    nullableInt64(metrics.TotalBalanceSat),
+  )
+  return err
+}
+
+func (s *SQLiteStore) BackfillBalances(ctx context.Context, date time.Time, onchain, lightning *int64) (err error) {
+  if s.db == nil {
+    return nil
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  var total *int64
+  if onchain != nil && lightning != nil {
+    sum := *onchain + *lightning
+    total = &sum
+  }
+  result, err := s.db.ExecContext(ctx, `
+update reports_daily set
+  onchain_balance_sats = coalesce(?, onchain_balance_sats),
+  lightning_balance_sats = coalesce(?, lightning_balance_sats),
+  total_balance_sats = coalesce(?, total_balance_sats),
+  updated_at = current_timestamp
+where report_date = ?
+`, nullableInt64(onchain), nullableInt64(lightning), nullableInt64(total), sqliteDate(date))
+  if err != nil {
+    return err
+  }
+  affected, err := result.RowsAffected()
+  if err != nil {
+    return err
+  }
+  if affected == 0 {
+    return ErrReportNotFound
+  }
+  return nil
+}
+
+func (s *SQLiteStore) PruneOlderThan(ctx context.Context, cutoff time.Time) (deleted int64, err error) {
+  if s.db == nil {
+    return 0, nil
+  }
+  if cutoff.IsZero() {
+    return 0, fmt.Errorf("prune cutoff is required")
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  result, err := s.db.ExecContext(ctx, `delete from reports_daily where report_date < ?`, sqliteDate(cutoff))
+  if err != nil {
+    return 0, err
+  }
+  return result.RowsAffected()
+}
+
+func (s *SQLiteStore) DeleteRange(ctx context.Context, startDate, endDate time.Time) (deleted int64, err error) {
+  start, end, err := deleteRangeBounds(startDate, endDate)
+  if err != nil {
+    return 0, err
+  }
+  if s.db == nil {
+    return 0, nil
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  result, err := s.db.ExecContext(ctx, `delete from reports_daily where report_date >= ? and report_date <= ?`, sqliteDate(start), sqliteDate(end))
+  if err != nil {
+    return 0, err
+  }
+  return result.RowsAffected()
+}
+
+// EnsureDaysExist walks the range with a recursive CTE since SQLite has no
+// generate_series. The "where true" keeps the upsert parser unambiguous.
+func (s *SQLiteStore) EnsureDaysExist(ctx context.Context, startDate, endDate time.Time) (err error) {
+  if s.db == nil {
+    return nil
+  }
+  start := normalizeReportDate(startDate)
+  end := normalizeReportDate(endDate)
+  if end.Before(start) {
+    return fmt.Errorf("end date before start date")
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  _, err = s.db.ExecContext(ctx, `
+with recursive days(day) as (
+  select ?
+  union all
+  select date(day, '+1 day') from days where day < ?
+)
+insert into reports_daily (report_date)
+select day from days where true
+on conflict (report_date) do nothing
+`, sqliteDate(start), sqliteDate(end))
+  return err
+}
+
+func (s *SQLiteStore) FetchRange(ctx context.Context, startDate, endDate time.Time) ([]Row, error) {
+  return s.FetchRangeOrdered(ctx, startDate, endDate, Ascending)
+}
+
+func (s *SQLiteStore) FetchRangeOrdered(ctx context.Context, startDate, endDate time.Time, order SortOrder) ([]Row, error) {
+  if s.db == nil {
+    return nil, nil
+  }
+  direction, err := order.sqlDirection()
+  if err != nil {
+    return nil, err
+  }
+  return s.queryRows(ctx, "select "+sqliteRowColumns+`
+from reports_daily
+where report_date >= ? and report_date <= ?
+order by report_date `+direction+`
+`, sqliteDate(startDate), sqliteDate(endDate))
+}
+
+func (s *SQLiteStore) FetchAll(ctx context.Context) ([]Row, error) {
+  items, err := s.fetchAll(ctx, s.opts.FetchAllMaxRows)
+  if err != nil {
+    return nil, err
+  }
+  return items, checkFetchAllRows(len(items), s.opts.FetchAllMaxRows)
+}
+
+func (s *SQLiteStore) FetchAllUnbounded(ctx context.Context) ([]Row, error) {
+  return s.fetchAll(ctx, 0)
+}
+
+func (s *SQLiteStore) fetchAll(ctx context.Context, maxRows int) ([]Row, error) {
+  if s.db == nil {
+    return nil, nil
+  }
+  query := "select " + sqliteRowColumns + "\nfrom reports_daily\norder by report_date asc\n"
+  var args []any
+  if maxRows > 0 {
+    query += "limit ?\n"
+    args = append(args, maxRows+1)
+  }
+  return s.queryRows(ctx, query, args...)
+}
+
+func (s *SQLiteStore) FetchPage(ctx context.Context, beforeDate time.Time, limit int) ([]Row, time.Time, error) {
+  limit, err := normalizePageLimit(limit)
+  if err != nil {
+    return nil, time.Time{}, err
+  }
+  if s.db == nil {
+    return nil, time.Time{}, nil
+  }
+  query := "select " + sqliteRowColumns + "\nfrom reports_daily\n"
+  var args []any
+  if !beforeDate.IsZero() {
+    query += "where report_date < ?\n"
+    args = append(args, sqliteDate(beforeDate))
+  }
+  query += "order by report_date desc\nlimit ?\n"
+  args = append(args, limit)
+
+  items, err := s.queryRows(ctx, query, args...)
+  if err != nil {
+    return nil, time.Time{}, err
+  }
+  return items, pageCursor(items), nil
+}
+
+func (s *SQLiteStore) FetchSummaryRange(ctx context.Context, startDate, endDate time.Time) (Summary, error) {
+  items, err := s.FetchRange(ctx, startDate, endDate)
+  if err != nil {
+    return Summary{}, err
+  }
+  return summarizeRows(items), nil
+}
+
+func (s *SQLiteStore) FetchSummaryAll(ctx context.Context) (Summary, error) {
+  items, err := s.FetchAllUnbounded(ctx)
+  if err != nil {
+    return Summary{}, err
+  }
+  return summarizeRows(items), nil
+}
+
+func (s *SQLiteStore) FetchRollup(ctx context.Context, startDate, endDate time.Time, granularity Granularity) ([]RollupBucket, error) {
+  if _, err := granularity.truncUnit(); err != nil {
+    return nil, err
+  }
+  items, err := s.FetchRange(ctx, startDate, endDate)
+  if err != nil {
+    return nil, err
+  }
+  return rollupRows(items, granularity), nil
+}
+
+func (s *SQLiteStore) FetchSummaryBucketed(ctx context.Context, startDate, endDate time.Time, interval string) ([]RollupBucket, error) {
+  granularity, err := ParseBucketInterval(interval)
+  if err != nil {
+    return nil, err
+  }
+  return s.FetchRollup(ctx, startDate, endDate, granularity)
+}
+
+func (s *SQLiteStore) FetchByWeekday(ctx context.Context, startDate, endDate time.Time) ([7]Metrics, error) {
+  items, err := s.FetchRange(ctx, startDate, endDate)
+  if err != nil {
+    return [7]Metrics{}, err
+  }
+  return weekdayTotals(items), nil
+}
+
+func (s *SQLiteStore) LoadPriceTable(ctx context.Context, currency string, startDate, endDate time.Time) (table PriceTable, err error) {
+  table = PriceTable{}
+  if s.db == nil {
+    return table, nil
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  rows, err := s.db.QueryContext(ctx, `
+select rate_date, rate
+from reports_fiat_rates
+where currency = ? and rate_date >= ? and rate_date <= ?
+`, currency, sqliteDate(startDate), sqliteDate(endDate))
+  if err != nil {
+    return nil, err
+  }
+  defer rows.Close()
+
+  for rows.Next() {
+    var rateDate string
+    var rate float64
+    if err := rows.Scan(&rateDate, &rate); err != nil {
+      return nil, err
+    }
+    table[rateDate] = rate
+  }
+  return table, rows.Err()
+}
+
+func (s *SQLiteStore) UpsertFiatRate(ctx context.Context, date time.Time, currency string, rate float64) (err error) {
+  if s.db == nil {
+    return nil
+  }
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  _, err = s.db.ExecContext(ctx, `
+insert into reports_fiat_rates (rate_date, currency, rate)
+values (?, ?, ?)
+on conflict (rate_date, currency) do update set
+  rate = excluded.rate,
+  updated_at = current_timestamp
+`, sqliteDate(date), currency, rate)
+  return err
+}
+
+func (s *SQLiteStore) RebuildDailyFromEvents(ctx context.Context, startDate, endDate time.Time, loc *time.Location) ([]Row, error) {
+  return nil, errSQLiteNoEvents
+}
+
+func (s *SQLiteStore) RebuildChannelDaily(ctx context.Context, startDate, endDate time.Time, loc *time.Location) error {
+  return errSQLiteNoEvents
+}
+
+func (s *SQLiteStore) queryRows(ctx context.Context, query string, args ...any) (items []Row, err error) {
+  ctx, done := startQuery(s.queryContext(ctx))
+  defer done(&err)
+  rows, err := s.db.QueryContext(ctx, query, args...)
+  if err != nil {
+    return nil, err
+  }
+  defer rows.Close()
+
+  for rows.Next() {
+    row, err := scanSQLiteRow(rows)
+    if err != nil {
+      return nil, err
+    }
+    items = append(items, row)
+  }
+  return items, rows.Err()
+}
+
+func sqliteUpsertArgs(row Row) []any {
+  metrics := row.Metrics
+  return []any{
+    sqliteDate(row.ReportDate),
+    metrics.ForwardFeeRevenueSat,
+    metrics.ForwardFeeRevenueMsat,
+    metrics.RebalanceFeeCostSat,
+    metrics.RebalanceFeeCostMsat,
+    metrics.NetRoutingProfitSat,
+    metrics.NetRoutingProfitMsat,
+    metrics.ForwardCount,
+    metrics.RebalanceCount,
+    metrics.RoutedVolumeSat,
+    metrics.RoutedVolumeMsat,
+    nullableInt64(metrics.OnchainBalanceSat),
+    nullableInt64(metrics.LightningBalanceSat),
+    nullableInt64(metrics.TotalBalanceSat),
+  }
+}
+
+func scanSQLiteRow(scanner rowScanner) (Row, error) {
+  var reportDate string
+  var metrics Metrics
+  var onchain sql.NullInt64
+  var lightning sql.NullInt64
+  var total sql.NullInt64
+  err := scanner.Scan(
+    &reportDate,
+    &metrics.ForwardFeeRevenueSat,
+    &metrics.ForwardFeeRevenueMsat,
+    &metrics.RebalanceFeeCostSat,
+    &metrics.RebalanceFeeCostMsat,
+    &metrics.NetRoutingProfitSat,
+    &metrics.NetRoutingProfitMsat,
+    &metrics.ForwardCount,
+    &metrics.RebalanceCount,
+    &metrics.RoutedVolumeSat,
+    &metrics.RoutedVolumeMsat,
+    &onchain,
+    &lightning,
+    &total,
+  )
+  if err != nil {
+    return Row{}, err
+  }
+  parsed, err := time.Parse(sqliteDateLayout, reportDate)
+  if err != nil {
+    return Row{}, fmt.Errorf("invalid report_date %q: %w", reportDate, err)
+  }
+  if onchain.Valid {
+    val := onchain.Int64
+    metrics.OnchainBalanceSat = &val
+  }
+  if lightning.Valid {
+    val := lightning.Int64
+    metrics.LightningBalanceSat = &val
+  }
+  if total.Valid {
+    val := total.Int64
+    metrics.TotalBalanceSat = &val
+  }
+  fillMsatFromSat(&metrics)
+  fillOnchainRatio(&metrics)
+  return Row{ReportDate: parsed, Metrics: metrics}, nil
+}
+
+func sqliteDate(value time.Time) string {
+  return normalizeReportDate(value).Format(sqliteDateLayout)
+}
+
+func parseSQLiteTimestamp(value string) (time.Time, error) {
+  for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano, time.RFC3339} {
+    if parsed, err := time.Parse(layout, value); err == nil {
+      return parsed.UTC(), nil
+    }
+  }
+  return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
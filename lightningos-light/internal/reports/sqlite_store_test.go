@@ -0,0 +1,194 @@
+package reports
+
+import (
+  "context"
+  "database/sql"
+  "database/sql/driver"
+  "errors"
+  "strings"
+  "sync"
+  "testing"
+  "time"
+)
+
+// recordingDriver is a database/sql driver that records every Exec so the
+// SQLite store can be tested without linking a real SQLite driver.
+type recordingDriver struct {
+  mu sync.Mutex
+  queries []string
+  args [][]driver.Value
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) {
+  return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) last() (string, []driver.Value) {
+  d.mu.Lock()
+  defer d.mu.Unlock()
+  if len(d.queries) == 0 {
+    return "", nil
+  }
+  return d.queries[len(d.queries)-1], d.args[len(d.args)-1]
+}
+
+type recordingConn struct {
+  driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+  return &recordingStmt{conn: c, query: query}, nil
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error { return nil }
+
+func (recordingTx) Rollback() error { return nil }
+
+type recordingStmt struct {
+  conn *recordingConn
+  query string
+}
+
+func (s *recordingStmt) Close() error { return nil }
+
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+  d := s.conn.driver
+  d.mu.Lock()
+  defer d.mu.Unlock()
+  d.queries = append(d.queries, s.query)
+  d.args = append(d.args, args)
+  return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+  return nil, errors.New("query not supported")
+}
+
+func openRecordingSQLite(t *testing.T) (*SQLiteStore, *recordingDriver) {
+  t.Helper()
+  rec := &recordingDriver{}
+  name := "reports-recording-" + t.Name()
+  sql.Register(name, rec)
+  db, err := sql.Open(name, "")
+  if err != nil {
+    t.Fatalf("open: %v", err)
+  }
+  t.Cleanup(func() { db.Close() })
+  return NewSQLiteStore(db, DefaultOptions()), rec
+}
+
+func TestSQLiteStoreEnsureSchema(t *testing.T) {
+  store, rec := openRecordingSQLite(t)
+  if err := store.EnsureSchema(context.Background()); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(rec.queries) != len(sqliteSchema) {
+    t.Fatalf("expected %d schema statements, got %d", len(sqliteSchema), len(rec.queries))
+  }
+  daily := rec.queries[0]
+  if !strings.Contains(daily, "create table if not exists reports_daily") || !strings.Contains(daily, "report_date text primary key") {
+    t.Fatalf("expected text-dated reports_daily, got %s", daily)
+  }
+  if strings.Contains(daily, "bigint") || !strings.Contains(daily, "forward_fee_revenue_msat integer") {
+    t.Fatalf("expected INTEGER columns, got %s", daily)
+  }
+}
+
+func TestSQLiteStoreUpsertArgs(t *testing.T) {
+  store, rec := openRecordingSQLite(t)
+  onchain := int64(5000)
+  row := Row{
+    ReportDate: time.Date(2026, 1, 15, 0, 0, 0, 0, time.FixedZone("Local", -3*60*60)),
+    Metrics: Metrics{ForwardFeeRevenueSat: 12, ForwardFeeRevenueMsat: 12000, OnchainBalanceSat: &onchain},
+  }
+  if err := store.UpsertDaily(context.Background(), row); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  query, args := rec.last()
+  if !strings.Contains(query, "on conflict (report_date) do update") {
+    t.Fatalf("expected upsert, got %s", query)
+  }
+  if len(args) != 14 || args[0] != "2026-01-15" || args[1] != int64(12) || args[11] != int64(5000) || args[12] != nil {
+    t.Fatalf("unexpected args: %v", args)
+  }
+}
+
+func TestSQLiteStoreEnsureDaysExist(t *testing.T) {
+  store, rec := openRecordingSQLite(t)
+  start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+  if err := store.EnsureDaysExist(context.Background(), start, start.AddDate(0, 0, 6)); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  query, args := rec.last()
+  if !strings.Contains(query, "with recursive days") || !strings.Contains(query, "do nothing") {
+    t.Fatalf("unexpected query: %s", query)
+  }
+  if len(args) != 2 || args[0] != "2026-03-01" || args[1] != "2026-03-07" {
+    t.Fatalf("unexpected args: %v", args)
+  }
+  if err := store.EnsureDaysExist(context.Background(), start, start.AddDate(0, 0, -1)); err == nil {
+    t.Fatalf("expected error for reversed range")
+  }
+}
+
+type sqliteRowScanner struct {
+  date string
+  onchain *int64
+}
+
+func (f sqliteRowScanner) Scan(dest ...any) error {
+  *dest[0].(*string) = f.date
+  *dest[1].(*int64) = 7
+  if f.onchain != nil {
+    *dest[11].(*sql.NullInt64) = sql.NullInt64{Int64: *f.onchain, Valid: true}
+  }
+  return nil
+}
+
+func TestScanSQLiteRow(t *testing.T) {
+  onchain := int64(900)
+  row, err := scanSQLiteRow(sqliteRowScanner{date: "2026-02-28", onchain: &onchain})
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if row.ReportDate.Format("2006-01-02") != "2026-02-28" || row.Metrics.ForwardFeeRevenueMsat != 7000 {
+    t.Fatalf("unexpected row: %+v", row)
+  }
+  if row.Metrics.OnchainBalanceSat == nil || *row.Metrics.OnchainBalanceSat != 900 || row.Metrics.LightningBalanceSat != nil {
+    t.Fatalf("unexpected balances: %+v", row.Metrics)
+  }
+  if _, err := scanSQLiteRow(sqliteRowScanner{date: "28/02/2026"}); err == nil {
+    t.Fatalf("expected error for malformed report_date")
+  }
+}
+
+func TestSQLiteStoreNilDBAndEvents(t *testing.T) {
+  store := NewSQLiteStore(nil, DefaultOptions())
+  if err := store.EnsureSchema(context.Background()); err != nil {
+    t.Fatalf("expected nil db schema to be a no-op, got %v", err)
+  }
+  if rows, err := store.FetchRange(context.Background(), time.Now(), time.Now()); err != nil || rows != nil {
+    t.Fatalf("expected nil db fetch to be a no-op, got %v %v", rows, err)
+  }
+  if _, err := store.RebuildDailyFromEvents(context.Background(), time.Now(), time.Now(), time.UTC); err == nil {
+    t.Fatalf("expected events rebuild to be refused")
+  }
+}
+
+func TestParseSQLiteTimestamp(t *testing.T) {
+  got, err := parseSQLiteTimestamp("2026-03-01 12:30:00")
+  if err != nil || !got.Equal(time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)) {
+    t.Fatalf("unexpected timestamp %s %v", got, err)
+  }
+  if _, err := parseSQLiteTimestamp("yesterday"); err == nil {
+    t.Fatalf("expected error for invalid timestamp")
+  }
+}
@@ -2,12 +2,31 @@ package reports
 
 import (
   "context"
+  "errors"
+  "fmt"
+  "math"
+  "strings"
   "time"
 
+  "github.com/jackc/pgx/v5"
   "github.com/jackc/pgx/v5/pgtype"
   "github.com/jackc/pgx/v5/pgxpool"
 )
 
+var ErrReportNotFound = errors.New("report not found")
+var ErrNoDatabase = errors.New("reports database not configured")
+
+// Ping reports whether the reports database is usable. The other functions in
+// this file treat a nil pool as "reporting disabled" and return empty results;
+// Ping returns ErrNoDatabase instead so callers can tell that apart from a
+// database that failed to connect.
+func Ping(ctx context.Context, db *pgxpool.Pool) error {
+  if db == nil {
+    return ErrNoDatabase
+  }
+  return db.Ping(ctx)
+}
+
 func EnsureSchema(ctx context.Context, db *pgxpool.Pool) error {
   if db == nil {
     return nil
@@ -36,16 +55,92 @@ alter table reports_daily add column if not exists forward_fee_revenue_msat bigi
 alter table reports_daily add column if not exists rebalance_fee_cost_msat bigint not null default 0;
 alter table reports_daily add column if not exists net_routing_profit_msat bigint not null default 0;
 alter table reports_daily add column if not exists routed_volume_msat bigint not null default 0;
+
+create table if not exists reports_fiat_rates (
+  rate_date date not null,
+  currency text not null,
+  rate double precision not null,
+  updated_at timestamptz not null default now(),
+  primary key (rate_date, currency)
+);
 `)
   return err
 }
 
-func UpsertDaily(ctx context.Context, db *pgxpool.Pool, row Row) error {
+func UpsertDaily(ctx context.Context, db *pgxpool.Pool, row Row) (err error) {
   if db == nil {
     return nil
   }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  query, args := buildUpsertDaily(row)
+  _, err = db.Exec(ctx, query, args...)
+  InvalidateSummaryCache()
+  return err
+}
+
+func UpsertDailyReturning(ctx context.Context, db *pgxpool.Pool, row Row) (stored Row, updatedAt time.Time, err error) {
+  if db == nil {
+    return Row{}, time.Time{}, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
   query, args := buildUpsertDaily(row)
-  _, err := db.Exec(ctx, query, args...)
+  query += `returning report_date,
+  forward_fee_revenue_sats,
+  forward_fee_revenue_msat,
+  rebalance_fee_cost_sats,
+  rebalance_fee_cost_msat,
+  net_routing_profit_sats,
+  net_routing_profit_msat,
+  forward_count,
+  rebalance_count,
+  routed_volume_sats,
+  routed_volume_msat,
+  onchain_balance_sats,
+  lightning_balance_sats,
+  total_balance_sats,
+  updated_at
+`
+  stored, err = scanRow(trailingScanner{scanner: db.QueryRow(ctx, query, args...), extra: []any{&updatedAt}})
+  InvalidateSummaryCache()
+  if err != nil {
+    return Row{}, time.Time{}, err
+  }
+  return stored, updatedAt, nil
+}
+
+func UpsertDailyBatch(ctx context.Context, db *pgxpool.Pool, rows []Row) (err error) {
+  if db == nil || len(rows) == 0 {
+    return nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+
+  tx, err := db.Begin(ctx)
+  if err != nil {
+    return err
+  }
+  defer tx.Rollback(ctx)
+
+  batch := &pgx.Batch{}
+  for _, row := range rows {
+    query, args := buildUpsertDaily(row)
+    batch.Queue(query, args...)
+  }
+
+  results := tx.SendBatch(ctx, batch)
+  for range rows {
+    if _, err := results.Exec(); err != nil {
+      _ = results.Close()
+      return err
+    }
+  }
+  if err := results.Close(); err != nil {
+    return err
+  }
+  err = tx.Commit(ctx)
+  InvalidateSummaryCache()
   return err
 }
 
@@ -107,10 +202,159 @@ on conflict (report_date) do update set
   return query, args
 }
 
+func UpsertBalancesOnly(ctx context.Context, db *pgxpool.Pool, date time.Time, metrics Metrics) (err error) {
+  if db == nil {
+    return nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  _, err = db.Exec(ctx, `
+insert into reports_daily (
+  report_date,
+  onchain_balance_sats,
+  lightning_balance_sats,
+  total_balance_sats
+) values ($1,$2,$3,$4)
+on conflict (report_date) do update set
+  onchain_balance_sats = excluded.onchain_balance_sats,
+  lightning_balance_sats = excluded.lightning_balance_sats,
+  total_balance_sats = excluded.total_balance_sats,
+  updated_at = now()
+`, normalizeReportDate(date),
+    nullableInt64(metrics.OnchainBalanceSat),
+    nullableInt64(metrics.LightningBalanceSat),
+    nullableInt64(metrics.TotalBalanceSat),
+  )
+  if err != nil {
+    return err
+  }
+  InvalidateSummaryCache()
+  return nil
+}
+
+func BackfillBalances(ctx context.Context, db *pgxpool.Pool, date time.Time, onchain, lightning *int64) (err error) {
+  if db == nil {
+    return nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  var total *int64
+  if onchain != nil && lightning != nil {
+    sum := *onchain + *lightning
+    total = &sum
+  }
+  tag, err := db.Exec(ctx, `
+update reports_daily set
+  onchain_balance_sats = coalesce($2, onchain_balance_sats),
+  lightning_balance_sats = coalesce($3, lightning_balance_sats),
+  total_balance_sats = coalesce($4, total_balance_sats),
+  updated_at = now()
+where report_date = $1
+`, normalizeReportDate(date), nullableInt64(onchain), nullableInt64(lightning), nullableInt64(total))
+  if err != nil {
+    return err
+  }
+  if tag.RowsAffected() == 0 {
+    return ErrReportNotFound
+  }
+  InvalidateSummaryCache()
+  return nil
+}
+
+func PruneOlderThan(ctx context.Context, db *pgxpool.Pool, cutoff time.Time) (deleted int64, err error) {
+  if db == nil {
+    return 0, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  if cutoff.IsZero() {
+    return 0, fmt.Errorf("prune cutoff is required")
+  }
+  tag, err := db.Exec(ctx, `delete from reports_daily where report_date < $1`, normalizeReportDate(cutoff))
+  InvalidateSummaryCache()
+  if err != nil {
+    return 0, err
+  }
+  return tag.RowsAffected(), nil
+}
+
+// DeleteRange removes the reports for [startDate, endDate] (inclusive) and
+// returns the number of rows deleted.
+func DeleteRange(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time) (deleted int64, err error) {
+  start, end, err := deleteRangeBounds(startDate, endDate)
+  if err != nil {
+    return 0, err
+  }
+  if db == nil {
+    return 0, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  tag, err := db.Exec(ctx, `delete from reports_daily where report_date >= $1 and report_date <= $2`, start, end)
+  InvalidateSummaryCache()
+  if err != nil {
+    return 0, err
+  }
+  return tag.RowsAffected(), nil
+}
+
+func deleteRangeBounds(startDate, endDate time.Time) (time.Time, time.Time, error) {
+  if startDate.IsZero() || endDate.IsZero() {
+    return time.Time{}, time.Time{}, fmt.Errorf("delete range start and end are required")
+  }
+  start := normalizeReportDate(startDate)
+  end := normalizeReportDate(endDate)
+  if start.After(end) {
+    return time.Time{}, time.Time{}, fmt.Errorf("delete range start after end")
+  }
+  return start, end, nil
+}
+
+// EnsureDaysExist inserts zero rows for the dates in [startDate, endDate]
+// that have no report yet, so range queries return a continuous series.
+// Existing rows are never touched.
+func EnsureDaysExist(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time) (err error) {
+  if db == nil {
+    return nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  query, args, err := buildEnsureDaysExist(startDate, endDate)
+  if err != nil {
+    return err
+  }
+  _, err = db.Exec(ctx, query, args...)
+  InvalidateSummaryCache()
+  return err
+}
+
+func buildEnsureDaysExist(startDate, endDate time.Time) (string, []any, error) {
+  start := normalizeReportDate(startDate)
+  end := normalizeReportDate(endDate)
+  if end.Before(start) {
+    return "", nil, fmt.Errorf("end date before start date")
+  }
+  return `
+insert into reports_daily (report_date)
+select day::date from generate_series($1::date, $2::date, interval '1 day') as day
+on conflict (report_date) do nothing
+`, []any{start, end}, nil
+}
+
 func FetchRange(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time) ([]Row, error) {
+  return FetchRangeOrdered(ctx, db, startDate, endDate, Ascending)
+}
+
+func FetchRangeOrdered(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time, order SortOrder) (items []Row, err error) {
   if db == nil {
     return nil, nil
   }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  direction, err := order.sqlDirection()
+  if err != nil {
+    return nil, err
+  }
   rows, err := db.Query(ctx, `
 select report_date,
   forward_fee_revenue_sats,
@@ -128,14 +372,13 @@ select report_date,
   total_balance_sats
 from reports_daily
 where report_date >= $1 and report_date <= $2
-order by report_date asc
+order by report_date `+direction+`
 `, normalizeReportDate(startDate), normalizeReportDate(endDate))
   if err != nil {
     return nil, err
   }
   defer rows.Close()
 
-  var items []Row
   for rows.Next() {
     row, err := scanRow(rows)
     if err != nil {
@@ -146,10 +389,62 @@ order by report_date asc
   return items, rows.Err()
 }
 
-func FetchAll(ctx context.Context, db *pgxpool.Pool) ([]Row, error) {
+func FetchMonth(ctx context.Context, db *pgxpool.Pool, year int, month time.Month) ([]Row, error) {
+  startDate, endDate, err := monthRange(year, month)
+  if err != nil {
+    return nil, err
+  }
+  return FetchRange(ctx, db, startDate, endDate)
+}
+
+func monthRange(year int, month time.Month) (time.Time, time.Time, error) {
+  if month < time.January || month > time.December {
+    return time.Time{}, time.Time{}, fmt.Errorf("invalid month %d", month)
+  }
+  startDate := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+  endDate := startDate.AddDate(0, 1, -1)
+  return startDate, endDate, nil
+}
+
+var ErrTooManyRows = errors.New("too many report rows to load at once; use a date range or pagination")
+
+// FetchAll loads every row unless there are more than maxRows, in which case
+// it returns ErrTooManyRows so callers switch to FetchRange or FetchPage.
+// Zero or less disables the check.
+func FetchAll(ctx context.Context, db *pgxpool.Pool, maxRows int) ([]Row, error) {
+  items, err := fetchAll(ctx, db, maxRows)
+  if err != nil {
+    return nil, err
+  }
+  return items, checkFetchAllRows(len(items), maxRows)
+}
+
+// FetchAllUnbounded loads every row regardless of the row cap.
+func FetchAllUnbounded(ctx context.Context, db *pgxpool.Pool) ([]Row, error) {
+  return fetchAll(ctx, db, 0)
+}
+
+// checkFetchAllRows is given the result of a query limited to maxRows+1, so
+// any count above maxRows means the table is over the cap.
+func checkFetchAllRows(count int, maxRows int) error {
+  if maxRows > 0 && count > maxRows {
+    return fmt.Errorf("%w (limit %d)", ErrTooManyRows, maxRows)
+  }
+  return nil
+}
+
+func fetchAll(ctx context.Context, db *pgxpool.Pool, maxRows int) (items []Row, err error) {
   if db == nil {
     return nil, nil
   }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  // Stop one row past the cap so an oversized table is detected without
+  // reading all of it.
+  limit := ""
+  if maxRows > 0 {
+    limit = fmt.Sprintf("limit %d\n", maxRows+1)
+  }
   rows, err := db.Query(ctx, `
 select report_date,
   forward_fee_revenue_sats,
@@ -167,13 +462,12 @@ select report_date,
   total_balance_sats
 from reports_daily
 order by report_date asc
-`)
+`+limit)
   if err != nil {
     return nil, err
   }
   defer rows.Close()
 
-  var items []Row
   for rows.Next() {
     row, err := scanRow(rows)
     if err != nil {
@@ -184,14 +478,160 @@ order by report_date asc
   return items, rows.Err()
 }
 
-func FetchSummaryRange(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time) (Summary, error) {
+const maxPageLimit = 1000
+
+func FetchPage(ctx context.Context, db *pgxpool.Pool, beforeDate time.Time, limit int) (items []Row, next time.Time, err error) {
+  limit, err = normalizePageLimit(limit)
+  if err != nil {
+    return nil, time.Time{}, err
+  }
+  if db == nil {
+    return nil, time.Time{}, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+
+  query := `
+select report_date,
+  forward_fee_revenue_sats,
+  forward_fee_revenue_msat,
+  rebalance_fee_cost_sats,
+  rebalance_fee_cost_msat,
+  net_routing_profit_sats,
+  net_routing_profit_msat,
+  forward_count,
+  rebalance_count,
+  routed_volume_sats,
+  routed_volume_msat,
+  onchain_balance_sats,
+  lightning_balance_sats,
+  total_balance_sats
+from reports_daily
+`
+  args := []any{limit}
+  if !beforeDate.IsZero() {
+    query += "where report_date < $2\n"
+    args = append(args, normalizeReportDate(beforeDate))
+  }
+  query += "order by report_date desc\nlimit $1\n"
+
+  rows, err := db.Query(ctx, query, args...)
+  if err != nil {
+    return nil, time.Time{}, err
+  }
+  defer rows.Close()
+
+  for rows.Next() {
+    row, err := scanRow(rows)
+    if err != nil {
+      return nil, time.Time{}, err
+    }
+    items = append(items, row)
+  }
+  if err := rows.Err(); err != nil {
+    return nil, time.Time{}, err
+  }
+  return items, pageCursor(items), nil
+}
+
+func normalizePageLimit(limit int) (int, error) {
+  if limit <= 0 {
+    return 0, fmt.Errorf("page limit must be positive")
+  }
+  if limit > maxPageLimit {
+    return maxPageLimit, nil
+  }
+  return limit, nil
+}
+
+func pageCursor(items []Row) time.Time {
+  if len(items) == 0 {
+    return time.Time{}
+  }
+  return items[len(items)-1].ReportDate
+}
+
+func FetchSummaryRange(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time) (summary Summary, err error) {
+  if db == nil {
+    return Summary{}, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  return fetchSummary(ctx, db, "where report_date >= $1 and report_date <= $2", normalizeReportDate(startDate), normalizeReportDate(endDate))
+}
+
+func FetchSummaryAll(ctx context.Context, db *pgxpool.Pool) (summary Summary, err error) {
   if db == nil {
     return Summary{}, nil
   }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  cached, generation, ok := summaryAllCache.get(db, time.Now())
+  if ok {
+    return cached, nil
+  }
+  summary, err = fetchSummary(ctx, db, "")
+  if err != nil {
+    return Summary{}, err
+  }
+  if !summary.Partial {
+    summaryAllCache.set(db, generation, summary, time.Now())
+  }
+  return summary, nil
+}
+
+// fetchSummary runs the totals and the max/median aggregates as separate
+// queries. Totals are required; if only the extremes fail the summary is
+// returned with Partial set and zero Max/Median so dashboards keep the fee and
+// volume figures during a database hiccup.
+func fetchSummary(ctx context.Context, db *pgxpool.Pool, where string, args ...any) (Summary, error) {
   var days int64
-  totals := Metrics{}
-  err := db.QueryRow(ctx, `
-select
+  var totals, maxes, medians Metrics
+  row := db.QueryRow(ctx, "select"+summaryTotalsColumns+"from reports_daily\n"+where, args...)
+  if err := row.Scan(summaryTotalsDest(&days, &totals)...); err != nil {
+    return Summary{}, err
+  }
+  partial := false
+  row = db.QueryRow(ctx, "select"+summaryExtremesColumns+"from reports_daily\n"+where, args...)
+  if err := row.Scan(summaryExtremesDest(&maxes, &medians)...); err != nil {
+    if ctx.Err() != nil {
+      return Summary{}, err
+    }
+    partial = true
+    maxes, medians = Metrics{}, Metrics{}
+  }
+  summary := buildSummary(days, totals, maxes, medians)
+  summary.Partial = partial
+  return summary, nil
+}
+
+// FetchSummarySince summarizes only the rows upserted after since and returns
+// the newest updated_at seen, to be passed back as since on the next poll.
+// When nothing changed the returned cursor is since itself.
+func FetchSummarySince(ctx context.Context, db *pgxpool.Pool, since time.Time) (summary Summary, cursor time.Time, err error) {
+  if db == nil {
+    return Summary{}, since, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  query := `select summary.*, (select max(updated_at) from reports_daily where updated_at > $1) from (` +
+    summarySelect + `where updated_at > $1) summary`
+  return scanSummarySince(db.QueryRow(ctx, query, since.UTC()), since)
+}
+
+func scanSummarySince(scanner rowScanner, since time.Time) (Summary, time.Time, error) {
+  var latest pgtype.Timestamptz
+  summary, err := scanSummary(trailingScanner{scanner: scanner, extra: []any{&latest}})
+  if err != nil {
+    return Summary{}, since, err
+  }
+  if !latest.Valid {
+    return summary, since, nil
+  }
+  return summary, latest.Time, nil
+}
+
+const summaryTotalsColumns = `
   count(*),
   coalesce(sum(forward_fee_revenue_sats), 0),
   coalesce(sum(forward_fee_revenue_msat), 0),
@@ -203,10 +643,36 @@ select
   coalesce(sum(rebalance_count), 0),
   coalesce(sum(routed_volume_sats), 0),
   coalesce(sum(routed_volume_msat), 0)
-from reports_daily
-where report_date >= $1 and report_date <= $2
-`, normalizeReportDate(startDate), normalizeReportDate(endDate)).Scan(
-    &days,
+`
+
+const summaryExtremesColumns = `
+  coalesce(max(forward_fee_revenue_sats), 0),
+  coalesce(max(forward_fee_revenue_msat), 0),
+  coalesce(max(rebalance_fee_cost_sats), 0),
+  coalesce(max(rebalance_fee_cost_msat), 0),
+  coalesce(max(net_routing_profit_sats), 0),
+  coalesce(max(net_routing_profit_msat), 0),
+  coalesce(max(forward_count), 0),
+  coalesce(max(rebalance_count), 0),
+  coalesce(max(routed_volume_sats), 0),
+  coalesce(max(routed_volume_msat), 0),
+  coalesce(percentile_cont(0.5) within group (order by forward_fee_revenue_sats), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by forward_fee_revenue_msat), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by rebalance_fee_cost_sats), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by rebalance_fee_cost_msat), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by net_routing_profit_sats), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by net_routing_profit_msat), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by forward_count), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by rebalance_count), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by routed_volume_sats), 0)::bigint,
+  coalesce(percentile_cont(0.5) within group (order by routed_volume_msat), 0)::bigint
+`
+
+const summarySelect = "select" + summaryTotalsColumns + "," + summaryExtremesColumns + "from reports_daily\n"
+
+func summaryTotalsDest(days *int64, totals *Metrics) []any {
+  return []any{
+    days,
     &totals.ForwardFeeRevenueSat,
     &totals.ForwardFeeRevenueMsat,
     &totals.RebalanceFeeCostSat,
@@ -217,23 +683,73 @@ where report_date >= $1 and report_date <= $2
     &totals.RebalanceCount,
     &totals.RoutedVolumeSat,
     &totals.RoutedVolumeMsat,
-  )
-  if err != nil {
+  }
+}
+
+func summaryExtremesDest(maxes *Metrics, medians *Metrics) []any {
+  return []any{
+    &maxes.ForwardFeeRevenueSat,
+    &maxes.ForwardFeeRevenueMsat,
+    &maxes.RebalanceFeeCostSat,
+    &maxes.RebalanceFeeCostMsat,
+    &maxes.NetRoutingProfitSat,
+    &maxes.NetRoutingProfitMsat,
+    &maxes.ForwardCount,
+    &maxes.RebalanceCount,
+    &maxes.RoutedVolumeSat,
+    &maxes.RoutedVolumeMsat,
+    &medians.ForwardFeeRevenueSat,
+    &medians.ForwardFeeRevenueMsat,
+    &medians.RebalanceFeeCostSat,
+    &medians.RebalanceFeeCostMsat,
+    &medians.NetRoutingProfitSat,
+    &medians.NetRoutingProfitMsat,
+    &medians.ForwardCount,
+    &medians.RebalanceCount,
+    &medians.RoutedVolumeSat,
+    &medians.RoutedVolumeMsat,
+  }
+}
+
+func scanSummary(scanner rowScanner) (Summary, error) {
+  var days int64
+  var totals, maxes, medians Metrics
+  dest := append(summaryTotalsDest(&days, &totals), summaryExtremesDest(&maxes, &medians)...)
+  if err := scanner.Scan(dest...); err != nil {
     return Summary{}, err
   }
+  return buildSummary(days, totals, maxes, medians), nil
+}
 
+func buildSummary(days int64, totals Metrics, maxes Metrics, medians Metrics) Summary {
   fillMsatFromSat(&totals)
-  return Summary{Days: days, Totals: totals, Averages: averageMetrics(totals, days)}, nil
+  fillMsatFromSat(&maxes)
+  fillMsatFromSat(&medians)
+  return Summary{
+    Days: days,
+    HasData: days > 0,
+    Totals: totals,
+    Averages: averageMetrics(totals, days),
+    Max: maxes,
+    Median: medians,
+    EffectivePpm: effectivePpm(totals),
+    RebalanceCostRatio: rebalanceCostRatio(totals),
+  }
 }
 
-func FetchSummaryAll(ctx context.Context, db *pgxpool.Pool) (Summary, error) {
+func FetchRollup(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time, granularity Granularity) (buckets []RollupBucket, err error) {
   if db == nil {
-    return Summary{}, nil
+    return nil, nil
   }
-  var days int64
-  totals := Metrics{}
-  err := db.QueryRow(ctx, `
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  unit, err := granularity.truncUnit()
+  if err != nil {
+    return nil, err
+  }
+  rows, err := db.Query(ctx, `
 select
+  date_trunc($1, report_date::timestamp)::date as bucket_start,
   count(*),
   coalesce(sum(forward_fee_revenue_sats), 0),
   coalesce(sum(forward_fee_revenue_msat), 0),
@@ -246,49 +762,233 @@ select
   coalesce(sum(routed_volume_sats), 0),
   coalesce(sum(routed_volume_msat), 0)
 from reports_daily
-`).Scan(
-    &days,
-    &totals.ForwardFeeRevenueSat,
-    &totals.ForwardFeeRevenueMsat,
-    &totals.RebalanceFeeCostSat,
-    &totals.RebalanceFeeCostMsat,
-    &totals.NetRoutingProfitSat,
-    &totals.NetRoutingProfitMsat,
-    &totals.ForwardCount,
-    &totals.RebalanceCount,
-    &totals.RoutedVolumeSat,
-    &totals.RoutedVolumeMsat,
-  )
+where report_date >= $2 and report_date <= $3
+group by 1
+order by 1 asc
+`, unit, normalizeReportDate(startDate), normalizeReportDate(endDate))
   if err != nil {
-    return Summary{}, err
+    return nil, err
   }
+  defer rows.Close()
 
-  fillMsatFromSat(&totals)
-  return Summary{Days: days, Totals: totals, Averages: averageMetrics(totals, days)}, nil
+  for rows.Next() {
+    var bucket RollupBucket
+    totals := Metrics{}
+    if err := rows.Scan(
+      &bucket.BucketStart,
+      &bucket.Days,
+      &totals.ForwardFeeRevenueSat,
+      &totals.ForwardFeeRevenueMsat,
+      &totals.RebalanceFeeCostSat,
+      &totals.RebalanceFeeCostMsat,
+      &totals.NetRoutingProfitSat,
+      &totals.NetRoutingProfitMsat,
+      &totals.ForwardCount,
+      &totals.RebalanceCount,
+      &totals.RoutedVolumeSat,
+      &totals.RoutedVolumeMsat,
+    ); err != nil {
+      return nil, err
+    }
+    fillMsatFromSat(&totals)
+    bucket.Totals = totals
+    bucket.Averages = averageMetrics(totals, bucket.Days)
+    buckets = append(buckets, bucket)
+  }
+  return buckets, rows.Err()
+}
+
+// bucketIntervals is the allowlist for FetchSummaryBucketed. Intervals never
+// reach SQL as text: they map to a Granularity whose date_trunc unit is bound
+// as a parameter.
+var bucketIntervals = map[string]Granularity{
+  "1 day": Daily,
+  "1 week": Weekly,
+  "1 month": Monthly,
+  "1 quarter": Quarterly,
+  "1 year": Yearly,
+}
+
+// ParseBucketInterval maps an interval such as "1 week" or "1 Month" to its
+// Granularity. Case and repeated spaces are ignored; anything outside the
+// allowlist is an error.
+func ParseBucketInterval(interval string) (Granularity, error) {
+  key := strings.ToLower(strings.Join(strings.Fields(interval), " "))
+  granularity, ok := bucketIntervals[key]
+  if !ok {
+    return 0, fmt.Errorf("invalid interval %q: use 1 day, 1 week, 1 month, 1 quarter or 1 year", interval)
+  }
+  return granularity, nil
+}
+
+// FetchSummaryBucketed is FetchRollup keyed by an interval string, for callers
+// that take the bucket size from user input.
+func FetchSummaryBucketed(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time, interval string) ([]RollupBucket, error) {
+  granularity, err := ParseBucketInterval(interval)
+  if err != nil {
+    return nil, err
+  }
+  return FetchRollup(ctx, db, startDate, endDate, granularity)
+}
+
+func FetchByWeekday(ctx context.Context, db *pgxpool.Pool, startDate, endDate time.Time) (weekdays [7]Metrics, err error) {
+  var buckets [7]Metrics
+  if db == nil {
+    return buckets, nil
+  }
+  ctx, done := startQuery(ctx)
+  defer done(&err)
+  rows, err := db.Query(ctx, `
+select
+  extract(dow from report_date)::int,
+  coalesce(sum(forward_fee_revenue_sats), 0),
+  coalesce(sum(forward_fee_revenue_msat), 0),
+  coalesce(sum(rebalance_fee_cost_sats), 0),
+  coalesce(sum(rebalance_fee_cost_msat), 0),
+  coalesce(sum(net_routing_profit_sats), 0),
+  coalesce(sum(net_routing_profit_msat), 0),
+  coalesce(sum(forward_count), 0),
+  coalesce(sum(rebalance_count), 0),
+  coalesce(sum(routed_volume_sats), 0),
+  coalesce(sum(routed_volume_msat), 0)
+from reports_daily
+where report_date >= $1 and report_date <= $2
+group by 1
+`, normalizeReportDate(startDate), normalizeReportDate(endDate))
+  if err != nil {
+    return buckets, err
+  }
+  defer rows.Close()
+
+  for rows.Next() {
+    var dow int
+    totals := Metrics{}
+    if err := rows.Scan(
+      &dow,
+      &totals.ForwardFeeRevenueSat,
+      &totals.ForwardFeeRevenueMsat,
+      &totals.RebalanceFeeCostSat,
+      &totals.RebalanceFeeCostMsat,
+      &totals.NetRoutingProfitSat,
+      &totals.NetRoutingProfitMsat,
+      &totals.ForwardCount,
+      &totals.RebalanceCount,
+      &totals.RoutedVolumeSat,
+      &totals.RoutedVolumeMsat,
+    ); err != nil {
+      return buckets, err
+    }
+    if dow < 0 || dow > 6 {
+      continue
+    }
+    fillMsatFromSat(&totals)
+    buckets[dow] = totals
+  }
+  return buckets, rows.Err()
+}
+
+func (o SortOrder) sqlDirection() (string, error) {
+  switch o {
+  case Ascending:
+    return "asc", nil
+  case Descending:
+    return "desc", nil
+  default:
+    return "", fmt.Errorf("invalid sort order: %d", o)
+  }
+}
+
+func (g Granularity) truncUnit() (string, error) {
+  switch g {
+  case Daily:
+    return "day", nil
+  case Weekly:
+    return "week", nil
+  case Monthly:
+    return "month", nil
+  case Quarterly:
+    return "quarter", nil
+  case Yearly:
+    return "year", nil
+  default:
+    return "", fmt.Errorf("invalid granularity: %d", g)
+  }
+}
+
+func effectivePpm(totals Metrics) float64 {
+  if totals.RoutedVolumeSat <= 0 {
+    return 0
+  }
+  return float64(totals.ForwardFeeRevenueMsat) / float64(totals.RoutedVolumeSat) * 1000
+}
+
+func rebalanceCostRatio(totals Metrics) *float64 {
+  if totals.ForwardFeeRevenueMsat <= 0 {
+    return nil
+  }
+  ratio := float64(totals.RebalanceFeeCostMsat) / float64(totals.ForwardFeeRevenueMsat)
+  return &ratio
 }
 
 func averageMetrics(totals Metrics, days int64) Metrics {
+  return AverageMetrics(totals, days, RoundTruncate)
+}
+
+// AverageMetrics divides totals by days. Summaries store truncated averages;
+// RoundHalfUp is for display and only changes the sat/msat amounts, counts are
+// always truncated.
+func AverageMetrics(totals Metrics, days int64, mode RoundingMode) Metrics {
   if days <= 0 {
     return Metrics{}
   }
+  div := func(total int64) int64 {
+    return divideRounded(total, days, mode)
+  }
   return Metrics{
-    ForwardFeeRevenueSat: totals.ForwardFeeRevenueSat / days,
-    ForwardFeeRevenueMsat: totals.ForwardFeeRevenueMsat / days,
-    RebalanceFeeCostSat: totals.RebalanceFeeCostSat / days,
-    RebalanceFeeCostMsat: totals.RebalanceFeeCostMsat / days,
-    NetRoutingProfitSat: totals.NetRoutingProfitSat / days,
-    NetRoutingProfitMsat: totals.NetRoutingProfitMsat / days,
+    ForwardFeeRevenueSat: div(totals.ForwardFeeRevenueSat),
+    ForwardFeeRevenueMsat: div(totals.ForwardFeeRevenueMsat),
+    RebalanceFeeCostSat: div(totals.RebalanceFeeCostSat),
+    RebalanceFeeCostMsat: div(totals.RebalanceFeeCostMsat),
+    NetRoutingProfitSat: div(totals.NetRoutingProfitSat),
+    NetRoutingProfitMsat: div(totals.NetRoutingProfitMsat),
     ForwardCount: totals.ForwardCount / days,
     RebalanceCount: totals.RebalanceCount / days,
-    RoutedVolumeSat: totals.RoutedVolumeSat / days,
-    RoutedVolumeMsat: totals.RoutedVolumeMsat / days,
+    RoutedVolumeSat: div(totals.RoutedVolumeSat),
+    RoutedVolumeMsat: div(totals.RoutedVolumeMsat),
   }
 }
 
+// divideRounded divides by a positive divisor. RoundHalfUp rounds halves away
+// from zero so a negative net profit of -2.5 averages to -3, mirroring 2.5 -> 3.
+func divideRounded(total, divisor int64, mode RoundingMode) int64 {
+  q := total / divisor
+  if mode != RoundHalfUp {
+    return q
+  }
+  r := total % divisor
+  if r < 0 {
+    if -r*2 >= divisor {
+      q--
+    }
+  } else if r*2 >= divisor {
+    q++
+  }
+  return q
+}
+
 type rowScanner interface {
   Scan(dest ...any) error
 }
 
+type trailingScanner struct {
+  scanner rowScanner
+  extra []any
+}
+
+func (t trailingScanner) Scan(dest ...any) error {
+  return t.scanner.Scan(append(dest, t.extra...)...)
+}
+
 func scanRow(scanner rowScanner) (Row, error) {
   var reportDate time.Time
   var metrics Metrics
@@ -327,6 +1027,7 @@ func scanRow(scanner rowScanner) (Row, error) {
     metrics.TotalBalanceSat = &val
   }
   fillMsatFromSat(&metrics)
+  fillOnchainRatio(&metrics)
   return Row{ReportDate: reportDate, Metrics: metrics}, nil
 }
 
@@ -337,6 +1038,9 @@ func nullableInt64(value *int64) any {
   return *value
 }
 
+// normalizeReportDate keeps the calendar day of a value that already is a
+// report date and stores it as a UTC date. It never shifts zones, so applying
+// it twice is harmless; instants become report days through dateOnly.
 func normalizeReportDate(value time.Time) time.Time {
   return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
 }
@@ -346,15 +1050,37 @@ func fillMsatFromSat(metrics *Metrics) {
     return
   }
   if metrics.ForwardFeeRevenueMsat == 0 && metrics.ForwardFeeRevenueSat != 0 {
-    metrics.ForwardFeeRevenueMsat = metrics.ForwardFeeRevenueSat * 1000
+    metrics.ForwardFeeRevenueMsat = satToMsat(metrics.ForwardFeeRevenueSat)
   }
   if metrics.RebalanceFeeCostMsat == 0 && metrics.RebalanceFeeCostSat != 0 {
-    metrics.RebalanceFeeCostMsat = metrics.RebalanceFeeCostSat * 1000
+    metrics.RebalanceFeeCostMsat = satToMsat(metrics.RebalanceFeeCostSat)
   }
   if metrics.NetRoutingProfitMsat == 0 && metrics.NetRoutingProfitSat != 0 {
-    metrics.NetRoutingProfitMsat = metrics.NetRoutingProfitSat * 1000
+    metrics.NetRoutingProfitMsat = satToMsat(metrics.NetRoutingProfitSat)
   }
   if metrics.RoutedVolumeMsat == 0 && metrics.RoutedVolumeSat != 0 {
-    metrics.RoutedVolumeMsat = metrics.RoutedVolumeSat * 1000
+    metrics.RoutedVolumeMsat = satToMsat(metrics.RoutedVolumeSat)
+  }
+}
+
+func fillOnchainRatio(metrics *Metrics) {
+  if metrics == nil {
+    return
+  }
+  metrics.OnchainRatio = nil
+  if metrics.OnchainBalanceSat == nil || metrics.TotalBalanceSat == nil || *metrics.TotalBalanceSat == 0 {
+    return
+  }
+  ratio := float64(*metrics.OnchainBalanceSat) / float64(*metrics.TotalBalanceSat)
+  metrics.OnchainRatio = &ratio
+}
+
+func satToMsat(sat int64) int64 {
+  if sat > math.MaxInt64/1000 {
+    return math.MaxInt64
+  }
+  if sat < math.MinInt64/1000 {
+    return math.MinInt64
   }
+  return sat * 1000
 }
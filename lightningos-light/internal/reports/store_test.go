@@ -1,9 +1,17 @@
 package reports
 
 import (
+  "context"
+  "errors"
+  "fmt"
+  "math"
+  "os"
   "strings"
   "testing"
   "time"
+
+  "github.com/jackc/pgx/v5/pgtype"
+  "github.com/jackc/pgx/v5/pgxpool"
 )
 
 func TestBuildUpsertDaily(t *testing.T) {
@@ -46,3 +54,494 @@ func TestBuildUpsertDaily(t *testing.T) {
     t.Fatalf("unexpected metrics args")
   }
 }
+
+func TestGranularityTruncUnit(t *testing.T) {
+  cases := map[Granularity]string{
+    Daily: "day",
+    Weekly: "week",
+    Monthly: "month",
+    Quarterly: "quarter",
+    Yearly: "year",
+  }
+  for granularity, want := range cases {
+    got, err := granularity.truncUnit()
+    if err != nil {
+      t.Fatalf("unexpected error for %d: %v", granularity, err)
+    }
+    if got != want {
+      t.Fatalf("expected %s, got %s", want, got)
+    }
+  }
+  if _, err := Granularity(99).truncUnit(); err == nil {
+    t.Fatalf("expected error for unknown granularity")
+  }
+}
+
+func TestFillMsatFromSatClampsOverflow(t *testing.T) {
+  metrics := Metrics{
+    RoutedVolumeSat: math.MaxInt64/1000 + 1,
+    NetRoutingProfitSat: math.MinInt64/1000 - 1,
+    ForwardFeeRevenueSat: 1500,
+  }
+  fillMsatFromSat(&metrics)
+  if metrics.RoutedVolumeMsat != math.MaxInt64 {
+    t.Fatalf("expected clamped msat volume, got %d", metrics.RoutedVolumeMsat)
+  }
+  if metrics.NetRoutingProfitMsat != math.MinInt64 {
+    t.Fatalf("expected clamped negative msat profit, got %d", metrics.NetRoutingProfitMsat)
+  }
+  if metrics.ForwardFeeRevenueMsat != 1500000 {
+    t.Fatalf("expected 1500000 msat revenue, got %d", metrics.ForwardFeeRevenueMsat)
+  }
+}
+
+type fakeScanner struct {
+  got int
+}
+
+func (f *fakeScanner) Scan(dest ...any) error {
+  f.got = len(dest)
+  return nil
+}
+
+func TestTrailingScannerAppendsExtraDest(t *testing.T) {
+  inner := &fakeScanner{}
+  var updatedAt time.Time
+  scanner := trailingScanner{scanner: inner, extra: []any{&updatedAt}}
+  if _, err := scanRow(scanner); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if inner.got != 15 {
+    t.Fatalf("expected 15 scan targets, got %d", inner.got)
+  }
+}
+
+func TestNormalizePageLimit(t *testing.T) {
+  if _, err := normalizePageLimit(0); err == nil {
+    t.Fatalf("expected error for zero limit")
+  }
+  if _, err := normalizePageLimit(-5); err == nil {
+    t.Fatalf("expected error for negative limit")
+  }
+  if got, _ := normalizePageLimit(50); got != 50 {
+    t.Fatalf("expected 50, got %d", got)
+  }
+  if got, _ := normalizePageLimit(5000); got != maxPageLimit {
+    t.Fatalf("expected cap at %d, got %d", maxPageLimit, got)
+  }
+}
+
+func TestPageCursorIsOldestRow(t *testing.T) {
+  items := []Row{
+    {ReportDate: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+    {ReportDate: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC)},
+  }
+  if got := pageCursor(items); !got.Equal(items[1].ReportDate) {
+    t.Fatalf("unexpected cursor: %v", got)
+  }
+  if got := pageCursor(nil); !got.IsZero() {
+    t.Fatalf("expected zero cursor for empty page")
+  }
+}
+
+func TestFillOnchainRatio(t *testing.T) {
+  onchain := int64(250)
+  total := int64(1000)
+  zero := int64(0)
+
+  metrics := Metrics{OnchainBalanceSat: &onchain, TotalBalanceSat: &total}
+  fillOnchainRatio(&metrics)
+  if metrics.OnchainRatio == nil || *metrics.OnchainRatio != 0.25 {
+    t.Fatalf("expected ratio 0.25, got %v", metrics.OnchainRatio)
+  }
+
+  metrics = Metrics{OnchainBalanceSat: &onchain}
+  fillOnchainRatio(&metrics)
+  if metrics.OnchainRatio != nil {
+    t.Fatalf("expected nil ratio without total")
+  }
+
+  metrics = Metrics{OnchainBalanceSat: &zero, TotalBalanceSat: &zero}
+  fillOnchainRatio(&metrics)
+  if metrics.OnchainRatio != nil {
+    t.Fatalf("expected nil ratio for zero total")
+  }
+}
+
+func TestPingNilDatabase(t *testing.T) {
+  if err := Ping(context.Background(), nil); !errors.Is(err, ErrNoDatabase) {
+    t.Fatalf("expected ErrNoDatabase, got %v", err)
+  }
+}
+
+func TestMonthRange(t *testing.T) {
+  cases := []struct {
+    year int
+    month time.Month
+    end string
+  }{
+    {2024, time.February, "2024-02-29"},
+    {2023, time.February, "2023-02-28"},
+    {2024, time.December, "2024-12-31"},
+    {2024, time.April, "2024-04-30"},
+  }
+  for _, tc := range cases {
+    start, end, err := monthRange(tc.year, tc.month)
+    if err != nil {
+      t.Fatalf("unexpected error: %v", err)
+    }
+    if start.Day() != 1 || start.Month() != tc.month || start.Year() != tc.year {
+      t.Fatalf("unexpected start %s", start)
+    }
+    if got := end.Format("2006-01-02"); got != tc.end {
+      t.Fatalf("expected end %s, got %s", tc.end, got)
+    }
+  }
+
+  if _, _, err := monthRange(2024, 0); err == nil {
+    t.Fatalf("expected error for month 0")
+  }
+  if _, _, err := monthRange(2024, 13); err == nil {
+    t.Fatalf("expected error for month 13")
+  }
+}
+
+func TestSortOrderSQLDirection(t *testing.T) {
+  if got, err := Ascending.sqlDirection(); err != nil || got != "asc" {
+    t.Fatalf("expected asc, got %q (%v)", got, err)
+  }
+  if got, err := Descending.sqlDirection(); err != nil || got != "desc" {
+    t.Fatalf("expected desc, got %q (%v)", got, err)
+  }
+  if _, err := SortOrder(7).sqlDirection(); err == nil {
+    t.Fatalf("expected error for invalid order")
+  }
+}
+
+// TestFetchRangeOrderedPostgres needs a scratch database; it runs in its own
+// schema and drops it afterwards.
+func TestFetchRangeOrderedPostgres(t *testing.T) {
+  dsn := os.Getenv("LIGHTNINGOS_TEST_DATABASE_URL")
+  if dsn == "" {
+    t.Skip("LIGHTNINGOS_TEST_DATABASE_URL not set")
+  }
+  ctx := context.Background()
+  admin, err := pgxpool.New(ctx, dsn)
+  if err != nil {
+    t.Fatalf("connect: %v", err)
+  }
+  defer admin.Close()
+  schema := fmt.Sprintf("reports_test_%d", time.Now().UnixNano())
+  if _, err := admin.Exec(ctx, "create schema "+schema); err != nil {
+    t.Fatalf("create schema: %v", err)
+  }
+  defer admin.Exec(ctx, "drop schema "+schema+" cascade")
+
+  cfg, err := pgxpool.ParseConfig(dsn)
+  if err != nil {
+    t.Fatalf("parse dsn: %v", err)
+  }
+  cfg.ConnConfig.RuntimeParams["search_path"] = schema
+  db, err := pgxpool.NewWithConfig(ctx, cfg)
+  if err != nil {
+    t.Fatalf("connect: %v", err)
+  }
+  defer db.Close()
+  if err := EnsureSchema(ctx, db); err != nil {
+    t.Fatalf("ensure schema: %v", err)
+  }
+
+  start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+  var rows []Row
+  for i := 0; i < 3; i++ {
+    rows = append(rows, Row{ReportDate: start.AddDate(0, 0, i), Metrics: Metrics{ForwardCount: int64(i + 1)}})
+  }
+  if err := UpsertDailyBatch(ctx, db, rows); err != nil {
+    t.Fatalf("upsert: %v", err)
+  }
+
+  want := map[SortOrder][]string{
+    Ascending: {"2026-03-01", "2026-03-02", "2026-03-03"},
+    Descending: {"2026-03-03", "2026-03-02", "2026-03-01"},
+  }
+  for order, dates := range want {
+    items, err := FetchRangeOrdered(ctx, db, start, start.AddDate(0, 0, 2), order)
+    if err != nil {
+      t.Fatalf("fetch %v: %v", order, err)
+    }
+    if len(items) != len(dates) {
+      t.Fatalf("order %v: expected %d rows, got %d", order, len(dates), len(items))
+    }
+    for i, date := range dates {
+      if got := items[i].ReportDate.Format("2006-01-02"); got != date {
+        t.Fatalf("order %v: row %d is %s, want %s", order, i, got, date)
+      }
+    }
+  }
+}
+
+func TestEnsureDaysExistNilDB(t *testing.T) {
+  start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+  if err := EnsureDaysExist(context.Background(), nil, start, start.AddDate(0, 0, 6)); err != nil {
+    t.Fatalf("expected nil db to be a no-op, got %v", err)
+  }
+}
+
+func TestBuildEnsureDaysExist(t *testing.T) {
+  start := time.Date(2026, 3, 1, 22, 30, 0, 0, time.FixedZone("Local", -3*60*60))
+  query, args, err := buildEnsureDaysExist(start, start.AddDate(0, 0, 6))
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if !strings.Contains(query, "generate_series($1::date, $2::date, interval '1 day')") {
+    t.Fatalf("expected generate_series over the range")
+  }
+  if !strings.Contains(query, "on conflict (report_date) do nothing") || strings.Contains(query, "do update") {
+    t.Fatalf("expected existing rows to be left alone")
+  }
+  if len(args) != 2 {
+    t.Fatalf("expected 2 args, got %d", len(args))
+  }
+  from, _ := args[0].(time.Time)
+  to, _ := args[1].(time.Time)
+  if from.Format("2006-01-02") != "2026-03-01" || to.Format("2006-01-02") != "2026-03-07" {
+    t.Fatalf("unexpected range args: %v %v", args[0], args[1])
+  }
+  if _, _, err := buildEnsureDaysExist(start, start.AddDate(0, 0, -1)); err == nil {
+    t.Fatalf("expected error for reversed range")
+  }
+}
+
+func TestFetchSummarySinceNilDB(t *testing.T) {
+  since := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+  summary, cursor, err := FetchSummarySince(context.Background(), nil, since)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if summary.Days != 0 || !cursor.Equal(since) {
+    t.Fatalf("expected empty summary and unchanged cursor, got %+v %s", summary, cursor)
+  }
+}
+
+type summarySinceScanner struct {
+  days int64
+  latest *time.Time
+  err error
+}
+
+func (f summarySinceScanner) Scan(dest ...any) error {
+  if f.err != nil {
+    return f.err
+  }
+  *dest[0].(*int64) = f.days
+  if f.latest != nil {
+    *dest[len(dest)-1].(*pgtype.Timestamptz) = pgtype.Timestamptz{Time: *f.latest, Valid: true}
+  }
+  return nil
+}
+
+func TestScanSummarySinceCursor(t *testing.T) {
+  since := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+  summary, cursor, err := scanSummarySince(summarySinceScanner{}, since)
+  if err != nil || summary.HasData || !cursor.Equal(since) {
+    t.Fatalf("expected unchanged cursor when nothing changed, got %+v %s %v", summary, cursor, err)
+  }
+
+  latest := since.Add(90 * time.Minute)
+  summary, cursor, err = scanSummarySince(summarySinceScanner{days: 2, latest: &latest}, since)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if summary.Days != 2 || !cursor.Equal(latest) {
+    t.Fatalf("expected 2 changed days and cursor %s, got %d %s", latest, summary.Days, cursor)
+  }
+
+  _, cursor, err = scanSummarySince(summarySinceScanner{err: errors.New("boom")}, since)
+  if err == nil || !cursor.Equal(since) {
+    t.Fatalf("expected error to keep the cursor, got %s %v", cursor, err)
+  }
+}
+
+func TestDeleteRangeBounds(t *testing.T) {
+  start := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+  end := time.Date(2026, 3, 5, 8, 0, 0, 0, time.UTC)
+  gotStart, gotEnd, err := deleteRangeBounds(start, end)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if gotStart.Format("2006-01-02") != "2026-03-01" || gotEnd.Format("2006-01-02") != "2026-03-05" {
+    t.Fatalf("unexpected bounds: %s %s", gotStart, gotEnd)
+  }
+  if _, _, err := deleteRangeBounds(start, start); err != nil {
+    t.Fatalf("expected a single day range to be valid, got %v", err)
+  }
+  if _, err := DeleteRange(context.Background(), nil, end, start); err == nil {
+    t.Fatalf("expected start after end to be rejected")
+  }
+  if _, _, err := deleteRangeBounds(time.Time{}, end); err == nil {
+    t.Fatalf("expected zero start to be rejected")
+  }
+}
+
+func TestSummaryScanTargetsSplit(t *testing.T) {
+  var days int64
+  var totals, maxes, medians Metrics
+  if got := len(summaryTotalsDest(&days, &totals)); got != 11 {
+    t.Fatalf("expected 11 totals targets, got %d", got)
+  }
+  if got := len(summaryExtremesDest(&maxes, &medians)); got != 20 {
+    t.Fatalf("expected 20 extremes targets, got %d", got)
+  }
+  inner := &fakeScanner{}
+  if _, err := scanSummary(inner); err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if inner.got != 31 {
+    t.Fatalf("expected 31 scan targets, got %d", inner.got)
+  }
+}
+
+func TestBuildSummaryKeepsTotalsWithoutExtremes(t *testing.T) {
+  totals := Metrics{ForwardFeeRevenueSat: 30, RoutedVolumeSat: 30000, ForwardCount: 6}
+  summary := buildSummary(3, totals, Metrics{}, Metrics{})
+  if summary.Totals.ForwardFeeRevenueMsat != 30000 {
+    t.Fatalf("expected msat filled from sats, got %d", summary.Totals.ForwardFeeRevenueMsat)
+  }
+  if summary.Averages.ForwardCount != 2 {
+    t.Fatalf("expected average forward count 2, got %d", summary.Averages.ForwardCount)
+  }
+  if summary.EffectivePpm != 1000 {
+    t.Fatalf("expected 1000 ppm, got %v", summary.EffectivePpm)
+  }
+  if summary.Max.ForwardCount != 0 || summary.Partial {
+    t.Fatalf("unexpected extremes: %+v", summary)
+  }
+}
+
+func TestBuildSummaryHasData(t *testing.T) {
+  if summary := buildSummary(0, Metrics{}, Metrics{}, Metrics{}); summary.HasData {
+    t.Fatalf("expected empty table to report no data")
+  }
+  if summary := buildSummary(1, Metrics{}, Metrics{}, Metrics{}); !summary.HasData {
+    t.Fatalf("expected a zero-activity day to still count as data")
+  }
+}
+
+func TestPgStoreReaderFallsBackToPrimary(t *testing.T) {
+  primary, err := pgxpool.New(context.Background(), "postgres://reports@127.0.0.1:1/primary")
+  if err != nil {
+    t.Fatalf("primary pool: %v", err)
+  }
+  defer primary.Close()
+  replica, err := pgxpool.New(context.Background(), "postgres://reports@127.0.0.1:1/replica")
+  if err != nil {
+    t.Fatalf("replica pool: %v", err)
+  }
+  defer replica.Close()
+
+  if got := NewPgStore(primary, DefaultOptions()).reader(); got != primary {
+    t.Fatalf("expected primary pool without replica")
+  }
+  if got := NewPgStoreWithReplica(primary, nil, DefaultOptions()).reader(); got != primary {
+    t.Fatalf("expected nil replica to fall back to primary")
+  }
+  if got := NewPgStoreWithReplica(primary, replica, DefaultOptions()).reader(); got != replica {
+    t.Fatalf("expected reads to use the replica")
+  }
+  if got := NewPgStoreWithReplica(primary, replica, DefaultOptions()).summaryReader(); got != primary {
+    t.Fatalf("expected the cached all-time summary to be read from the primary")
+  }
+}
+
+func TestAverageMetricsRounding(t *testing.T) {
+  totals := Metrics{
+    ForwardFeeRevenueSat: 5,
+    ForwardFeeRevenueMsat: 5001,
+    RebalanceFeeCostSat: 4,
+    RebalanceFeeCostMsat: 4999,
+    NetRoutingProfitSat: -5,
+    NetRoutingProfitMsat: -3,
+    ForwardCount: 5,
+  }
+
+  truncated := AverageMetrics(totals, 2, RoundTruncate)
+  if truncated != averageMetrics(totals, 2) {
+    t.Fatalf("expected truncate to match the stored averages")
+  }
+  if truncated.ForwardFeeRevenueSat != 2 || truncated.ForwardFeeRevenueMsat != 2500 || truncated.NetRoutingProfitSat != -2 || truncated.NetRoutingProfitMsat != -1 {
+    t.Fatalf("unexpected truncated averages: %+v", truncated)
+  }
+
+  rounded := AverageMetrics(totals, 2, RoundHalfUp)
+  if rounded.ForwardFeeRevenueSat != 3 {
+    t.Fatalf("expected 2.5 sat to round to 3, got %d", rounded.ForwardFeeRevenueSat)
+  }
+  if rounded.ForwardFeeRevenueMsat != 2501 {
+    t.Fatalf("expected 2500.5 msat to round to 2501, got %d", rounded.ForwardFeeRevenueMsat)
+  }
+  if rounded.RebalanceFeeCostSat != 2 || rounded.RebalanceFeeCostMsat != 2500 {
+    t.Fatalf("expected exact and 2499.5 averages to be 2 and 2500, got %+v", rounded)
+  }
+  if rounded.NetRoutingProfitSat != -3 || rounded.NetRoutingProfitMsat != -2 {
+    t.Fatalf("expected negative halves to round away from zero, got %+v", rounded)
+  }
+  if rounded.ForwardCount != 2 {
+    t.Fatalf("expected counts to stay truncated, got %d", rounded.ForwardCount)
+  }
+  if got := AverageMetrics(totals, 3, RoundHalfUp).ForwardFeeRevenueMsat; got != 1667 {
+    t.Fatalf("expected 1667 msat, got %d", got)
+  }
+}
+
+func TestCheckFetchAllRows(t *testing.T) {
+  if err := checkFetchAllRows(10, 10); err != nil {
+    t.Fatalf("expected no error at the limit, got %v", err)
+  }
+  if err := checkFetchAllRows(11, 10); !errors.Is(err, ErrTooManyRows) {
+    t.Fatalf("expected ErrTooManyRows, got %v", err)
+  }
+  if err := checkFetchAllRows(1_000_000, 0); err != nil {
+    t.Fatalf("expected zero limit to disable the check, got %v", err)
+  }
+}
+
+func TestParseBucketInterval(t *testing.T) {
+  cases := map[string]Granularity{
+    "1 day": Daily,
+    " 1  Week ": Weekly,
+    "1 MONTH": Monthly,
+    "1 quarter": Quarterly,
+    "1 year": Yearly,
+  }
+  for interval, want := range cases {
+    got, err := ParseBucketInterval(interval)
+    if err != nil || got != want {
+      t.Fatalf("%q: expected %d, got %d (%v)", interval, want, got, err)
+    }
+  }
+  for _, interval := range []string{"", "day", "2 weeks", "1 hour", "1 day'); drop table reports_daily; --"} {
+    if _, err := ParseBucketInterval(interval); err == nil {
+      t.Fatalf("expected %q to be rejected", interval)
+    }
+  }
+}
+
+func TestEffectivePpm(t *testing.T) {
+  got := effectivePpm(Metrics{ForwardFeeRevenueMsat: 1500000, RoutedVolumeSat: 2000000})
+  if got != 750 {
+    t.Fatalf("expected 750 ppm, got %v", got)
+  }
+  if got := effectivePpm(Metrics{ForwardFeeRevenueMsat: 1500000}); got != 0 {
+    t.Fatalf("expected 0 ppm for zero volume, got %v", got)
+  }
+}
+
+func TestRebalanceCostRatio(t *testing.T) {
+  ratio := rebalanceCostRatio(Metrics{ForwardFeeRevenueMsat: 4000, RebalanceFeeCostMsat: 1000})
+  if ratio == nil || *ratio != 0.25 {
+    t.Fatalf("expected 0.25, got %v", ratio)
+  }
+  if ratio := rebalanceCostRatio(Metrics{RebalanceFeeCostMsat: 1000}); ratio != nil {
+    t.Fatalf("expected nil ratio for zero revenue, got %v", *ratio)
+  }
+}
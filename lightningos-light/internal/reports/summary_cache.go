@@ -0,0 +1,74 @@
+package reports
+
+import (
+  "context"
+  "sync"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The all-time summary only changes when reports_daily is written. Writes in
+// this process invalidate it right away; the TTL bounds staleness for writes
+// from other processes (the nightly reports-run timer).
+const summaryCacheTTL = 5 * time.Minute
+
+type summaryCache struct {
+  mu sync.RWMutex
+  db *pgxpool.Pool
+  summary Summary
+  storedAt time.Time
+  generation uint64
+}
+
+var summaryAllCache summaryCache
+
+func (c *summaryCache) get(db *pgxpool.Pool, now time.Time) (Summary, uint64, bool) {
+  c.mu.RLock()
+  defer c.mu.RUnlock()
+  if c.db == nil || c.db != db || now.Sub(c.storedAt) >= summaryCacheTTL {
+    return Summary{}, c.generation, false
+  }
+  return copySummary(c.summary), c.generation, true
+}
+
+func (c *summaryCache) set(db *pgxpool.Pool, generation uint64, summary Summary, now time.Time) {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  if generation != c.generation {
+    return
+  }
+  c.db = db
+  c.summary = copySummary(summary)
+  c.storedAt = now
+}
+
+func (c *summaryCache) invalidate() {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  c.db = nil
+  c.summary = Summary{}
+  c.storedAt = time.Time{}
+  c.generation++
+}
+
+// InvalidateSummaryCache drops the cached all-time summary so the next
+// FetchSummaryAll hits the database.
+func InvalidateSummaryCache() {
+  summaryAllCache.invalidate()
+}
+
+// RefreshSummaryAll recomputes the all-time summary and replaces the cached
+// copy.
+func RefreshSummaryAll(ctx context.Context, db *pgxpool.Pool) (Summary, error) {
+  InvalidateSummaryCache()
+  return FetchSummaryAll(ctx, db)
+}
+
+func copySummary(summary Summary) Summary {
+  if summary.RebalanceCostRatio != nil {
+    ratio := *summary.RebalanceCostRatio
+    summary.RebalanceCostRatio = &ratio
+  }
+  return summary
+}
@@ -0,0 +1,54 @@
+package reports
+
+import (
+  "testing"
+  "time"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestSummaryCache(t *testing.T) {
+  var cache summaryCache
+  db := &pgxpool.Pool{}
+  now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+  _, generation, ok := cache.get(db, now)
+  if ok {
+    t.Fatalf("expected empty cache miss")
+  }
+  ratio := 0.5
+  cache.set(db, generation, Summary{Days: 3, RebalanceCostRatio: &ratio}, now)
+
+  cached, _, ok := cache.get(db, now.Add(time.Minute))
+  if !ok || cached.Days != 3 {
+    t.Fatalf("expected cache hit, got %+v (%v)", cached, ok)
+  }
+  *cached.RebalanceCostRatio = 9
+  if again, _, _ := cache.get(db, now); *again.RebalanceCostRatio != 0.5 {
+    t.Fatalf("cached summary was mutated through a returned copy")
+  }
+  if _, _, ok := cache.get(&pgxpool.Pool{}, now); ok {
+    t.Fatalf("expected miss for a different pool")
+  }
+  if _, _, ok := cache.get(db, now.Add(summaryCacheTTL)); ok {
+    t.Fatalf("expected miss after ttl")
+  }
+
+  cache.invalidate()
+  if _, _, ok := cache.get(db, now); ok {
+    t.Fatalf("expected miss after invalidate")
+  }
+}
+
+func TestSummaryCacheSkipsStaleGeneration(t *testing.T) {
+  var cache summaryCache
+  db := &pgxpool.Pool{}
+  now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+  _, generation, _ := cache.get(db, now)
+  cache.invalidate()
+  cache.set(db, generation, Summary{Days: 1}, now)
+  if _, _, ok := cache.get(db, now); ok {
+    t.Fatalf("summary computed before a write must not be cached")
+  }
+}
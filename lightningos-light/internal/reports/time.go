@@ -1,6 +1,7 @@
 package reports
 
 import (
+  "errors"
   "fmt"
   "time"
 )
@@ -14,7 +15,15 @@ const (
   RangeAll = "all"
 )
 
-const maxCustomRangeDays = 730
+// maxFutureDays allows an end date up to one day ahead of today so a client in
+// a timezone ahead of the server can still ask for its own "today".
+const maxFutureDays = 1
+
+var (
+  ErrInvalidRange = errors.New("invalid range")
+  ErrRangeTooLarge = errors.New("range too large")
+  ErrRangeInFuture = errors.New("range ends in the future")
+)
 
 type DateRange struct {
   StartDate time.Time
@@ -115,17 +124,24 @@ func dateOnly(value time.Time, loc *time.Location) time.Time {
   return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
 }
 
-func ValidateCustomRange(start, end time.Time) error {
+// ValidateCustomRange checks a from/to pair against maxDays (inclusive). The
+// future check uses today in loc, the zone the report days are cut in.
+func ValidateCustomRange(start, end time.Time, loc *time.Location, maxDays int) error {
+  return validateCustomRange(start, end, time.Now(), loc, maxDays)
+}
+
+func validateCustomRange(start, end time.Time, now time.Time, loc *time.Location, maxDays int) error {
+  start = normalizeReportDate(start)
+  end = normalizeReportDate(end)
   if end.Before(start) {
-    return fmt.Errorf("invalid range")
+    return ErrInvalidRange
+  }
+  if end.After(normalizeReportDate(dateOnly(now, loc)).AddDate(0, 0, maxFutureDays)) {
+    return ErrRangeInFuture
   }
   days := int(end.Sub(start).Hours()/24) + 1
-  if days > maxCustomRangeDays {
-    return fmt.Errorf("range too large")
+  if days > maxDays {
+    return ErrRangeTooLarge
   }
   return nil
 }
-
-func CustomRangeDaysLimit() int {
-  return maxCustomRangeDays
-}
@@ -1,6 +1,7 @@
 package reports
 
 import (
+  "errors"
   "testing"
   "time"
 )
@@ -68,3 +69,97 @@ func TestTimeRangeInclusiveEnd(t *testing.T) {
 func sameDate(a, b time.Time) bool {
   return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
 }
+
+func TestNormalizeReportDateTimezone(t *testing.T) {
+  value := time.Date(2026, 1, 15, 1, 30, 0, 0, time.UTC)
+
+  if got := normalizeReportDate(value).Format("2006-01-02"); got != "2026-01-15" {
+    t.Fatalf("expected default to keep the calendar day, got %s", got)
+  }
+
+  loc, err := LoadTimezone("America/Sao_Paulo")
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if got := normalizeReportDate(value); got.Format("2006-01-02") != "2026-01-15" || got.Location() != time.UTC {
+    t.Fatalf("expected a date to keep its calendar day, got %s", got)
+  }
+  if got := dateOnly(value, loc).Format("2006-01-02"); got != "2026-01-14" {
+    t.Fatalf("expected the instant to fall on 2026-01-14 in the report zone, got %s", got)
+  }
+
+  if _, err := LoadTimezone("Not/AZone"); err == nil {
+    t.Fatalf("expected invalid timezone error")
+  }
+  if loc, err := LoadTimezone(""); err != nil || loc != nil {
+    t.Fatalf("expected empty name to mean the local zone")
+  }
+  if got := (Options{}).Location(); got != time.Local {
+    t.Fatalf("expected options without a timezone to use time.Local, got %s", got)
+  }
+}
+
+func TestReportDateRoundTripWestOfUTC(t *testing.T) {
+  loc, err := LoadTimezone("America/Los_Angeles")
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+
+  parsed, err := ParseDate("2026-03-10", loc)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  stored := normalizeReportDate(parsed)
+  if stored.Format("2006-01-02") != "2026-03-10" {
+    t.Fatalf("expected parsed date to store as 2026-03-10, got %s", stored)
+  }
+  // Dates read back from the database are UTC midnight; normalizing them
+  // again must not move them to the previous day.
+  if again := normalizeReportDate(stored); !again.Equal(stored) {
+    t.Fatalf("expected normalization to be idempotent, got %s", again)
+  }
+  start, end, err := monthRange(2026, time.March)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if normalizeReportDate(start).Format("2006-01-02") != "2026-03-01" || normalizeReportDate(end).Format("2006-01-02") != "2026-03-31" {
+    t.Fatalf("unexpected month range %s..%s", start, end)
+  }
+}
+
+func TestValidateCustomRangeLimits(t *testing.T) {
+  const maxDays = 1095
+
+  now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+  day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+  if err := validateCustomRange(day(2023, 6, 16), day(2026, 6, 14), now, time.UTC, maxDays); err != nil {
+    t.Fatalf("expected 1095-day range to pass, got %v", err)
+  }
+  if err := validateCustomRange(day(2023, 6, 15), day(2026, 6, 14), now, time.UTC, maxDays); !errors.Is(err, ErrRangeTooLarge) {
+    t.Fatalf("expected ErrRangeTooLarge, got %v", err)
+  }
+  if err := validateCustomRange(day(1976, 1, 1), day(2026, 1, 1), now, time.UTC, maxDays); !errors.Is(err, ErrRangeTooLarge) {
+    t.Fatalf("expected 50-year range to be rejected, got %v", err)
+  }
+  if err := validateCustomRange(day(2026, 6, 1), day(2026, 6, 16), now, time.UTC, maxDays); err != nil {
+    t.Fatalf("expected tomorrow to be allowed, got %v", err)
+  }
+  if err := validateCustomRange(day(2026, 6, 1), day(2030, 1, 1), now, time.UTC, maxDays); !errors.Is(err, ErrRangeInFuture) {
+    t.Fatalf("expected ErrRangeInFuture, got %v", err)
+  }
+  if err := validateCustomRange(day(2026, 6, 2), day(2026, 6, 1), now, time.UTC, maxDays); !errors.Is(err, ErrInvalidRange) {
+    t.Fatalf("expected ErrInvalidRange, got %v", err)
+  }
+
+  // 01:00 UTC on June 16 is still June 15 at -3h, so June 17 is two days out
+  // there even though it is tomorrow in UTC.
+  early := time.Date(2026, 6, 16, 1, 0, 0, 0, time.UTC)
+  brt := time.FixedZone("BRT", -3*60*60)
+  if err := validateCustomRange(day(2026, 6, 1), day(2026, 6, 17), early, time.UTC, maxDays); err != nil {
+    t.Fatalf("expected tomorrow in UTC to be allowed, got %v", err)
+  }
+  if err := validateCustomRange(day(2026, 6, 1), day(2026, 6, 17), early, brt, maxDays); !errors.Is(err, ErrRangeInFuture) {
+    t.Fatalf("expected the report zone's today to be used, got %v", err)
+  }
+}
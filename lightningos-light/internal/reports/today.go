@@ -0,0 +1,113 @@
+package reports
+
+import (
+  "sync"
+  "time"
+)
+
+// TodayAccumulator keeps running totals for the current UTC day from events
+// seen by this process. Events are deduplicated by key because the forwarding
+// poller can deliver the same event twice, and events older than since are
+// ignored: those belong to the row Flush persisted before a restart.
+type TodayAccumulator struct {
+  mu sync.Mutex
+  since time.Time
+  day time.Time
+  acc *Accumulator
+  seen map[string]struct{}
+}
+
+func NewTodayAccumulator(since time.Time) *TodayAccumulator {
+  return &TodayAccumulator{
+    since: since.UTC(),
+    day: utcDay(since),
+    acc: NewAccumulator(),
+    seen: map[string]struct{}{},
+  }
+}
+
+// Record adds event if it falls on the current UTC day and was not seen yet.
+// An event from a later day rolls the accumulator over.
+func (t *TodayAccumulator) Record(event Event) bool {
+  if event.OccurredAt.Before(t.since) {
+    return false
+  }
+  day := utcDay(event.OccurredAt)
+  t.mu.Lock()
+  defer t.mu.Unlock()
+  t.rollLocked(day)
+  if day.Before(t.day) {
+    return false
+  }
+  if _, ok := t.seen[event.Key]; ok {
+    return false
+  }
+  switch event.Type {
+  case EventForward:
+    t.acc.AddForward(event.FeeMsat, event.AmountMsat)
+  case EventRebalance:
+    t.acc.AddRebalance(event.FeeMsat)
+  default:
+    return false
+  }
+  t.seen[event.Key] = struct{}{}
+  return true
+}
+
+// Snapshot returns the UTC day for now, the time live counting started on that
+// day, and the totals so far.
+func (t *TodayAccumulator) Snapshot(now time.Time) (day time.Time, since time.Time, metrics Metrics) {
+  t.mu.Lock()
+  defer t.mu.Unlock()
+  t.rollLocked(utcDay(now))
+  since = t.since
+  if since.Before(t.day) {
+    since = t.day
+  }
+  return t.day, since, t.acc.Snapshot()
+}
+
+func (t *TodayAccumulator) rollLocked(day time.Time) {
+  if !day.After(t.day) {
+    return
+  }
+  t.day = day
+  t.acc = NewAccumulator()
+  t.seen = map[string]struct{}{}
+}
+
+func utcDay(value time.Time) time.Time {
+  value = value.UTC()
+  return time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+var liveToday = NewTodayAccumulator(time.Now())
+
+// RecordToday feeds the process-wide accumulator behind Service.Today.
+func RecordToday(event Event) {
+  liveToday.Record(event)
+}
+
+// Today is the provisional view of the current UTC day.
+type Today struct {
+  Date time.Time
+  LiveSince time.Time
+  Persisted bool
+  Metrics Metrics
+}
+
+func mergeTodayMetrics(stored, live Metrics) Metrics {
+  fillMsatFromSat(&stored)
+  merged := metricsFromMsat(
+    stored.ForwardFeeRevenueMsat+live.ForwardFeeRevenueMsat,
+    stored.RebalanceFeeCostMsat+live.RebalanceFeeCostMsat,
+    stored.RoutedVolumeMsat+live.RoutedVolumeMsat,
+    stored.ForwardCount+live.ForwardCount,
+    stored.RebalanceCount+live.RebalanceCount,
+  )
+  merged.OnchainBalanceSat = stored.OnchainBalanceSat
+  merged.LightningBalanceSat = stored.LightningBalanceSat
+  merged.TotalBalanceSat = stored.TotalBalanceSat
+  fillOnchainRatio(&merged)
+  return merged
+}
@@ -0,0 +1,100 @@
+package reports
+
+import (
+  "context"
+  "testing"
+  "time"
+)
+
+func TestTodayAccumulator(t *testing.T) {
+  start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+  acc := NewTodayAccumulator(start)
+
+  if acc.Record(Event{Key: "forward:old", OccurredAt: start.Add(-time.Minute), Type: EventForward, FeeMsat: 9000}) {
+    t.Fatalf("events before the start belong to the persisted row")
+  }
+  if !acc.Record(Event{Key: "forward:1", OccurredAt: start.Add(time.Hour), Type: EventForward, FeeMsat: 1500, AmountMsat: 2000000}) {
+    t.Fatalf("expected forward to be recorded")
+  }
+  if acc.Record(Event{Key: "forward:1", OccurredAt: start.Add(time.Hour), Type: EventForward, FeeMsat: 1500, AmountMsat: 2000000}) {
+    t.Fatalf("expected duplicate key to be ignored")
+  }
+  acc.Record(Event{Key: "payment:a", OccurredAt: start.Add(2 * time.Hour), Type: EventRebalance, FeeMsat: 500})
+
+  day, since, metrics := acc.Snapshot(start.Add(3 * time.Hour))
+  if !day.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) || !since.Equal(start) {
+    t.Fatalf("unexpected day/since: %v %v", day, since)
+  }
+  if metrics.ForwardCount != 1 || metrics.ForwardFeeRevenueMsat != 1500 || metrics.NetRoutingProfitMsat != 1000 {
+    t.Fatalf("unexpected metrics: %+v", metrics)
+  }
+
+  day, since, metrics = acc.Snapshot(time.Date(2026, 3, 3, 0, 5, 0, 0, time.UTC))
+  if day.Day() != 3 || !since.Equal(day) || metrics.ForwardCount != 0 {
+    t.Fatalf("expected rollover at UTC midnight, got %v %v %+v", day, since, metrics)
+  }
+  if acc.Record(Event{Key: "forward:2", OccurredAt: start.Add(4 * time.Hour), Type: EventForward, FeeMsat: 100}) {
+    t.Fatalf("expected events from a closed day to be ignored")
+  }
+}
+
+func TestMergeTodayMetrics(t *testing.T) {
+  total := int64(1000)
+  onchain := int64(250)
+  stored := Metrics{ForwardFeeRevenueSat: 2, ForwardCount: 1, TotalBalanceSat: &total, OnchainBalanceSat: &onchain}
+  live := metricsFromMsat(1500, 700, 3000000, 2, 1)
+
+  merged := mergeTodayMetrics(stored, live)
+  if merged.ForwardFeeRevenueMsat != 3500 || merged.ForwardFeeRevenueSat != 3 || merged.NetRoutingProfitMsat != 2800 {
+    t.Fatalf("unexpected merged amounts: %+v", merged)
+  }
+  if merged.ForwardCount != 3 || merged.RebalanceCount != 1 || merged.RoutedVolumeMsat != 3000000 {
+    t.Fatalf("unexpected merged counts: %+v", merged)
+  }
+  if merged.OnchainRatio == nil || *merged.OnchainRatio != 0.25 {
+    t.Fatalf("expected balances to carry over from the stored row, got %+v", merged.OnchainRatio)
+  }
+}
+
+type todayStore struct {
+  Store
+  fetched int
+}
+
+func (s *todayStore) FetchRange(ctx context.Context, startDate, endDate time.Time) ([]Row, error) {
+  s.fetched++
+  return []Row{{ReportDate: startDate, Metrics: Metrics{ForwardFeeRevenueSat: 5, ForwardCount: 2}}}, nil
+}
+
+func TestServiceTodayMergesOnlyUTCDays(t *testing.T) {
+  now := time.Now()
+
+  utc := DefaultOptions()
+  utc.Timezone = time.UTC
+  store := &todayStore{}
+  today, err := NewService(store, nil, nil, utc).Today(context.Background(), now)
+  if err != nil || !today.Persisted || store.fetched != 1 || today.Metrics.ForwardCount < 2 {
+    t.Fatalf("expected the stored UTC row to be merged, got %+v %v", today, err)
+  }
+
+  brt := DefaultOptions()
+  brt.Timezone = time.FixedZone("BRT", -3*60*60)
+  store = &todayStore{}
+  today, err = NewService(store, nil, nil, brt).Today(context.Background(), now)
+  if err != nil || today.Persisted || store.fetched != 0 {
+    t.Fatalf("expected no merge with a report-zone row, got %+v %v", today, err)
+  }
+}
+
+func TestReportDayIsUTCDay(t *testing.T) {
+  day := time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC)
+  if !reportDayIsUTCDay(day, time.UTC) {
+    t.Fatalf("expected UTC to match")
+  }
+  if reportDayIsUTCDay(day, time.FixedZone("BRT", -3*60*60)) {
+    t.Fatalf("expected a -3h zone not to match")
+  }
+  if !reportDayIsUTCDay(day, time.FixedZone("GMT", 0)) {
+    t.Fatalf("expected another zero-offset zone to match")
+  }
+}
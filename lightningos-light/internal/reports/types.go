@@ -2,6 +2,10 @@ package reports
 
 import "time"
 
+// SchemaVersion is bumped whenever reports_daily columns change
+// (v2 added the msat columns).
+const SchemaVersion = 2
+
 type Metrics struct {
   ForwardFeeRevenueSat int64
   ForwardFeeRevenueMsat int64
@@ -16,6 +20,51 @@ type Metrics struct {
   OnchainBalanceSat *int64
   LightningBalanceSat *int64
   TotalBalanceSat *int64
+  OnchainRatio *float64
+}
+
+// Add returns m + other field by field, msat included. A balance is only set
+// when both sides have it; OnchainRatio is recomputed from the resulting
+// balances. Sat fields are summed as stored rather than re-derived from msat.
+func (m Metrics) Add(other Metrics) Metrics {
+  return combineMetrics(m, other, 1)
+}
+
+// Sub returns m - other with the same rules as Add, so a balance is nil unless
+// both rows have one (nil minus a value is nil, not the negated value).
+// OnchainRatio is left nil since a ratio of two deltas means nothing.
+func (m Metrics) Sub(other Metrics) Metrics {
+  return combineMetrics(m, other, -1)
+}
+
+func combineMetrics(a, b Metrics, sign int64) Metrics {
+  out := Metrics{
+    ForwardFeeRevenueSat: a.ForwardFeeRevenueSat + sign*b.ForwardFeeRevenueSat,
+    ForwardFeeRevenueMsat: a.ForwardFeeRevenueMsat + sign*b.ForwardFeeRevenueMsat,
+    RebalanceFeeCostSat: a.RebalanceFeeCostSat + sign*b.RebalanceFeeCostSat,
+    RebalanceFeeCostMsat: a.RebalanceFeeCostMsat + sign*b.RebalanceFeeCostMsat,
+    NetRoutingProfitSat: a.NetRoutingProfitSat + sign*b.NetRoutingProfitSat,
+    NetRoutingProfitMsat: a.NetRoutingProfitMsat + sign*b.NetRoutingProfitMsat,
+    ForwardCount: a.ForwardCount + sign*b.ForwardCount,
+    RebalanceCount: a.RebalanceCount + sign*b.RebalanceCount,
+    RoutedVolumeSat: a.RoutedVolumeSat + sign*b.RoutedVolumeSat,
+    RoutedVolumeMsat: a.RoutedVolumeMsat + sign*b.RoutedVolumeMsat,
+    OnchainBalanceSat: combineBalance(a.OnchainBalanceSat, b.OnchainBalanceSat, sign),
+    LightningBalanceSat: combineBalance(a.LightningBalanceSat, b.LightningBalanceSat, sign),
+    TotalBalanceSat: combineBalance(a.TotalBalanceSat, b.TotalBalanceSat, sign),
+  }
+  if sign > 0 {
+    fillOnchainRatio(&out)
+  }
+  return out
+}
+
+func combineBalance(a, b *int64, sign int64) *int64 {
+  if a == nil || b == nil {
+    return nil
+  }
+  value := *a + sign*(*b)
+  return &value
 }
 
 type Row struct {
@@ -24,6 +73,44 @@ type Row struct {
 }
 
 type Summary struct {
+  Days int64
+  HasData bool
+  Totals Metrics
+  Averages Metrics
+  Max Metrics
+  Median Metrics
+  EffectivePpm float64
+  RebalanceCostRatio *float64
+  Partial bool
+}
+
+type Granularity int
+
+const (
+  Daily Granularity = iota
+  Weekly
+  Monthly
+  Quarterly
+  Yearly
+)
+
+// RoundingMode controls how averages are divided down to whole units.
+type RoundingMode int
+
+const (
+  RoundTruncate RoundingMode = iota
+  RoundHalfUp
+)
+
+type SortOrder int
+
+const (
+  Ascending SortOrder = iota
+  Descending
+)
+
+type RollupBucket struct {
+  BucketStart time.Time
   Days int64
   Totals Metrics
   Averages Metrics
@@ -0,0 +1,59 @@
+package reports
+
+import "testing"
+
+func TestMetricsAddSub(t *testing.T) {
+  int64p := func(v int64) *int64 { return &v }
+  today := Metrics{
+    ForwardFeeRevenueSat: 5,
+    ForwardFeeRevenueMsat: 5500,
+    RebalanceFeeCostMsat: 1000,
+    RebalanceFeeCostSat: 1,
+    NetRoutingProfitSat: 4,
+    NetRoutingProfitMsat: 4500,
+    ForwardCount: 3,
+    RoutedVolumeMsat: 9000,
+    RoutedVolumeSat: 9,
+    OnchainBalanceSat: int64p(300),
+    TotalBalanceSat: int64p(1000),
+  }
+  yesterday := Metrics{
+    ForwardFeeRevenueSat: 7,
+    ForwardFeeRevenueMsat: 7250,
+    NetRoutingProfitSat: 7,
+    NetRoutingProfitMsat: 7250,
+    ForwardCount: 4,
+    RebalanceCount: 1,
+    OnchainBalanceSat: int64p(100),
+    LightningBalanceSat: int64p(600),
+    TotalBalanceSat: int64p(700),
+  }
+
+  diff := today.Sub(yesterday)
+  if diff.ForwardFeeRevenueMsat != -1750 || diff.ForwardFeeRevenueSat != -2 || diff.NetRoutingProfitMsat != -2750 || diff.RebalanceFeeCostMsat != 1000 {
+    t.Fatalf("unexpected amount delta: %+v", diff)
+  }
+  if diff.ForwardCount != -1 || diff.RebalanceCount != -1 || diff.RoutedVolumeMsat != 9000 {
+    t.Fatalf("unexpected count delta: %+v", diff)
+  }
+  if diff.OnchainBalanceSat == nil || *diff.OnchainBalanceSat != 200 || *diff.TotalBalanceSat != 300 {
+    t.Fatalf("expected balance delta when both sides are set, got %+v", diff)
+  }
+  if diff.LightningBalanceSat != nil {
+    t.Fatalf("expected nil lightning delta when one side is missing")
+  }
+  if diff.OnchainRatio != nil {
+    t.Fatalf("expected no ratio on a delta")
+  }
+
+  sum := today.Add(yesterday)
+  if sum.ForwardFeeRevenueMsat != 12750 || sum.ForwardCount != 7 || *sum.TotalBalanceSat != 1700 {
+    t.Fatalf("unexpected sum: %+v", sum)
+  }
+  if sum.OnchainRatio == nil || *sum.OnchainRatio != 400.0/1700.0 {
+    t.Fatalf("expected ratio from the summed balances, got %v", sum.OnchainRatio)
+  }
+  if back := sum.Sub(yesterday); back.ForwardFeeRevenueMsat != today.ForwardFeeRevenueMsat || back.NetRoutingProfitSat != today.NetRoutingProfitSat {
+    t.Fatalf("expected Sub to undo Add, got %+v", back)
+  }
+}
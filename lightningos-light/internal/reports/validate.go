@@ -0,0 +1,53 @@
+package reports
+
+import "fmt"
+
+func (r Row) Validate() error {
+  m := r.Metrics
+  date := r.ReportDate.Format("2006-01-02")
+
+  hasMsat := m.ForwardFeeRevenueMsat != 0 || m.RebalanceFeeCostMsat != 0 || m.NetRoutingProfitMsat != 0
+  if !hasMsat {
+    if want := m.ForwardFeeRevenueSat - m.RebalanceFeeCostSat; m.NetRoutingProfitSat != want {
+      return fmt.Errorf("report %s: net_routing_profit_sats %d != revenue %d - cost %d", date, m.NetRoutingProfitSat, m.ForwardFeeRevenueSat, m.RebalanceFeeCostSat)
+    }
+    return nil
+  }
+
+  if want := m.ForwardFeeRevenueMsat - m.RebalanceFeeCostMsat; m.NetRoutingProfitMsat != want {
+    return fmt.Errorf("report %s: net_routing_profit_msat %d != revenue %d - cost %d", date, m.NetRoutingProfitMsat, m.ForwardFeeRevenueMsat, m.RebalanceFeeCostMsat)
+  }
+  // Sat columns are truncated from msat independently, so they must match
+  // msat/1000 rather than satisfy the subtraction themselves.
+  checks := []struct {
+    name string
+    sat int64
+    msat int64
+  }{
+    {"forward_fee_revenue", m.ForwardFeeRevenueSat, m.ForwardFeeRevenueMsat},
+    {"rebalance_fee_cost", m.RebalanceFeeCostSat, m.RebalanceFeeCostMsat},
+    {"net_routing_profit", m.NetRoutingProfitSat, m.NetRoutingProfitMsat},
+  }
+  for _, check := range checks {
+    if check.sat != check.msat/1000 {
+      return fmt.Errorf("report %s: %s_sats %d does not match %s_msat %d", date, check.name, check.sat, check.name, check.msat)
+    }
+  }
+  return nil
+}
+
+func validateForWrite(row Row, strict bool) error {
+  if !strict {
+    return nil
+  }
+  return row.Validate()
+}
+
+func validateBatchForWrite(rows []Row, strict bool) error {
+  for _, row := range rows {
+    if err := validateForWrite(row, strict); err != nil {
+      return err
+    }
+  }
+  return nil
+}
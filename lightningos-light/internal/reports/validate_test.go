@@ -0,0 +1,94 @@
+package reports
+
+import (
+  "context"
+  "testing"
+  "time"
+)
+
+func TestRowValidate(t *testing.T) {
+  date := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+  cases := []struct {
+    name string
+    metrics Metrics
+    ok bool
+  }{
+    {
+      name: "consistent msat with truncated sats",
+      metrics: Metrics{
+        ForwardFeeRevenueSat: 1, ForwardFeeRevenueMsat: 1500,
+        RebalanceFeeCostSat: 0, RebalanceFeeCostMsat: 600,
+        NetRoutingProfitSat: 0, NetRoutingProfitMsat: 900,
+      },
+      ok: true,
+    },
+    {
+      name: "msat mismatch",
+      metrics: Metrics{
+        ForwardFeeRevenueSat: 2, ForwardFeeRevenueMsat: 2000,
+        RebalanceFeeCostSat: 1, RebalanceFeeCostMsat: 1000,
+        NetRoutingProfitSat: 1, NetRoutingProfitMsat: 1001,
+      },
+      ok: false,
+    },
+    {
+      name: "sat out of sync with msat",
+      metrics: Metrics{
+        ForwardFeeRevenueSat: 3, ForwardFeeRevenueMsat: 2000,
+        RebalanceFeeCostSat: 1, RebalanceFeeCostMsat: 1000,
+        NetRoutingProfitSat: 1, NetRoutingProfitMsat: 1000,
+      },
+      ok: false,
+    },
+    {
+      name: "sat only consistent",
+      metrics: Metrics{ForwardFeeRevenueSat: 10, RebalanceFeeCostSat: 4, NetRoutingProfitSat: 6},
+      ok: true,
+    },
+    {
+      name: "sat only mismatch",
+      metrics: Metrics{ForwardFeeRevenueSat: 10, RebalanceFeeCostSat: 4, NetRoutingProfitSat: 7},
+      ok: false,
+    },
+    {
+      name: "negative net",
+      metrics: Metrics{
+        ForwardFeeRevenueSat: 0, ForwardFeeRevenueMsat: 200,
+        RebalanceFeeCostSat: 1, RebalanceFeeCostMsat: 1500,
+        NetRoutingProfitSat: -1, NetRoutingProfitMsat: -1300,
+      },
+      ok: true,
+    },
+  }
+  for _, tc := range cases {
+    err := Row{ReportDate: date, Metrics: tc.metrics}.Validate()
+    if tc.ok && err != nil {
+      t.Fatalf("%s: unexpected error: %v", tc.name, err)
+    }
+    if !tc.ok && err == nil {
+      t.Fatalf("%s: expected error", tc.name)
+    }
+  }
+}
+
+func TestValidateForWriteRespectsStrictFlag(t *testing.T) {
+  bad := Row{Metrics: Metrics{ForwardFeeRevenueSat: 10, NetRoutingProfitSat: 1}}
+  if err := validateForWrite(bad, false); err != nil {
+    t.Fatalf("expected no validation when strict is off: %v", err)
+  }
+  if err := validateForWrite(bad, true); err == nil {
+    t.Fatalf("expected validation error when strict is on")
+  }
+}
+
+func TestPgStoreStrictValidationOption(t *testing.T) {
+  bad := Row{Metrics: Metrics{ForwardFeeRevenueSat: 10, NetRoutingProfitSat: 1}}
+  if err := NewPgStore(nil, DefaultOptions()).UpsertDaily(context.Background(), bad); err != nil {
+    t.Fatalf("expected default store to skip validation: %v", err)
+  }
+  opts := DefaultOptions()
+  opts.StrictValidation = true
+  if err := NewPgStore(nil, opts).UpsertDaily(context.Background(), bad); err == nil {
+    t.Fatalf("expected strict store to reject the row")
+  }
+}
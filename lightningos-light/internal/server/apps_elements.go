@@ -10,6 +10,7 @@ import (
   "runtime"
   "strconv"
   "strings"
+  "time"
 
   "lightningos-light/internal/config"
 )
@@ -17,10 +18,11 @@ import (
 const (
   elementsAppID = "elements"
   elementsVersion = "23.3.1"
-  elementsUser = "losop"
-  elementsServiceName = "lightningos-elements"
+  elementsUser = config.DefaultElementsUser
   elementsRPCPort = 7041
   elementsFallbackFee = "0.00001"
+  elementsDefaultRPCWaitSec = 5
+  elementsRPCTimeoutBuffer = time.Second
 )
 
 var elementsAssetDirs = []string{
@@ -40,6 +42,8 @@ type elementsPaths struct {
   VersionPath string
   RPCCredsPath string
   MainchainSourcePath string
+  RPCWaitTimeoutSec int
+  User string
 }
 
 type elementsApp struct {
@@ -75,12 +79,12 @@ func (a elementsApp) Definition() appDefinition {
 func (a elementsApp) Info(ctx context.Context) (appInfo, error) {
   def := a.Definition()
   info := newAppInfo(def)
-  paths := elementsAppPaths()
+  paths := a.server.elementsPaths()
   if !fileExists(paths.ElementsdPath) {
     return info, nil
   }
   info.Installed = true
-  status, err := elementsServiceStatus(ctx)
+  status, err := elementsServiceStatus(ctx, a.server.elementsServiceUnit())
   if err != nil {
     info.Status = "unknown"
     return info, err
@@ -118,15 +122,27 @@ func elementsAppPaths() elementsPaths {
     ElementsdPath: filepath.Join(binDir, "elementsd"),
     ElementsCliPath: filepath.Join(binDir, "elements-cli"),
     ConfigPath: filepath.Join(dataDir, "elements.conf"),
-    ServicePath: filepath.Join("/etc/systemd/system", elementsServiceName+".service"),
+    ServicePath: filepath.Join("/etc/systemd/system", config.DefaultElementsServiceUnit),
     VersionPath: filepath.Join(root, "VERSION"),
     RPCCredsPath: filepath.Join(appDataDir, "rpc.env"),
     MainchainSourcePath: filepath.Join(appDataDir, "mainchain_source"),
   }
 }
 
-func (s *Server) installElements(ctx context.Context) error {
+// elementsPaths is elementsAppPaths with the configured elements settings
+// applied: the unit file named after elements.service_unit, the elements-cli
+// user and the RPC wait timeout. Handlers use it instead of elementsAppPaths
+// so none of them runs elements-cli with the defaults.
+func (s *Server) elementsPaths() elementsPaths {
   paths := elementsAppPaths()
+  paths.ServicePath = filepath.Join("/etc/systemd/system", s.elementsServiceUnit())
+  paths.RPCWaitTimeoutSec = s.elementsRPCWaitTimeoutSec()
+  paths.User = s.elementsUser()
+  return paths
+}
+
+func (s *Server) installElements(ctx context.Context) error {
+  paths := s.elementsPaths()
   if err := os.MkdirAll(paths.Root, 0750); err != nil {
     return fmt.Errorf("failed to create app directory: %w", err)
   }
@@ -145,14 +161,14 @@ func (s *Server) installElements(ctx context.Context) error {
   if err := ensureElementsService(ctx, paths); err != nil {
     return err
   }
-  if _, err := runSystemd(ctx, "systemctl", "enable", "--now", elementsServiceName); err != nil {
+  if _, err := runSystemd(ctx, "systemctl", "enable", "--now", s.elementsServiceUnit()); err != nil {
     return err
   }
   return nil
 }
 
 func (s *Server) startElements(ctx context.Context) error {
-  paths := elementsAppPaths()
+  paths := s.elementsPaths()
   if !fileExists(paths.ElementsdPath) {
     return errors.New("Elements is not installed")
   }
@@ -165,27 +181,27 @@ func (s *Server) startElements(ctx context.Context) error {
   if err := ensureElementsService(ctx, paths); err != nil {
     return err
   }
-  if _, err := runSystemd(ctx, "systemctl", "restart", elementsServiceName); err != nil {
+  if _, err := runSystemd(ctx, "systemctl", "restart", s.elementsServiceUnit()); err != nil {
     return err
   }
   return nil
 }
 
 func (s *Server) stopElements(ctx context.Context) error {
-  paths := elementsAppPaths()
+  paths := s.elementsPaths()
   if !fileExists(paths.ElementsdPath) {
     return errors.New("Elements is not installed")
   }
-  if _, err := runSystemd(ctx, "systemctl", "stop", elementsServiceName); err != nil {
+  if _, err := runSystemd(ctx, "systemctl", "stop", s.elementsServiceUnit()); err != nil {
     return err
   }
   return nil
 }
 
 func (s *Server) uninstallElements(ctx context.Context) error {
-	paths := elementsAppPaths()
+	paths := s.elementsPaths()
 	if fileExists(paths.ServicePath) {
-		_, _ = runSystemd(ctx, "systemctl", "disable", "--now", elementsServiceName)
+		_, _ = runSystemd(ctx, "systemctl", "disable", "--now", s.elementsServiceUnit())
 		_, _ = runSystemd(ctx, "systemctl", "daemon-reload")
 		_, _ = runSystemd(ctx, "/bin/sh", "-c", "rm -f "+paths.ServicePath)
 	}
@@ -507,7 +523,7 @@ func writeElementsConfig(ctx context.Context, paths elementsPaths, content strin
 }
 
 func readElementsConfig(ctx context.Context, paths elementsPaths) (string, error) {
-  out, err := runSystemd(ctx, "/bin/sh", "-c", "cat "+paths.ConfigPath)
+  out, err := runSystemdRetry(ctx, systemdStatusAttempts, "/bin/sh", "-c", "cat "+paths.ConfigPath)
   if err != nil {
     msg := strings.ToLower(out)
     if strings.Contains(msg, "no such file") || strings.Contains(strings.ToLower(err.Error()), "no such file") {
@@ -732,25 +748,10 @@ func parseElementsMainchainConfig(raw string) (string, int) {
   return host, port
 }
 
-func elementsServiceStatus(ctx context.Context) (string, error) {
-  out, err := runSystemd(ctx, "systemctl", "is-active", elementsServiceName)
-  if err != nil {
-    state := strings.TrimSpace(out)
-    if state == "activating" {
-      return "running", nil
-    }
-    if state == "inactive" || state == "failed" || state == "deactivating" {
-      return "stopped", nil
-    }
-    return "unknown", err
-  }
-  state := strings.TrimSpace(out)
-  switch state {
-  case "active", "activating":
-    return "running", nil
-  case "inactive", "failed", "deactivating":
-    return "stopped", nil
-  default:
-    return "unknown", nil
+func elementsServiceStatus(ctx context.Context, unit string) (string, error) {
+  status, err := systemdUnitStatus(ctx, unit)
+  if status == systemdStatusFailed {
+    return systemdStatusStopped, nil
   }
+  return status, err
 }
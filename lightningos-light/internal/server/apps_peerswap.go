@@ -112,7 +112,7 @@ func peerswapAppPaths() peerswapPaths {
 }
 
 func (s *Server) installPeerswap(ctx context.Context) error {
-  if err := ensureElementsReady(ctx); err != nil {
+  if err := ensureElementsReady(ctx, s.elementsServiceUnit()); err != nil {
     return err
   }
   paths := peerswapAppPaths()
@@ -131,7 +131,7 @@ func (s *Server) installPeerswap(ctx context.Context) error {
   if err := ensurePeerswapConfig(ctx, paths); err != nil {
     return err
   }
-  if err := ensurePeerswapServices(ctx, paths); err != nil {
+  if err := ensurePeerswapServices(ctx, paths, s.elementsServiceUnit()); err != nil {
     return err
   }
   if _, err := runSystemd(ctx, "systemctl", "enable", "--now", peerswapServiceName); err != nil {
@@ -144,7 +144,7 @@ func (s *Server) installPeerswap(ctx context.Context) error {
 }
 
 func (s *Server) startPeerswap(ctx context.Context) error {
-  if err := ensureElementsReady(ctx); err != nil {
+  if err := ensureElementsReady(ctx, s.elementsServiceUnit()); err != nil {
     return err
   }
   paths := peerswapAppPaths()
@@ -157,7 +157,7 @@ func (s *Server) startPeerswap(ctx context.Context) error {
   if err := ensurePeerswapConfig(ctx, paths); err != nil {
     return err
   }
-  if err := ensurePeerswapServices(ctx, paths); err != nil {
+  if err := ensurePeerswapServices(ctx, paths, s.elementsServiceUnit()); err != nil {
     return err
   }
   if _, err := runSystemd(ctx, "systemctl", "restart", peerswapServiceName); err != nil {
@@ -198,12 +198,12 @@ func (s *Server) uninstallPeerswap(ctx context.Context) error {
   return nil
 }
 
-func ensureElementsReady(ctx context.Context) error {
+func ensureElementsReady(ctx context.Context, unit string) error {
   paths := elementsAppPaths()
   if !fileExists(paths.ElementsdPath) {
     return errors.New("Elements is required before installing Peerswap")
   }
-  status, err := elementsServiceStatus(ctx)
+  status, err := elementsServiceStatus(ctx, unit)
   if err != nil {
     return fmt.Errorf("failed to check Elements status: %w", err)
   }
@@ -459,7 +459,7 @@ func updatePeerswapConfig(raw string, values peerswapConfigValues) string {
 }
 
 func readPeerswapConfig(ctx context.Context, paths peerswapPaths) (string, error) {
-  out, err := runSystemd(ctx, "/bin/sh", "-c", "cat "+paths.ConfigPath)
+  out, err := runSystemdRetry(ctx, systemdStatusAttempts, "/bin/sh", "-c", "cat "+paths.ConfigPath)
   if err != nil {
     msg := strings.ToLower(out)
     if strings.Contains(msg, "no such file") || strings.Contains(strings.ToLower(err.Error()), "no such file") {
@@ -485,8 +485,8 @@ func writePeerswapConfig(ctx context.Context, paths peerswapPaths, content strin
   return nil
 }
 
-func ensurePeerswapServices(ctx context.Context, paths peerswapPaths) error {
-  svc := peerswapServiceContents(paths)
+func ensurePeerswapServices(ctx context.Context, paths peerswapPaths, elementsUnit string) error {
+  svc := peerswapServiceContents(paths, elementsUnit)
   if existing, err := os.ReadFile(paths.ServicePath); err == nil && string(existing) == svc {
     // no-op
   } else {
@@ -526,7 +526,7 @@ func ensurePeerswapServices(ctx context.Context, paths peerswapPaths) error {
   return nil
 }
 
-func peerswapServiceContents(paths peerswapPaths) string {
+func peerswapServiceContents(paths peerswapPaths, elementsUnit string) string {
   return fmt.Sprintf(`[Unit]
 Description=LightningOS Peerswap daemon
 After=network-online.target %s
@@ -545,7 +545,7 @@ RestartSec=5
 
 [Install]
 WantedBy=multi-user.target
-`, elementsServiceName+".service", peerswapUser, peerswapUser, peerswapUser, peerswapUser, filepath.Join(paths.BinDir, "peerswapd"))
+`, elementsUnit, peerswapUser, peerswapUser, peerswapUser, peerswapUser, filepath.Join(paths.BinDir, "peerswapd"))
 }
 
 func pswebServiceContents(paths peerswapPaths) string {
@@ -589,7 +589,7 @@ func peerswapServiceStatus(ctx context.Context) (string, error) {
 }
 
 func serviceActiveState(ctx context.Context, name string) (string, error) {
-  out, err := runSystemd(ctx, "systemctl", "is-active", name)
+  out, err := runSystemdRetry(ctx, systemdStatusAttempts, "systemctl", "is-active", name)
   if err != nil {
     state := strings.TrimSpace(out)
     if state == "activating" {
@@ -0,0 +1,154 @@
+package server
+
+import (
+  "context"
+  "log"
+  "net"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/audit"
+
+  "github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+  controlAuditDefaultLimit = 50
+  controlAuditMaxLimit = 500
+  controlAuditResultOK = "ok"
+  controlAuditResultFailed = "failed"
+)
+
+// controlAuditSink receives one entry per control action. The database sink is
+// used when Postgres is available, otherwise entries go to the server log.
+type controlAuditSink interface {
+  Record(ctx context.Context, entry audit.Entry) error
+}
+
+type dbControlAuditSink struct {
+  db *pgxpool.Pool
+}
+
+func (d dbControlAuditSink) Record(ctx context.Context, entry audit.Entry) error {
+  return audit.Insert(ctx, d.db, entry)
+}
+
+type logControlAuditSink struct {
+  logger *log.Logger
+}
+
+func (l logControlAuditSink) Record(ctx context.Context, entry audit.Entry) error {
+  if l.logger == nil {
+    return nil
+  }
+  l.logger.Printf("audit: action=%s actor=%s result=%s at=%s", entry.Action, entry.Actor, entry.Result, entry.OccurredAt.UTC().Format(time.RFC3339))
+  return nil
+}
+
+func (s *Server) initControlAudit() {
+  if s.db == nil {
+    s.audit = logControlAuditSink{logger: s.logger}
+    return
+  }
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  err := audit.EnsureSchema(ctx, s.db)
+  cancel()
+  if err != nil {
+    s.logger.Printf("control audit: failed to init schema, logging only: %v", err)
+    s.audit = logControlAuditSink{logger: s.logger}
+    return
+  }
+  s.audit = dbControlAuditSink{db: s.db}
+}
+
+func (s *Server) recordControlAudit(r *http.Request, action string, result string) {
+  sink := s.audit
+  if sink == nil {
+    sink = logControlAuditSink{logger: s.logger}
+  }
+  entry := audit.Entry{
+    OccurredAt: time.Now(),
+    Action: action,
+    Actor: controlAuditActor(r),
+    Result: result,
+  }
+  ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+  defer cancel()
+  if err := sink.Record(ctx, entry); err != nil && s.logger != nil {
+    s.logger.Printf("control audit: record %s failed: %v", action, err)
+  }
+}
+
+// controlAuditActor is the verified terminal identity when the request
+// carries one, otherwise the client address. Unverified user headers are
+// never recorded.
+func controlAuditActor(r *http.Request) string {
+  if user := terminalRequestUser(r); user != "" {
+    return user
+  }
+  host, _, err := net.SplitHostPort(r.RemoteAddr)
+  if err != nil {
+    host = r.RemoteAddr
+  }
+  if host == "" {
+    return "anonymous"
+  }
+  return "addr:" + host
+}
+
+func controlAuditResult(status int) string {
+  if status >= 200 && status < 300 {
+    return controlAuditResultOK
+  }
+  return controlAuditResultFailed
+}
+
+func parseControlAuditPage(r *http.Request) (int64, int, bool) {
+  limit := controlAuditDefaultLimit
+  if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+    parsed, err := strconv.Atoi(raw)
+    if err != nil || parsed < 1 || parsed > controlAuditMaxLimit {
+      return 0, 0, false
+    }
+    limit = parsed
+  }
+  var before int64
+  if raw := strings.TrimSpace(r.URL.Query().Get("before")); raw != "" {
+    parsed, err := strconv.ParseInt(raw, 10, 64)
+    if err != nil || parsed < 1 {
+      return 0, 0, false
+    }
+    before = parsed
+  }
+  return before, limit, true
+}
+
+func (s *Server) handleControlAudit(w http.ResponseWriter, r *http.Request) {
+  if s.db == nil {
+    writeErrorCode(w, http.StatusServiceUnavailable, "control_audit_unavailable", "control audit unavailable")
+    return
+  }
+  before, limit, ok := parseControlAuditPage(r)
+  if !ok {
+    writeErrorCode(w, http.StatusBadRequest, "invalid_page", "limit must be 1-500 and before a positive id")
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+  items, err := audit.Fetch(ctx, s.db, before, limit)
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "control_audit_failed", "failed to load control audit")
+    return
+  }
+  if items == nil {
+    items = []audit.Entry{}
+  }
+  resp := map[string]any{"items": items}
+  if len(items) == limit {
+    resp["next_before"] = items[len(items)-1].ID
+  }
+  writeJSON(w, http.StatusOK, resp)
+}
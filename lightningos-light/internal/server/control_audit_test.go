@@ -0,0 +1,77 @@
+package server
+
+import (
+  "bytes"
+  "context"
+  "log"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+
+  "lightningos-light/internal/audit"
+)
+
+func TestLogControlAuditSinkWritesEntry(t *testing.T) {
+  var buf bytes.Buffer
+  sink := logControlAuditSink{logger: log.New(&buf, "", 0)}
+  entry := audit.Entry{
+    OccurredAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+    Action: "elements.restart",
+    Actor: "admin",
+    Result: controlAuditResultOK,
+  }
+  if err := sink.Record(context.Background(), entry); err != nil {
+    t.Fatalf("record: %v", err)
+  }
+  want := "audit: action=elements.restart actor=admin result=ok at=2026-03-01T12:00:00Z"
+  if got := strings.TrimSpace(buf.String()); got != want {
+    t.Fatalf("unexpected log line: %q", got)
+  }
+}
+
+func TestControlAuditActor(t *testing.T) {
+  t.Setenv("TERMINAL_CREDENTIAL", "admin:secret")
+  t.Setenv("TERMINAL_TRUSTED_PROXIES", "")
+
+  req := httptest.NewRequest("POST", "/api/elements/control", nil)
+  req.RemoteAddr = "192.0.2.10:51234"
+  if got := controlAuditActor(req); got != "addr:192.0.2.10" {
+    t.Fatalf("expected the client address, got %q", got)
+  }
+  req.Header.Set("X-Forwarded-User", "admin")
+  if got := controlAuditActor(req); got != "addr:192.0.2.10" {
+    t.Fatalf("expected a spoofed header to be ignored, got %q", got)
+  }
+  req.SetBasicAuth("admin", "wrong")
+  if got := controlAuditActor(req); got != "addr:192.0.2.10" {
+    t.Fatalf("expected a bad credential to be ignored, got %q", got)
+  }
+  req.SetBasicAuth("admin", "secret")
+  if got := controlAuditActor(req); got != "admin" {
+    t.Fatalf("expected admin, got %q", got)
+  }
+}
+
+func TestParseControlAuditPage(t *testing.T) {
+  cases := []struct {
+    query string
+    before int64
+    limit int
+    ok bool
+  }{
+    {"", 0, controlAuditDefaultLimit, true},
+    {"?limit=10&before=42", 42, 10, true},
+    {"?limit=0", 0, 0, false},
+    {"?limit=501", 0, 0, false},
+    {"?before=-1", 0, 0, false},
+    {"?before=abc", 0, 0, false},
+  }
+  for _, tc := range cases {
+    req := httptest.NewRequest("GET", "/api/audit/control"+tc.query, nil)
+    before, limit, ok := parseControlAuditPage(req)
+    if ok != tc.ok || before != tc.before || limit != tc.limit {
+      t.Fatalf("%q: got (%d, %d, %v)", tc.query, before, limit, ok)
+    }
+  }
+}
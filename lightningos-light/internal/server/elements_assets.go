@@ -0,0 +1,94 @@
+package server
+
+import (
+  "encoding/json"
+  "net/http"
+  "sort"
+  "strings"
+)
+
+type elementsAssetLabel struct {
+  Label string `json:"label"`
+  Asset string `json:"asset"`
+}
+
+type elementsIssuance struct {
+  TxID string `json:"txid"`
+  Vin int `json:"vin"`
+  Entropy string `json:"entropy"`
+  Asset string `json:"asset"`
+  AssetLabel string `json:"assetlabel,omitempty"`
+  Token string `json:"token"`
+  AssetAmount float64 `json:"assetamount"`
+  TokenAmount float64 `json:"tokenamount"`
+  IsReissuance bool `json:"isreissuance"`
+}
+
+type elementsAssetsResponse struct {
+  Labels []elementsAssetLabel `json:"labels"`
+  Issuances []elementsIssuance `json:"issuances"`
+}
+
+func (s *Server) handleElementsAssets(w http.ResponseWriter, r *http.Request) {
+  ctx, paths, cancel, ok := s.elementsRunningPaths(w, r)
+  if !ok {
+    return
+  }
+  defer cancel()
+
+  labelsOut, err := runElementsCLI(ctx, paths, "dumpassetlabels")
+  if err != nil {
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_rpc_failed", "Elements RPC unavailable")
+    return
+  }
+  labels, err := parseElementsAssetLabels(labelsOut)
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "elements_rpc_invalid_response", "failed to parse asset labels")
+    return
+  }
+
+  issuancesOut, err := runElementsCLI(ctx, paths, "listissuances")
+  if err != nil {
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_rpc_failed", "Elements RPC unavailable")
+    return
+  }
+  issuances, err := parseElementsIssuances(issuancesOut)
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "elements_rpc_invalid_response", "failed to parse issuances")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, elementsAssetsResponse{Labels: labels, Issuances: issuances})
+}
+
+func parseElementsAssetLabels(raw string) ([]elementsAssetLabel, error) {
+  labels := []elementsAssetLabel{}
+  if strings.TrimSpace(raw) == "" {
+    return labels, nil
+  }
+  var byLabel map[string]string
+  if err := json.Unmarshal([]byte(raw), &byLabel); err != nil {
+    return nil, err
+  }
+  for label, asset := range byLabel {
+    labels = append(labels, elementsAssetLabel{Label: label, Asset: asset})
+  }
+  sort.Slice(labels, func(i, j int) bool {
+    return labels[i].Label < labels[j].Label
+  })
+  return labels, nil
+}
+
+func parseElementsIssuances(raw string) ([]elementsIssuance, error) {
+  issuances := []elementsIssuance{}
+  if strings.TrimSpace(raw) == "" {
+    return issuances, nil
+  }
+  if err := json.Unmarshal([]byte(raw), &issuances); err != nil {
+    return nil, err
+  }
+  if issuances == nil {
+    issuances = []elementsIssuance{}
+  }
+  return issuances, nil
+}
@@ -0,0 +1,36 @@
+package server
+
+import "testing"
+
+func TestParseElementsAssetLabels(t *testing.T) {
+  labels, err := parseElementsAssetLabels(`{"bitcoin": "6f02", "USDt": "ce09"}`)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(labels) != 2 || labels[0].Label != "USDt" || labels[1].Asset != "6f02" {
+    t.Fatalf("unexpected labels: %+v", labels)
+  }
+  if _, err := parseElementsAssetLabels("not json"); err == nil {
+    t.Fatalf("expected parse error")
+  }
+}
+
+func TestParseElementsIssuances(t *testing.T) {
+  for _, raw := range []string{"", "[]", "null"} {
+    issuances, err := parseElementsIssuances(raw)
+    if err != nil {
+      t.Fatalf("%q: unexpected error: %v", raw, err)
+    }
+    if issuances == nil || len(issuances) != 0 {
+      t.Fatalf("%q: expected empty non-nil slice, got %#v", raw, issuances)
+    }
+  }
+
+  issuances, err := parseElementsIssuances(`[{"txid":"aa","vin":0,"entropy":"bb","asset":"cc","token":"dd","assetamount":10.5,"tokenamount":1,"isreissuance":false,"assetblinds":"00"}]`)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(issuances) != 1 || issuances[0].Asset != "cc" || issuances[0].AssetAmount != 10.5 {
+    t.Fatalf("unexpected issuances: %+v", issuances)
+  }
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+  "encoding/json"
+  "net/http"
+  "strings"
+)
+
+type elementsChainTip struct {
+  Height int64 `json:"height"`
+  Hash string `json:"hash"`
+  BranchLen int64 `json:"branchlen"`
+  Status string `json:"status"`
+}
+
+type elementsChainTipsResponse struct {
+  Tips []elementsChainTip `json:"tips"`
+  ForkDetected bool `json:"fork_detected"`
+}
+
+func (s *Server) handleElementsChainTips(w http.ResponseWriter, r *http.Request) {
+  ctx, paths, cancel, ok := s.elementsRunningPaths(w, r)
+  if !ok {
+    return
+  }
+  defer cancel()
+
+  out, err := runElementsCLI(ctx, paths, "getchaintips")
+  if err != nil {
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_rpc_failed", "Elements RPC unavailable")
+    return
+  }
+  tips, err := parseElementsChainTips(out)
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "elements_rpc_invalid_response", "failed to parse chain tips")
+    return
+  }
+
+  writeJSON(w, http.StatusOK, elementsChainTipsResponse{Tips: tips, ForkDetected: elementsForkDetected(tips)})
+}
+
+func parseElementsChainTips(raw string) ([]elementsChainTip, error) {
+  tips := []elementsChainTip{}
+  if strings.TrimSpace(raw) == "" {
+    return tips, nil
+  }
+  if err := json.Unmarshal([]byte(raw), &tips); err != nil {
+    return nil, err
+  }
+  if tips == nil {
+    tips = []elementsChainTip{}
+  }
+  return tips, nil
+}
+
+func elementsForkDetected(tips []elementsChainTip) bool {
+  for _, tip := range tips {
+    switch tip.Status {
+    case "valid-fork", "invalid":
+      return true
+    }
+  }
+  return false
+}
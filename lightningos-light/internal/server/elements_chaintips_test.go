@@ -0,0 +1,43 @@
+package server
+
+import "testing"
+
+func TestParseElementsChainTipsSingleTip(t *testing.T) {
+  tips, err := parseElementsChainTips(`[{"height":2800000,"hash":"abc","branchlen":0,"status":"active"}]`)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(tips) != 1 || tips[0].Height != 2800000 || tips[0].Status != "active" {
+    t.Fatalf("unexpected tips: %+v", tips)
+  }
+  if elementsForkDetected(tips) {
+    t.Fatalf("single active tip should not be a fork")
+  }
+}
+
+func TestElementsForkDetected(t *testing.T) {
+  tips, err := parseElementsChainTips(`[
+    {"height":2800000,"hash":"abc","branchlen":0,"status":"active"},
+    {"height":2799990,"hash":"def","branchlen":2,"status":"headers-only"}
+  ]`)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if elementsForkDetected(tips) {
+    t.Fatalf("headers-only tip should not be flagged")
+  }
+
+  for _, status := range []string{"valid-fork", "invalid"} {
+    tips[1].Status = status
+    if !elementsForkDetected(tips) {
+      t.Fatalf("expected %s tip to be flagged", status)
+    }
+  }
+
+  if tips, err := parseElementsChainTips(""); err != nil || len(tips) != 0 || tips == nil {
+    t.Fatalf("expected empty tips for empty output, got %v (%v)", tips, err)
+  }
+  if _, err := parseElementsChainTips("{"); err == nil {
+    t.Fatalf("expected parse error")
+  }
+}
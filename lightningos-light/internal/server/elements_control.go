@@ -0,0 +1,95 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "strings"
+  "time"
+)
+
+var elementsControlActions = []string{"start", "stop", "restart"}
+
+type elementsControlResponse struct {
+  OK bool `json:"ok"`
+  Action string `json:"action"`
+  Status string `json:"status,omitempty"`
+  DryRun bool `json:"dry_run,omitempty"`
+  Command []string `json:"command,omitempty"`
+}
+
+func (s *Server) handleElementsControl(w http.ResponseWriter, r *http.Request) {
+  var req struct {
+    Action string `json:"action"`
+  }
+  if err := readJSON(r, &req); err != nil {
+    writeErrorCode(w, http.StatusBadRequest, "invalid_json", "invalid json")
+    return
+  }
+  action := strings.ToLower(strings.TrimSpace(req.Action))
+  if !stringInSlice(action, elementsControlActions) {
+    writeErrorCode(w, http.StatusBadRequest, "invalid_action", "action must be start, stop or restart")
+    return
+  }
+
+  paths := s.elementsPaths()
+  if !fileExists(paths.ElementsdPath) {
+    writeErrorCode(w, http.StatusBadRequest, "elements_not_installed", "Elements is not installed")
+    return
+  }
+
+  args := []string{"systemctl", action, s.elementsServiceUnit()}
+  if strings.TrimSpace(r.URL.Query().Get("dry_run")) == "1" {
+    writeJSON(w, http.StatusOK, elementsControlResponse{
+      OK: true,
+      Action: action,
+      DryRun: true,
+      Command: append([]string{"systemd-run"}, systemdRunArgs(args)...),
+    })
+    return
+  }
+
+  key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+  if key == "" {
+    status, payload := s.runElementsControl(r.Context(), action, args)
+    s.recordControlAudit(r, "elements."+action, controlAuditResult(status))
+    writeJSON(w, status, payload)
+    return
+  }
+
+  entry, owner := s.elementsControlKeys.begin(key, time.Now())
+  if !owner {
+    status, payload, err := s.elementsControlKeys.wait(r.Context(), entry)
+    if err != nil {
+      writeErrorCode(w, http.StatusRequestTimeout, "request_canceled", "request canceled")
+      return
+    }
+    w.Header().Set("Idempotent-Replayed", "true")
+    writeJSON(w, status, payload)
+    return
+  }
+  status, payload := s.runElementsControl(r.Context(), action, args)
+  s.recordControlAudit(r, "elements."+action, controlAuditResult(status))
+  s.elementsControlKeys.finish(entry, status, payload, time.Now())
+  writeJSON(w, status, payload)
+}
+
+func (s *Server) runElementsControl(parent context.Context, action string, args []string) (int, any) {
+  ctx, cancel := context.WithTimeout(parent, 12*time.Second)
+  defer cancel()
+
+  _, err := runSystemd(ctx, args...)
+  s.invalidateElementsStatus()
+  if err != nil {
+    return http.StatusInternalServerError, errorCodePayload("elements_control_failed", "elements "+action+" failed")
+  }
+
+  resp := elementsControlResponse{OK: true, Action: action}
+  if action == "start" || action == "restart" {
+    if status, err := elementsServiceStatus(ctx, s.elementsServiceUnit()); err == nil {
+      resp.Status = status
+    } else {
+      resp.Status = "unknown"
+    }
+  }
+  return http.StatusOK, resp
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+  "context"
+  "io/fs"
+  "path/filepath"
+  "time"
+)
+
+const (
+  elementsDiskCacheTTL = 5 * time.Minute
+  elementsDiskTimeout = 20 * time.Second
+)
+
+func (s *Server) elementsDataDirBytes(parent context.Context, dataDir string) (int64, error) {
+  s.elementsDiskMu.Lock()
+  defer s.elementsDiskMu.Unlock()
+  if s.elementsDiskDir == dataDir && time.Now().Before(s.elementsDiskExpires) {
+    return s.elementsDiskBytes, nil
+  }
+
+  ctx, cancel := context.WithTimeout(parent, elementsDiskTimeout)
+  defer cancel()
+  total, err := dirSize(ctx, dataDir)
+  if err != nil {
+    return 0, err
+  }
+  s.elementsDiskDir = dataDir
+  s.elementsDiskBytes = total
+  s.elementsDiskExpires = time.Now().Add(elementsDiskCacheTTL)
+  return total, nil
+}
+
+func dirSize(ctx context.Context, root string) (int64, error) {
+  var total int64
+  err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+    if ctxErr := ctx.Err(); ctxErr != nil {
+      return ctxErr
+    }
+    if err != nil {
+      if path == root {
+        return err
+      }
+      if d != nil && d.IsDir() {
+        return fs.SkipDir
+      }
+      return nil
+    }
+    if d.IsDir() {
+      return nil
+    }
+    info, err := d.Info()
+    if err != nil {
+      return nil
+    }
+    if info.Mode().IsRegular() {
+      total += info.Size()
+    }
+    return nil
+  })
+  return total, err
+}
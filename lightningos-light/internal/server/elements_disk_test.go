@@ -0,0 +1,39 @@
+package server
+
+import (
+  "context"
+  "os"
+  "path/filepath"
+  "testing"
+)
+
+func TestDirSize(t *testing.T) {
+  root := t.TempDir()
+  if err := os.MkdirAll(filepath.Join(root, "chainstate"), 0o755); err != nil {
+    t.Fatalf("mkdir: %v", err)
+  }
+  if err := os.WriteFile(filepath.Join(root, "debug.log"), make([]byte, 100), 0o644); err != nil {
+    t.Fatalf("write: %v", err)
+  }
+  if err := os.WriteFile(filepath.Join(root, "chainstate", "000001.ldb"), make([]byte, 250), 0o644); err != nil {
+    t.Fatalf("write: %v", err)
+  }
+
+  total, err := dirSize(context.Background(), root)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if total != 350 {
+    t.Fatalf("expected 350 bytes, got %d", total)
+  }
+
+  if _, err := dirSize(context.Background(), filepath.Join(root, "missing")); err == nil {
+    t.Fatalf("expected error for missing root")
+  }
+
+  ctx, cancel := context.WithCancel(context.Background())
+  cancel()
+  if _, err := dirSize(ctx, root); err == nil {
+    t.Fatalf("expected error for canceled context")
+  }
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/elements"
+)
+
+const (
+  elementsSnapshotInterval = 5 * time.Minute
+  elementsSnapshotRetentionDays = 90
+  elementsHistoryMaxRangeDays = 90
+)
+
+func elementsSnapshotFromStatus(status elementsStatus, capturedAt time.Time) (elements.Snapshot, bool) {
+  if !status.Installed || !status.RPCOk {
+    return elements.Snapshot{}, false
+  }
+  return elements.Snapshot{
+    CapturedAt: capturedAt,
+    Blocks: status.Blocks,
+    Headers: status.Headers,
+    VerificationProgress: status.VerificationProgress,
+    Peers: status.Peers,
+  }, true
+}
+
+func (s *Server) startElementsSnapshotter() {
+  if s.db == nil {
+    return
+  }
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  err := elements.EnsureHistorySchema(ctx, s.db)
+  cancel()
+  if err != nil {
+    s.logger.Printf("elements history disabled: failed to init schema: %v", err)
+    return
+  }
+
+  go func() {
+    ticker := time.NewTicker(elementsSnapshotInterval)
+    defer ticker.Stop()
+    for range ticker.C {
+      s.captureElementsSnapshot()
+    }
+  }()
+}
+
+func (s *Server) captureElementsSnapshot() {
+  status, ok := s.cachedElementsStatus()
+  if !ok {
+    status = s.loadElementsStatus(context.Background())
+  }
+  snap, ok := elementsSnapshotFromStatus(status, time.Now())
+  if !ok {
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  defer cancel()
+  if err := elements.InsertSnapshot(ctx, s.db, snap); err != nil {
+    s.logger.Printf("elements history: insert failed: %v", err)
+    return
+  }
+  _ = elements.PruneSnapshots(ctx, s.db, time.Now().AddDate(0, 0, -elementsSnapshotRetentionDays))
+}
+
+func (s *Server) handleElementsHistory(w http.ResponseWriter, r *http.Request) {
+  if s.db == nil {
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_history_unavailable", "elements history unavailable")
+    return
+  }
+
+  end := time.Now()
+  start := end.Add(-24 * time.Hour)
+  if raw := strings.TrimSpace(r.URL.Query().Get("from")); raw != "" {
+    parsed, err := time.Parse(time.RFC3339, raw)
+    if err != nil {
+      writeErrorCode(w, http.StatusBadRequest, "invalid_range", "from must be RFC3339")
+      return
+    }
+    start = parsed
+  }
+  if raw := strings.TrimSpace(r.URL.Query().Get("to")); raw != "" {
+    parsed, err := time.Parse(time.RFC3339, raw)
+    if err != nil {
+      writeErrorCode(w, http.StatusBadRequest, "invalid_range", "to must be RFC3339")
+      return
+    }
+    end = parsed
+  }
+  if end.Before(start) || end.Sub(start) > elementsHistoryMaxRangeDays*24*time.Hour {
+    writeErrorCode(w, http.StatusBadRequest, "invalid_range", "range must be positive and at most 90 days")
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+  items, err := elements.FetchSnapshots(ctx, s.db, start, end)
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "elements_history_failed", "failed to load elements history")
+    return
+  }
+  if items == nil {
+    items = []elements.Snapshot{}
+  }
+  writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
@@ -19,7 +19,7 @@ type elementsMainchainState struct {
 }
 
 func (s *Server) handleElementsMainchainGet(w http.ResponseWriter, r *http.Request) {
-  paths := elementsAppPaths()
+  paths := s.elementsPaths()
   source := readElementsMainchainSource(paths)
   ctx, cancel := context.WithTimeout(r.Context(), 6*time.Second)
   defer cancel()
@@ -53,7 +53,7 @@ func (s *Server) handleElementsMainchainPost(w http.ResponseWriter, r *http.Requ
     return
   }
 
-  paths := elementsAppPaths()
+  paths := s.elementsPaths()
   if !fileExists(paths.ElementsdPath) {
     writeError(w, http.StatusBadRequest, "Elements is not installed")
     return
@@ -84,7 +84,9 @@ func (s *Server) handleElementsMainchainPost(w http.ResponseWriter, r *http.Requ
     writeError(w, http.StatusInternalServerError, err.Error())
     return
   }
-  if _, err := runSystemd(ctx, "systemctl", "restart", elementsServiceName); err != nil {
+  _, err := runSystemd(ctx, "systemctl", "restart", s.elementsServiceUnit())
+  s.invalidateElementsStatus()
+  if err != nil {
     writeError(w, http.StatusInternalServerError, "elements restart failed")
     return
   }
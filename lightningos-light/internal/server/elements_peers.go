@@ -0,0 +1,50 @@
+package server
+
+import (
+  "encoding/json"
+  "net/http"
+)
+
+const elementsPeersLimit = 50
+
+type elementsPeer struct {
+  Addr string `json:"addr"`
+  Subver string `json:"subver"`
+  Inbound bool `json:"inbound"`
+  SyncedBlocks int64 `json:"synced_blocks"`
+  BytesSent int64 `json:"bytessent"`
+  BytesRecv int64 `json:"bytesrecv"`
+}
+
+type elementsPeersResponse struct {
+  Total int `json:"total"`
+  Peers []elementsPeer `json:"peers"`
+}
+
+func (s *Server) handleElementsPeers(w http.ResponseWriter, r *http.Request) {
+  ctx, paths, cancel, ok := s.elementsRunningPaths(w, r)
+  if !ok {
+    return
+  }
+  defer cancel()
+
+  out, err := runElementsCLI(ctx, paths, "getpeerinfo")
+  if err != nil {
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_rpc_failed", "Elements RPC unavailable")
+    return
+  }
+  var peers []elementsPeer
+  if err := json.Unmarshal([]byte(out), &peers); err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "elements_rpc_invalid_response", "failed to parse peer info")
+    return
+  }
+
+  resp := elementsPeersResponse{Total: len(peers), Peers: peers}
+  if len(resp.Peers) > elementsPeersLimit {
+    resp.Peers = resp.Peers[:elementsPeersLimit]
+  }
+  if resp.Peers == nil {
+    resp.Peers = []elementsPeer{}
+  }
+  writeJSON(w, http.StatusOK, resp)
+}
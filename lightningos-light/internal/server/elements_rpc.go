@@ -0,0 +1,139 @@
+package server
+
+import (
+  "encoding/json"
+  "fmt"
+  "math"
+  "net/http"
+  "regexp"
+  "strconv"
+  "strings"
+)
+
+type elementsRPCArgKind int
+
+const (
+  elementsRPCArgInt elementsRPCArgKind = iota
+  elementsRPCArgHash
+  elementsRPCArgBool
+)
+
+type elementsRPCSpec struct {
+  Args []elementsRPCArgKind
+  Required int
+}
+
+// Read-only RPCs that can be run through /api/elements/rpc. Wallet and
+// node-mutating methods are deliberately absent.
+var elementsReadRPCs = map[string]elementsRPCSpec{
+  "getbestblockhash": {},
+  "getblockcount": {},
+  "getblockhash": {Args: []elementsRPCArgKind{elementsRPCArgInt}, Required: 1},
+  "getblock": {Args: []elementsRPCArgKind{elementsRPCArgHash, elementsRPCArgInt}, Required: 1},
+  "getblockheader": {Args: []elementsRPCArgKind{elementsRPCArgHash, elementsRPCArgBool}, Required: 1},
+  "getrawtransaction": {Args: []elementsRPCArgKind{elementsRPCArgHash, elementsRPCArgBool}, Required: 1},
+  "gettxout": {Args: []elementsRPCArgKind{elementsRPCArgHash, elementsRPCArgInt, elementsRPCArgBool}, Required: 2},
+  "getmempoolentry": {Args: []elementsRPCArgKind{elementsRPCArgHash}, Required: 1},
+  "getrawmempool": {Args: []elementsRPCArgKind{elementsRPCArgBool}},
+  "getchaintxstats": {Args: []elementsRPCArgKind{elementsRPCArgInt}},
+  "getdifficulty": {},
+  "gettxoutsetinfo": {},
+}
+
+var elementsRPCHashPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+type elementsRPCResponse struct {
+  Method string `json:"method"`
+  Result json.RawMessage `json:"result"`
+}
+
+func (s *Server) handleElementsRPC(w http.ResponseWriter, r *http.Request) {
+  var req struct {
+    Method string `json:"method"`
+    Params []any `json:"params"`
+  }
+  if err := readJSON(r, &req); err != nil {
+    writeErrorCode(w, http.StatusBadRequest, "invalid_json", "invalid json")
+    return
+  }
+  method := strings.TrimSpace(req.Method)
+  args, err := elementsRPCArgs(method, req.Params)
+  if err != nil {
+    code := "elements_rpc_invalid_params"
+    if _, ok := elementsReadRPCs[method]; !ok {
+      code = "elements_rpc_method_not_allowed"
+    }
+    writeErrorCode(w, http.StatusBadRequest, code, err.Error())
+    return
+  }
+
+  ctx, paths, cancel, ok := s.elementsRunningPaths(w, r)
+  if !ok {
+    return
+  }
+  defer cancel()
+
+  out, err := runElementsCLI(ctx, paths, append([]string{method}, args...)...)
+  if err != nil {
+    writeErrorCode(w, http.StatusBadGateway, "elements_rpc_failed", "Elements RPC call failed")
+    return
+  }
+  writeJSON(w, http.StatusOK, elementsRPCResponse{Method: method, Result: elementsRPCResult(out)})
+}
+
+func elementsRPCArgs(method string, params []any) ([]string, error) {
+  spec, ok := elementsReadRPCs[method]
+  if !ok {
+    return nil, fmt.Errorf("method not allowed: %q", method)
+  }
+  if len(params) < spec.Required || len(params) > len(spec.Args) {
+    if spec.Required == len(spec.Args) {
+      return nil, fmt.Errorf("%s takes %d params", method, len(spec.Args))
+    }
+    return nil, fmt.Errorf("%s takes %d to %d params", method, spec.Required, len(spec.Args))
+  }
+  args := make([]string, 0, len(params))
+  for i, param := range params {
+    arg, err := elementsRPCArg(spec.Args[i], param)
+    if err != nil {
+      return nil, fmt.Errorf("param %d: %w", i+1, err)
+    }
+    args = append(args, arg)
+  }
+  return args, nil
+}
+
+func elementsRPCArg(kind elementsRPCArgKind, param any) (string, error) {
+  switch kind {
+  case elementsRPCArgInt:
+    value, ok := param.(float64)
+    if !ok || value < 0 || value > math.MaxInt32 || value != math.Trunc(value) {
+      return "", fmt.Errorf("expected a non-negative integer")
+    }
+    return strconv.FormatInt(int64(value), 10), nil
+  case elementsRPCArgHash:
+    value, ok := param.(string)
+    if !ok || !elementsRPCHashPattern.MatchString(value) {
+      return "", fmt.Errorf("expected a 64 character hex hash")
+    }
+    return strings.ToLower(value), nil
+  case elementsRPCArgBool:
+    value, ok := param.(bool)
+    if !ok {
+      return "", fmt.Errorf("expected a boolean")
+    }
+    return strconv.FormatBool(value), nil
+  }
+  return "", fmt.Errorf("unsupported param")
+}
+
+// elements-cli prints string results without quotes; anything that is not
+// valid JSON is returned as a JSON string.
+func elementsRPCResult(out string) json.RawMessage {
+  out = strings.TrimSpace(out)
+  if out != "" && json.Valid([]byte(out)) {
+    return json.RawMessage(out)
+  }
+  encoded, _ := json.Marshal(out)
+  return json.RawMessage(encoded)
+}
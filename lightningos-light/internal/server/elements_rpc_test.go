@@ -0,0 +1,66 @@
+package server
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestElementsRPCArgs(t *testing.T) {
+  hash := strings.Repeat("ab", 32)
+  args, err := elementsRPCArgs("gettxout", []any{hash, float64(1), true})
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if strings.Join(args, " ") != hash+" 1 true" {
+    t.Fatalf("unexpected args: %v", args)
+  }
+  if args, err := elementsRPCArgs("getblockcount", nil); err != nil || len(args) != 0 {
+    t.Fatalf("expected no args, got %v (%v)", args, err)
+  }
+
+  cases := []struct {
+    method string
+    params []any
+  }{
+    {"sendtoaddress", []any{"addr", float64(1)}},
+    {"getnewaddress", nil},
+    {"stop", nil},
+    {"getblockhash", nil},
+    {"getblockhash", []any{float64(1), float64(2)}},
+    {"getblockhash", []any{"100"}},
+    {"getblockhash", []any{float64(-1)}},
+    {"getblockhash", []any{1.5}},
+    {"getblock", []any{"-rpcconnect=evil"}},
+    {"gettxout", []any{hash}},
+    {"getblockheader", []any{hash, "true"}},
+  }
+  for _, tc := range cases {
+    if _, err := elementsRPCArgs(tc.method, tc.params); err == nil {
+      t.Fatalf("expected %s %v to be rejected", tc.method, tc.params)
+    }
+  }
+}
+
+func TestElementsRPCMethodsPassCLIValidation(t *testing.T) {
+  for method := range elementsReadRPCs {
+    if err := validateElementsCLIArgs([]string{method}); err != nil {
+      t.Fatalf("read RPC %s rejected by CLI allowlist: %v", method, err)
+    }
+  }
+}
+
+func TestElementsRPCResult(t *testing.T) {
+  if got := string(elementsRPCResult("12345\n")); got != "12345" {
+    t.Fatalf("unexpected number result: %s", got)
+  }
+  hash := strings.Repeat("0f", 32)
+  if got := string(elementsRPCResult(hash + "\n")); got != `"`+hash+`"` {
+    t.Fatalf("expected quoted hash, got %s", got)
+  }
+  if got := string(elementsRPCResult(`{"confirmations":3}`)); got != `{"confirmations":3}` {
+    t.Fatalf("unexpected object result: %s", got)
+  }
+  if got := string(elementsRPCResult("")); got != `""` {
+    t.Fatalf("expected empty string result, got %s", got)
+  }
+}
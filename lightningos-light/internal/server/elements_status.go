@@ -4,11 +4,33 @@ import (
   "context"
   "encoding/json"
   "errors"
+  "fmt"
+  "net"
   "net/http"
+  "regexp"
+  "strconv"
   "strings"
+  "sync"
   "time"
+
+  "lightningos-light/internal/config"
+)
+
+const (
+  elementsStatusCacheTTL = 5 * time.Second
+  elementsMainchainDialTimeout = 2 * time.Second
+)
+
+const (
+  elementsSyncIBD = "ibd"
+  elementsSyncVerifying = "verifying"
+  elementsSyncSynced = "synced"
+  elementsSyncedProgress = 0.9999
+  elementsRPCErrorMaxLen = 200
 )
 
+var runElementsCLI = execElementsCLI
+
 type elementsStatus struct {
   Installed bool `json:"installed"`
   Status string `json:"status"`
@@ -16,16 +38,35 @@ type elementsStatus struct {
   MainchainSource string `json:"mainchain_source,omitempty"`
   MainchainRPCHost string `json:"mainchain_rpchost,omitempty"`
   MainchainRPCPort int `json:"mainchain_rpcport,omitempty"`
+  MainchainReachable bool `json:"mainchain_reachable"`
+  MainchainMismatch bool `json:"mainchain_mismatch"`
   RPCOk bool `json:"rpc_ok"`
+  RPCError string `json:"rpc_error,omitempty"`
+  RPCLatencyMs int64 `json:"rpc_latency_ms"`
   Chain string `json:"chain,omitempty"`
+  ExpectedChain string `json:"expected_chain,omitempty"`
+  ChainMatch bool `json:"chain_match"`
   Blocks int64 `json:"blocks,omitempty"`
   Headers int64 `json:"headers,omitempty"`
   VerificationProgress float64 `json:"verification_progress,omitempty"`
   InitialBlockDownload bool `json:"initial_block_download,omitempty"`
+  SyncState string `json:"sync_state,omitempty"`
+  BestBlockTime int64 `json:"bestblock_time,omitempty"`
+  StaleSeconds int64 `json:"stale_seconds,omitempty"`
+  Stale bool `json:"stale"`
   Peers int `json:"peers,omitempty"`
   Version int `json:"version,omitempty"`
   Subversion string `json:"subversion,omitempty"`
+  MinVersion string `json:"min_version,omitempty"`
+  Outdated bool `json:"outdated"`
   SizeOnDisk int64 `json:"size_on_disk,omitempty"`
+  DataDirBytes int64 `json:"data_dir_bytes,omitempty"`
+  MempoolTxCount int `json:"mempool_tx_count,omitempty"`
+  MempoolBytes int64 `json:"mempool_bytes,omitempty"`
+  FeeEstimate float64 `json:"fee_estimate,omitempty"`
+  WalletBalances map[string]float64 `json:"wallet_balances,omitempty"`
+  AsOf string `json:"as_of,omitempty"`
+  Error *apiError `json:"error,omitempty"`
 }
 
 type elementsChainInfo struct {
@@ -35,6 +76,8 @@ type elementsChainInfo struct {
   VerificationProgress float64 `json:"verificationprogress"`
   InitialBlockDownload bool `json:"initialblockdownload"`
   SizeOnDisk int64 `json:"size_on_disk"`
+  Time int64 `json:"time"`
+  MedianTime int64 `json:"mediantime"`
 }
 
 type elementsNetworkInfo struct {
@@ -43,111 +86,541 @@ type elementsNetworkInfo struct {
   Connections int `json:"connections"`
 }
 
+type elementsMempoolInfo struct {
+  Size int `json:"size"`
+  Bytes int64 `json:"bytes"`
+}
+
 func (s *Server) handleElementsStatus(w http.ResponseWriter, r *http.Request) {
-  paths := elementsAppPaths()
-  resp := elementsStatus{
+  if strings.TrimSpace(r.URL.Query().Get("fresh")) == "1" {
+    s.invalidateElementsStatus()
+  }
+  resp := s.currentElementsStatus(r.Context())
+
+  if resp.Installed && strings.TrimSpace(r.URL.Query().Get("disk")) == "1" {
+    if total, err := s.elementsDataDirBytes(r.Context(), resp.DataDir); err == nil {
+      resp.DataDirBytes = total
+    } else {
+      s.logger.Printf("elements: data dir size failed: %v", err)
+    }
+  }
+  if resp.RPCOk && strings.TrimSpace(r.URL.Query().Get("fee")) == "1" {
+    paths := s.elementsPaths()
+    if feerate, err := fetchElementsFeeEstimate(r.Context(), paths); err == nil {
+      resp.FeeEstimate = feerate
+    } else {
+      s.logger.Printf("elements: estimatesmartfee failed: %v", err)
+    }
+  }
+  writeJSON(w, http.StatusOK, resp)
+}
+
+func elementsSyncState(initialBlockDownload bool, verificationProgress float64) string {
+  if initialBlockDownload {
+    return elementsSyncIBD
+  }
+  if verificationProgress > elementsSyncedProgress {
+    return elementsSyncSynced
+  }
+  return elementsSyncVerifying
+}
+
+// elementsTipStaleness reports how old the best block is. Newer Elements
+// releases include the tip time in getblockchaininfo; older ones only have
+// mediantime, which lags the tip by a few blocks. A node still in IBD is
+// expected to have an old tip and is never flagged.
+func elementsTipStaleness(info elementsChainInfo, now time.Time, threshold time.Duration) (int64, int64, bool) {
+  blockTime := info.Time
+  if blockTime <= 0 {
+    blockTime = info.MedianTime
+  }
+  if blockTime <= 0 {
+    return 0, 0, false
+  }
+  staleSeconds := max(now.Unix()-blockTime, 0)
+  stale := !info.InitialBlockDownload && threshold > 0 && time.Duration(staleSeconds)*time.Second > threshold
+  return blockTime, staleSeconds, stale
+}
+
+func elementsMainchainMismatch(host string, port int, expectedHost string, expectedPort int) bool {
+  if host != "" && expectedHost != "" && !strings.EqualFold(host, expectedHost) {
+    return true
+  }
+  if port != 0 && expectedPort != 0 && port != expectedPort {
+    return true
+  }
+  return false
+}
+
+func elementsChainMatches(chain string, expected string) bool {
+  chain = strings.TrimSpace(chain)
+  expected = strings.TrimSpace(expected)
+  if expected == "" {
+    return true
+  }
+  return chain != "" && strings.EqualFold(chain, expected)
+}
+
+func elementsMainchainReachable(ctx context.Context, host string, port int) bool {
+  if host == "" || port <= 0 {
+    return false
+  }
+  dialer := net.Dialer{Timeout: elementsMainchainDialTimeout}
+  conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+  if err != nil {
+    return false
+  }
+  _ = conn.Close()
+  return true
+}
+
+func (s *Server) loadElementsStatus(parent context.Context) (resp elementsStatus) {
+  paths := s.elementsPaths()
+  resp = elementsStatus{
     Installed: false,
     Status: "not_installed",
     DataDir: paths.DataDir,
+    AsOf: time.Now().UTC().Format(time.RFC3339),
   }
   resp.MainchainSource = readElementsMainchainSource(paths)
   if !fileExists(paths.ElementsdPath) {
-    writeJSON(w, http.StatusOK, resp)
-    return
+    return resp
   }
   resp.Installed = true
 
-  ctx, cancel := context.WithTimeout(r.Context(), 6*time.Second)
+  ctx, cancel := context.WithTimeout(parent, s.elementsStatusTimeout())
   defer cancel()
 
+  expectedHost := defaultElementsMainchainHost(resp.MainchainSource, s.cfg)
+  expectedPort := defaultElementsMainchainPort(resp.MainchainSource, s.cfg)
   if raw, err := readElementsConfig(ctx, paths); err == nil {
     host, port := parseElementsMainchainConfig(raw)
+    resp.MainchainMismatch = elementsMainchainMismatch(host, port, expectedHost, expectedPort)
     if host == "" {
-      host = defaultElementsMainchainHost(resp.MainchainSource, s.cfg)
+      host = expectedHost
     }
     if port == 0 {
-      port = defaultElementsMainchainPort(resp.MainchainSource, s.cfg)
+      port = expectedPort
     }
     resp.MainchainRPCHost = host
     resp.MainchainRPCPort = port
   } else {
-    resp.MainchainRPCHost = defaultElementsMainchainHost(resp.MainchainSource, s.cfg)
-    resp.MainchainRPCPort = defaultElementsMainchainPort(resp.MainchainSource, s.cfg)
+    resp.MainchainRPCHost = expectedHost
+    resp.MainchainRPCPort = expectedPort
   }
 
-  status, err := elementsServiceStatus(ctx)
+  reachable := make(chan bool, 1)
+  go func(host string, port int) {
+    reachable <- elementsMainchainReachable(ctx, host, port)
+  }(resp.MainchainRPCHost, resp.MainchainRPCPort)
+  defer func() {
+    resp.MainchainReachable = <-reachable
+  }()
+
+  status, err := elementsServiceStatus(ctx, s.elementsServiceUnit())
   if err != nil {
     resp.Status = "unknown"
-    writeJSON(w, http.StatusOK, resp)
-    return
+    resp.Error = &apiError{Code: "elements_status_failed", Message: "failed to read Elements service status"}
+    return resp
   }
   resp.Status = status
   if status != "running" {
-    writeJSON(w, http.StatusOK, resp)
-    return
+    return resp
   }
 
-  chainInfo, networkInfo, err := fetchElementsInfo(ctx, paths)
+  chainInfo, networkInfo, mempoolInfo, latency, err := fetchElementsInfo(ctx, paths)
+  resp.RPCLatencyMs = latency.Milliseconds()
   if err != nil {
     resp.RPCOk = false
-    writeJSON(w, http.StatusOK, resp)
-    return
+    resp.RPCError = elementsRPCErrorDetail(err)
+    var parseErr *elementsParseError
+    if errors.As(err, &parseErr) {
+      resp.Error = &apiError{Code: "elements_rpc_invalid_response", Message: "Elements RPC returned a malformed response"}
+    } else {
+      resp.Error = &apiError{Code: "elements_rpc_failed", Message: "Elements RPC unavailable"}
+    }
+    return resp
   }
 
   resp.RPCOk = true
   resp.Chain = chainInfo.Chain
+  resp.ExpectedChain = s.elementsExpectedChain()
+  resp.ChainMatch = elementsChainMatches(chainInfo.Chain, resp.ExpectedChain)
   resp.Blocks = chainInfo.Blocks
   resp.Headers = chainInfo.Headers
   resp.VerificationProgress = chainInfo.VerificationProgress
   resp.InitialBlockDownload = chainInfo.InitialBlockDownload
+  resp.SyncState = elementsSyncState(chainInfo.InitialBlockDownload, chainInfo.VerificationProgress)
   resp.SizeOnDisk = chainInfo.SizeOnDisk
+  resp.BestBlockTime, resp.StaleSeconds, resp.Stale = elementsTipStaleness(chainInfo, time.Now(), s.elementsStaleAfter())
   resp.Version = networkInfo.Version
   resp.Subversion = networkInfo.Subversion
+  resp.MinVersion = s.elementsMinVersion()
+  resp.Outdated = elementsVersionOutdated(networkInfo, resp.MinVersion)
   resp.Peers = networkInfo.Connections
+  resp.MempoolTxCount = mempoolInfo.Size
+  resp.MempoolBytes = mempoolInfo.Bytes
 
-  writeJSON(w, http.StatusOK, resp)
+  if balances, err := fetchElementsWalletBalances(ctx, paths); err == nil {
+    resp.WalletBalances = balances
+  }
+
+  return resp
+}
+
+func (s *Server) cachedElementsStatus() (elementsStatus, bool) {
+  s.elementsStatusMu.Lock()
+  defer s.elementsStatusMu.Unlock()
+  if s.elementsStatusCache == nil || time.Now().After(s.elementsStatusExpires) {
+    return elementsStatus{}, false
+  }
+  return *s.elementsStatusCache, true
 }
 
-func fetchElementsInfo(ctx context.Context, paths elementsPaths) (elementsChainInfo, elementsNetworkInfo, error) {
-  out, err := execElementsCLI(ctx, paths, "getblockchaininfo")
+func (s *Server) storeElementsStatus(resp elementsStatus) {
+  s.elementsStatusMu.Lock()
+  s.elementsStatusCache = &resp
+  s.elementsStatusExpires = time.Now().Add(elementsStatusCacheTTL)
+  s.elementsStatusMu.Unlock()
+}
+
+func (s *Server) invalidateElementsStatus() {
+  s.elementsStatusMu.Lock()
+  s.elementsStatusCache = nil
+  s.elementsStatusMu.Unlock()
+}
+
+type elementsParseError struct {
+  Method string
+  Err error
+}
+
+func (e *elementsParseError) Error() string {
+  return e.Method + ": malformed JSON response: " + e.Err.Error()
+}
+
+func (e *elementsParseError) Unwrap() error {
+  return e.Err
+}
+
+func elementsRPCErrorDetail(err error) string {
+  var parseErr *elementsParseError
+  if !errors.As(err, &parseErr) {
+    return "elements-cli call failed"
+  }
+  detail := parseErr.Error()
+  if len(detail) > elementsRPCErrorMaxLen {
+    detail = detail[:elementsRPCErrorMaxLen]
+  }
+  return detail
+}
+
+func fetchElementsInfo(ctx context.Context, paths elementsPaths) (elementsChainInfo, elementsNetworkInfo, elementsMempoolInfo, time.Duration, error) {
+  var wg sync.WaitGroup
+  var out, netOut, mempoolOut string
+  var err, netErr, mempoolErr error
+  var chainTook, netTook, mempoolTook time.Duration
+  timed := func(took *time.Duration, method string) (string, error) {
+    start := time.Now()
+    defer func() { *took = time.Since(start) }()
+    return runElementsCLI(ctx, paths, method)
+  }
+  wg.Add(3)
+  go func() {
+    defer wg.Done()
+    out, err = timed(&chainTook, "getblockchaininfo")
+  }()
+  go func() {
+    defer wg.Done()
+    netOut, netErr = timed(&netTook, "getnetworkinfo")
+  }()
+  go func() {
+    defer wg.Done()
+    mempoolOut, mempoolErr = timed(&mempoolTook, "getmempoolinfo")
+  }()
+  wg.Wait()
+  latency := max(chainTook, netTook, mempoolTook)
+
   if err != nil {
-    return elementsChainInfo{}, elementsNetworkInfo{}, err
+    return elementsChainInfo{}, elementsNetworkInfo{}, elementsMempoolInfo{}, latency, err
   }
   chainInfo := elementsChainInfo{}
   if err := json.Unmarshal([]byte(out), &chainInfo); err != nil {
-    return elementsChainInfo{}, elementsNetworkInfo{}, err
+    return elementsChainInfo{}, elementsNetworkInfo{}, elementsMempoolInfo{}, latency, &elementsParseError{Method: "getblockchaininfo", Err: err}
   }
 
-  netOut, err := execElementsCLI(ctx, paths, "getnetworkinfo")
-  if err != nil {
-    return chainInfo, elementsNetworkInfo{}, err
+  if netErr != nil {
+    return chainInfo, elementsNetworkInfo{}, elementsMempoolInfo{}, latency, netErr
   }
   netInfo := elementsNetworkInfo{}
   if err := json.Unmarshal([]byte(netOut), &netInfo); err != nil {
-    return chainInfo, elementsNetworkInfo{}, err
+    return chainInfo, elementsNetworkInfo{}, elementsMempoolInfo{}, latency, &elementsParseError{Method: "getnetworkinfo", Err: err}
   }
 
-  return chainInfo, netInfo, nil
+  mempoolInfo := elementsMempoolInfo{}
+  if mempoolErr == nil {
+    if err := json.Unmarshal([]byte(mempoolOut), &mempoolInfo); err != nil {
+      mempoolInfo = elementsMempoolInfo{}
+    }
+  }
+
+  return chainInfo, netInfo, mempoolInfo, latency, nil
+}
+
+// fetchElementsFeeEstimate returns the 6-block feerate in L-BTC/kvB. When the
+// node has too little data estimatesmartfee answers with an errors array and no
+// feerate, which is reported as zero rather than an error.
+func fetchElementsFeeEstimate(ctx context.Context, paths elementsPaths) (float64, error) {
+  out, err := execElementsCLI(ctx, paths, "estimatesmartfee", "6")
+  if err != nil {
+    return 0, err
+  }
+  return parseElementsFeeEstimate(out)
+}
+
+func parseElementsFeeEstimate(out string) (float64, error) {
+  var estimate struct {
+    FeeRate float64 `json:"feerate"`
+    Errors []string `json:"errors"`
+  }
+  if err := json.Unmarshal([]byte(out), &estimate); err != nil {
+    return 0, &elementsParseError{Method: "estimatesmartfee", Err: err}
+  }
+  if len(estimate.Errors) > 0 || estimate.FeeRate < 0 {
+    return 0, nil
+  }
+  return estimate.FeeRate, nil
+}
+
+func fetchElementsWalletBalances(ctx context.Context, paths elementsPaths) (map[string]float64, error) {
+  out, err := execElementsCLI(ctx, paths, "getbalance")
+  if err != nil {
+    return nil, err
+  }
+  balances := map[string]float64{}
+  if err := json.Unmarshal([]byte(out), &balances); err != nil {
+    return nil, err
+  }
+  return balances, nil
+}
+
+var elementsCLIMethods = map[string]bool{
+  "dumpassetlabels": true,
+  "estimatesmartfee": true,
+  "getbalance": true,
+  "getbestblockhash": true,
+  "getblock": true,
+  "getchaintips": true,
+  "getblockchaininfo": true,
+  "getmempoolinfo": true,
+  "getnetworkinfo": true,
+  "getpeerinfo": true,
+  "listissuances": true,
+}
+
+var (
+  elementsCLIMethodPattern = regexp.MustCompile(`^[a-z]+$`)
+  elementsCLIArgPattern = regexp.MustCompile(`^[A-Za-z0-9_.:/=,\[\]{}"-]*$`)
+)
+
+func validateElementsCLIArgs(args []string) error {
+  if len(args) == 0 {
+    return errors.New("elements-cli method required")
+  }
+  method := args[0]
+  _, readRPC := elementsReadRPCs[method]
+  if !elementsCLIMethodPattern.MatchString(method) || !(elementsCLIMethods[method] || readRPC) {
+    return fmt.Errorf("elements-cli method not allowed: %q", method)
+  }
+  for _, arg := range args[1:] {
+    if strings.HasPrefix(arg, "-") || !elementsCLIArgPattern.MatchString(arg) {
+      return fmt.Errorf("elements-cli argument not allowed: %q", arg)
+    }
+  }
+  return nil
 }
 
 func execElementsCLI(ctx context.Context, paths elementsPaths, args ...string) (string, error) {
+  out, err := elementsCLI(ctx, paths, args...)
+  if err != nil {
+    method := ""
+    if len(args) > 0 {
+      method = args[0]
+    }
+    logCommandError(ctx, "elements-cli "+method, err)
+  }
+  return out, err
+}
+
+func elementsCLI(ctx context.Context, paths elementsPaths, args ...string) (string, error) {
+  if err := validateElementsCLIArgs(args); err != nil {
+    return "", err
+  }
   if !fileExists(paths.ElementsCliPath) {
     return "", errors.New("elements-cli missing")
   }
+  rpcWait := paths.RPCWaitTimeoutSec
+  if rpcWait <= 0 {
+    rpcWait = elementsDefaultRPCWaitSec
+  }
+  user := paths.User
+  if user == "" {
+    user = elementsUser
+  }
   cliArgs := []string{
-    "--uid", elementsUser,
-    "--gid", elementsUser,
+    "--uid", user,
+    "--gid", user,
     "--property=WorkingDirectory=" + paths.DataDir,
     paths.ElementsCliPath,
     "-conf=" + paths.ConfigPath,
     "-datadir=" + paths.DataDir,
     "-rpcwait",
-    "-rpcwaittimeout=5",
+    "-rpcwaittimeout=" + strconv.Itoa(rpcWait),
   }
   cliArgs = append(cliArgs, args...)
-  out, err := runSystemd(ctx, cliArgs...)
+  // Every allowed method is a read, so a transient bus error is safe to retry.
+  out, err := retryTransient(ctx, systemdStatusAttempts, func() (string, error) {
+    return systemdRun(ctx, cliArgs...)
+  })
   if err != nil {
     return "", err
   }
   return strings.TrimSpace(out), nil
 }
+
+func (s *Server) elementsRPCWaitTimeoutSec() int {
+  if s.cfg == nil || s.cfg.Elements.RPCWaitTimeoutSec <= 0 {
+    return elementsDefaultRPCWaitSec
+  }
+  return s.cfg.Elements.RPCWaitTimeoutSec
+}
+
+// elementsUser is the account elements-cli runs as. Config validation rejects
+// unsafe names; the default covers a nil config.
+func (s *Server) elementsUser() string {
+  if s.cfg == nil || !config.ValidUserName(s.cfg.Elements.User) {
+    return elementsUser
+  }
+  return s.cfg.Elements.User
+}
+
+func (s *Server) elementsExpectedChain() string {
+  if s.cfg == nil || strings.TrimSpace(s.cfg.Elements.ExpectedChain) == "" {
+    return "liquidv1"
+  }
+  return strings.TrimSpace(s.cfg.Elements.ExpectedChain)
+}
+
+// elementsServiceUnit is the systemd unit used for Elements status and control.
+// Config validation rejects unsafe names; the default covers a nil config.
+func (s *Server) elementsServiceUnit() string {
+  if s.cfg == nil || !config.ValidServiceUnit(s.cfg.Elements.ServiceUnit) {
+    return config.DefaultElementsServiceUnit
+  }
+  return s.cfg.Elements.ServiceUnit
+}
+
+func (s *Server) elementsStaleAfter() time.Duration {
+  if s.cfg == nil || s.cfg.Elements.StaleAfterSec <= 0 {
+    return 10 * time.Minute
+  }
+  return time.Duration(s.cfg.Elements.StaleAfterSec) * time.Second
+}
+
+// elementsMinVersion defaults to the version the app installer ships, so a
+// node installed by us is never flagged until the installer moves on.
+func (s *Server) elementsMinVersion() string {
+  if s.cfg == nil || strings.TrimSpace(s.cfg.Elements.MinVersion) == "" {
+    return elementsVersion
+  }
+  return strings.TrimSpace(s.cfg.Elements.MinVersion)
+}
+
+var elementsVersionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseElementsVersion turns "23.3.1", "/Elements Core:23.3.1/", "230301" or a
+// bare major like "23" into the integer getnetworkinfo reports
+// (major*10000 + minor*100 + patch).
+func parseElementsVersion(raw string) (int, bool) {
+  raw = strings.TrimSpace(raw)
+  if raw == "" {
+    return 0, false
+  }
+  if n, err := strconv.Atoi(raw); err == nil {
+    switch {
+    case n >= 10000:
+      return n, true
+    case n > 0 && n < 100:
+      return n * 10000, true
+    default:
+      return 0, false
+    }
+  }
+  match := elementsVersionPattern.FindStringSubmatch(raw)
+  if match == nil {
+    return 0, false
+  }
+  parts := [3]int{}
+  for i := 0; i < 3; i++ {
+    if match[i+1] == "" {
+      continue
+    }
+    n, err := strconv.Atoi(match[i+1])
+    if err != nil || (i > 0 && n > 99) {
+      return 0, false
+    }
+    parts[i] = n
+  }
+  return parts[0]*10000 + parts[1]*100 + parts[2], true
+}
+
+// elementsVersionOutdated prefers the integer version and falls back to the
+// subversion string. Anything it cannot parse is not flagged.
+func elementsVersionOutdated(info elementsNetworkInfo, minVersion string) bool {
+  minimum, ok := parseElementsVersion(minVersion)
+  if !ok {
+    return false
+  }
+  current := info.Version
+  if current <= 0 {
+    parsed, ok := parseElementsVersion(info.Subversion)
+    if !ok {
+      return false
+    }
+    current = parsed
+  }
+  return current < minimum
+}
+
+// elementsRunningPaths is the preamble of the elements read handlers: it
+// answers 503 when elementsd is not installed or not running and otherwise
+// returns the status-timeout context and the configured paths. The caller
+// must defer cancel when ok is true.
+func (s *Server) elementsRunningPaths(w http.ResponseWriter, r *http.Request) (context.Context, elementsPaths, context.CancelFunc, bool) {
+  paths := s.elementsPaths()
+  if !fileExists(paths.ElementsdPath) {
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_not_installed", "Elements is not installed")
+    return nil, paths, nil, false
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), s.elementsStatusTimeout())
+  status, err := elementsServiceStatus(ctx, s.elementsServiceUnit())
+  if err != nil || status != "running" {
+    cancel()
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_not_running", "Elements is not running")
+    return nil, paths, nil, false
+  }
+  return ctx, paths, cancel, true
+}
+
+func (s *Server) elementsStatusTimeout() time.Duration {
+  timeout := 6 * time.Second
+  if s.cfg != nil && s.cfg.Elements.StatusTimeoutSec > 0 {
+    timeout = time.Duration(s.cfg.Elements.StatusTimeoutSec) * time.Second
+  }
+  minimum := time.Duration(s.elementsRPCWaitTimeoutSec())*time.Second + elementsRPCTimeoutBuffer
+  if timeout < minimum {
+    return minimum
+  }
+  return timeout
+}
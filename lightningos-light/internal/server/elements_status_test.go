@@ -0,0 +1,391 @@
+package server
+
+import (
+  "context"
+  "errors"
+  "net"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+
+  "lightningos-light/internal/config"
+)
+
+func stubElementsCLI(t *testing.T, fn func(ctx context.Context, paths elementsPaths, args ...string) (string, error)) {
+  t.Helper()
+  prev := runElementsCLI
+  runElementsCLI = fn
+  t.Cleanup(func() { runElementsCLI = prev })
+}
+
+func TestFetchElementsInfoConcurrent(t *testing.T) {
+  delay := 200 * time.Millisecond
+  stubElementsCLI(t, func(ctx context.Context, paths elementsPaths, args ...string) (string, error) {
+    time.Sleep(delay)
+    switch args[0] {
+    case "getblockchaininfo":
+      return `{"chain":"liquidv1","blocks":10,"headers":12}`, nil
+    case "getnetworkinfo":
+      return `{"version":230301,"connections":8}`, nil
+    case "getmempoolinfo":
+      return `{"size":3,"bytes":4096}`, nil
+    }
+    return "", errors.New("unexpected command")
+  })
+
+  start := time.Now()
+  chainInfo, netInfo, mempoolInfo, latency, err := fetchElementsInfo(context.Background(), elementsPaths{})
+  elapsed := time.Since(start)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if elapsed >= 2*delay {
+    t.Fatalf("expected calls to run concurrently, took %v", elapsed)
+  }
+  if latency < delay || latency > elapsed {
+    t.Fatalf("expected latency to be the slowest call (%v..%v), got %v", delay, elapsed, latency)
+  }
+  if chainInfo.Chain != "liquidv1" || chainInfo.Blocks != 10 {
+    t.Fatalf("unexpected chain info: %+v", chainInfo)
+  }
+  if netInfo.Connections != 8 {
+    t.Fatalf("unexpected network info: %+v", netInfo)
+  }
+  if mempoolInfo.Size != 3 || mempoolInfo.Bytes != 4096 {
+    t.Fatalf("unexpected mempool info: %+v", mempoolInfo)
+  }
+}
+
+func TestFetchElementsInfoNetworkFailureKeepsChain(t *testing.T) {
+  stubElementsCLI(t, func(ctx context.Context, paths elementsPaths, args ...string) (string, error) {
+    switch args[0] {
+    case "getblockchaininfo":
+      return `{"chain":"liquidv1","blocks":10,"headers":12}`, nil
+    case "getmempoolinfo":
+      return `{"size":3,"bytes":4096}`, nil
+    }
+    return "", errors.New("rpc unavailable")
+  })
+
+  chainInfo, _, _, _, err := fetchElementsInfo(context.Background(), elementsPaths{})
+  if err == nil {
+    t.Fatalf("expected network error")
+  }
+  if chainInfo.Chain != "liquidv1" || chainInfo.Blocks != 10 {
+    t.Fatalf("expected chain info to be preserved, got %+v", chainInfo)
+  }
+}
+
+func TestElementsSyncState(t *testing.T) {
+  cases := []struct {
+    ibd bool
+    progress float64
+    want string
+  }{
+    {ibd: true, progress: 0.2, want: elementsSyncIBD},
+    {ibd: true, progress: 1, want: elementsSyncIBD},
+    {ibd: false, progress: 0, want: elementsSyncVerifying},
+    {ibd: false, progress: 0.5, want: elementsSyncVerifying},
+    {ibd: false, progress: 0.9999, want: elementsSyncVerifying},
+    {ibd: false, progress: 0.99991, want: elementsSyncSynced},
+    {ibd: false, progress: 1, want: elementsSyncSynced},
+  }
+  for _, tc := range cases {
+    if got := elementsSyncState(tc.ibd, tc.progress); got != tc.want {
+      t.Fatalf("elementsSyncState(%v, %v) = %q, want %q", tc.ibd, tc.progress, got, tc.want)
+    }
+  }
+}
+
+func TestElementsMainchainMismatch(t *testing.T) {
+  if elementsMainchainMismatch("", 0, "bitcoin.example", 8332) {
+    t.Fatalf("missing config values should not be a mismatch")
+  }
+  if elementsMainchainMismatch("Bitcoin.Example", 8332, "bitcoin.example", 8332) {
+    t.Fatalf("case-only host difference should not be a mismatch")
+  }
+  if !elementsMainchainMismatch("10.0.0.5", 8332, "127.0.0.1", 8332) {
+    t.Fatalf("expected host mismatch")
+  }
+  if !elementsMainchainMismatch("127.0.0.1", 18332, "127.0.0.1", 8332) {
+    t.Fatalf("expected port mismatch")
+  }
+}
+
+func TestElementsChainMatches(t *testing.T) {
+  if !elementsChainMatches("LiquidV1", "liquidv1") {
+    t.Fatalf("expected case-insensitive match")
+  }
+  if elementsChainMatches("elementsregtest", "liquidv1") {
+    t.Fatalf("expected regtest to mismatch liquidv1")
+  }
+  if elementsChainMatches("", "liquidv1") {
+    t.Fatalf("unknown chain should not match")
+  }
+  if !elementsChainMatches("elementsregtest", "") {
+    t.Fatalf("empty expectation should match any chain")
+  }
+}
+
+func TestElementsMainchainReachable(t *testing.T) {
+  listener, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Skipf("listen unavailable: %v", err)
+  }
+  port := listener.Addr().(*net.TCPAddr).Port
+  go func() {
+    if conn, err := listener.Accept(); err == nil {
+      _ = conn.Close()
+    }
+  }()
+
+  if !elementsMainchainReachable(context.Background(), "127.0.0.1", port) {
+    t.Fatalf("expected listener to be reachable")
+  }
+  _ = listener.Close()
+  if elementsMainchainReachable(context.Background(), "", port) {
+    t.Fatalf("empty host should be unreachable")
+  }
+}
+
+func TestElementsSnapshotFromStatus(t *testing.T) {
+  now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+  if _, ok := elementsSnapshotFromStatus(elementsStatus{Installed: true}, now); ok {
+    t.Fatalf("expected no snapshot without rpc")
+  }
+  snap, ok := elementsSnapshotFromStatus(elementsStatus{
+    Installed: true,
+    RPCOk: true,
+    Blocks: 100,
+    Headers: 120,
+    VerificationProgress: 0.5,
+    Peers: 8,
+  }, now)
+  if !ok {
+    t.Fatalf("expected snapshot")
+  }
+  if snap.Blocks != 100 || snap.Headers != 120 || snap.VerificationProgress != 0.5 || snap.Peers != 8 || !snap.CapturedAt.Equal(now) {
+    t.Fatalf("unexpected snapshot %+v", snap)
+  }
+}
+
+func TestValidateElementsCLIArgs(t *testing.T) {
+  if err := validateElementsCLIArgs([]string{"getblockchaininfo"}); err != nil {
+    t.Fatalf("expected allowed method, got %v", err)
+  }
+  if err := validateElementsCLIArgs([]string{"getbalance", "*", "1"}); err == nil {
+    t.Fatalf("expected wildcard argument to be rejected")
+  }
+  rejected := [][]string{
+    nil,
+    {"stop"},
+    {"-rpcconnect=evil"},
+    {"GetBlockchainInfo"},
+    {"getpeerinfo", "-datadir=/tmp"},
+    {"getpeerinfo", "a b"},
+    {"getpeerinfo", "x\n"},
+  }
+  for _, args := range rejected {
+    if err := validateElementsCLIArgs(args); err == nil {
+      t.Fatalf("expected %q to be rejected", args)
+    }
+  }
+  if err := validateElementsCLIArgs([]string{"getbalance", "bitcoin", "1"}); err != nil {
+    t.Fatalf("expected plain arguments to be allowed, got %v", err)
+  }
+}
+
+func TestFetchElementsInfoMalformedJSON(t *testing.T) {
+  stubElementsCLI(t, func(ctx context.Context, paths elementsPaths, args ...string) (string, error) {
+    switch args[0] {
+    case "getblockchaininfo":
+      return "error: garbage <html>", nil
+    case "getnetworkinfo":
+      return `{"version":230301,"connections":8}`, nil
+    }
+    return `{}`, nil
+  })
+
+  _, _, _, _, err := fetchElementsInfo(context.Background(), elementsPaths{})
+  var parseErr *elementsParseError
+  if !errors.As(err, &parseErr) || parseErr.Method != "getblockchaininfo" {
+    t.Fatalf("expected parse error for getblockchaininfo, got %v", err)
+  }
+  detail := elementsRPCErrorDetail(err)
+  if !strings.HasPrefix(detail, "getblockchaininfo: malformed JSON response") {
+    t.Fatalf("unexpected detail %q", detail)
+  }
+  if len(detail) > elementsRPCErrorMaxLen {
+    t.Fatalf("expected detail to be truncated, got %d chars", len(detail))
+  }
+}
+
+func TestElementsRPCErrorDetailHidesCLIErrors(t *testing.T) {
+  if got := elementsRPCErrorDetail(errors.New("rpcpassword=secret connection refused")); got != "elements-cli call failed" {
+    t.Fatalf("expected generic detail, got %q", got)
+  }
+  long := &elementsParseError{Method: "getnetworkinfo", Err: errors.New(strings.Repeat("x", 500))}
+  if got := elementsRPCErrorDetail(long); len(got) != elementsRPCErrorMaxLen {
+    t.Fatalf("expected truncation to %d chars, got %d", elementsRPCErrorMaxLen, len(got))
+  }
+}
+
+func TestElementsServiceUnit(t *testing.T) {
+  s := &Server{}
+  if got := s.elementsServiceUnit(); got != config.DefaultElementsServiceUnit {
+    t.Fatalf("expected default unit, got %q", got)
+  }
+  s.cfg = &config.Config{Elements: config.ElementsConfig{ServiceUnit: "elementsd@liquid.service"}}
+  if got := s.elementsServiceUnit(); got != "elementsd@liquid.service" {
+    t.Fatalf("expected configured unit, got %q", got)
+  }
+  for _, name := range []string{"elementsd", "elements;reboot.service", "../x.service", "a b.service", "--now.service"} {
+    if config.ValidServiceUnit(name) {
+      t.Fatalf("expected %q to be rejected", name)
+    }
+  }
+  s.cfg.Elements.ServiceUnit = "elements;reboot.service"
+  if got := s.elementsServiceUnit(); got != config.DefaultElementsServiceUnit {
+    t.Fatalf("expected fallback for unsafe unit, got %q", got)
+  }
+}
+
+func TestElementsConfiguredUnitUsedEverywhere(t *testing.T) {
+  s := &Server{cfg: &config.Config{Elements: config.ElementsConfig{ServiceUnit: "elementsd@liquid.service", User: "elements", RPCWaitTimeoutSec: 7}}}
+  if paths := s.elementsPaths(); paths.User != "elements" || paths.RPCWaitTimeoutSec != 7 {
+    t.Fatalf("expected the configured user and timeout, got %q %d", paths.User, paths.RPCWaitTimeoutSec)
+  }
+  for _, name := range []string{"lightningos-elements", "elementsd"} {
+    if got := s.mapService(name); got != "elementsd@liquid.service" {
+      t.Fatalf("mapService(%q) = %q, want the configured unit", name, got)
+    }
+  }
+  if got := s.elementsPaths().ServicePath; got != "/etc/systemd/system/elementsd@liquid.service" {
+    t.Fatalf("unexpected unit path %q", got)
+  }
+  if contents := peerswapServiceContents(peerswapAppPaths(), s.elementsServiceUnit()); !strings.Contains(contents, "After=network-online.target elementsd@liquid.service\n") {
+    t.Fatalf("expected peerswapd to start after the configured unit:\n%s", contents)
+  }
+}
+
+func TestElementsUser(t *testing.T) {
+  s := &Server{}
+  if got := s.elementsUser(); got != config.DefaultElementsUser {
+    t.Fatalf("expected default user, got %q", got)
+  }
+  s.cfg = &config.Config{Elements: config.ElementsConfig{User: "elements"}}
+  if got := s.elementsUser(); got != "elements" {
+    t.Fatalf("expected configured user, got %q", got)
+  }
+  for _, name := range []string{"", "-root", "Elements", "el ements", "a;id", "0day", strings.Repeat("a", 33)} {
+    if config.ValidUserName(name) {
+      t.Fatalf("expected %q to be rejected", name)
+    }
+  }
+  s.cfg.Elements.User = "a;id"
+  if got := s.elementsUser(); got != config.DefaultElementsUser {
+    t.Fatalf("expected fallback for unsafe user, got %q", got)
+  }
+}
+
+func TestElementsTipStaleness(t *testing.T) {
+  now := time.Unix(1_800_000_000, 0)
+  threshold := 10 * time.Minute
+
+  blockTime, staleSeconds, stale := elementsTipStaleness(elementsChainInfo{Time: now.Unix() - 60}, now, threshold)
+  if blockTime != now.Unix()-60 || staleSeconds != 60 || stale {
+    t.Fatalf("expected fresh tip, got %d %d %v", blockTime, staleSeconds, stale)
+  }
+  _, staleSeconds, stale = elementsTipStaleness(elementsChainInfo{Time: now.Unix() - 1200}, now, threshold)
+  if staleSeconds != 1200 || !stale {
+    t.Fatalf("expected stale tip, got %d %v", staleSeconds, stale)
+  }
+  blockTime, _, stale = elementsTipStaleness(elementsChainInfo{MedianTime: now.Unix() - 1200}, now, threshold)
+  if blockTime != now.Unix()-1200 || !stale {
+    t.Fatalf("expected mediantime fallback, got %d %v", blockTime, stale)
+  }
+  if _, _, stale := elementsTipStaleness(elementsChainInfo{Time: now.Unix() - 86400, InitialBlockDownload: true}, now, threshold); stale {
+    t.Fatalf("expected ibd not to be flagged stale")
+  }
+  if blockTime, _, stale := elementsTipStaleness(elementsChainInfo{}, now, threshold); blockTime != 0 || stale {
+    t.Fatalf("expected no staleness without block time")
+  }
+}
+
+func TestParseElementsFeeEstimate(t *testing.T) {
+  feerate, err := parseElementsFeeEstimate(`{"feerate": 0.00000100, "blocks": 2}`)
+  if err != nil || feerate != 0.000001 {
+    t.Fatalf("unexpected feerate: %v %v", feerate, err)
+  }
+  feerate, err = parseElementsFeeEstimate(`{"errors": ["Insufficient data or no feerate found"], "blocks": 0}`)
+  if err != nil || feerate != 0 {
+    t.Fatalf("expected zero feerate without data, got %v %v", feerate, err)
+  }
+  if _, err := parseElementsFeeEstimate(`not json`); err == nil {
+    t.Fatalf("expected parse error")
+  }
+}
+
+func TestParseElementsVersion(t *testing.T) {
+  cases := []struct {
+    raw string
+    want int
+    ok bool
+  }{
+    {"23.3.1", 230301, true},
+    {"/Elements Core:22.1.1/", 220101, true},
+    {"/Elements Core:23.2/", 230200, true},
+    {"230301", 230301, true},
+    {"23", 230000, true},
+    {"", 0, false},
+    {"/Satoshi:unknown/", 0, false},
+    {"23.300.1", 0, false},
+    {"1234", 0, false},
+  }
+  for _, tc := range cases {
+    got, ok := parseElementsVersion(tc.raw)
+    if got != tc.want || ok != tc.ok {
+      t.Fatalf("%q: expected %d/%v, got %d/%v", tc.raw, tc.want, tc.ok, got, ok)
+    }
+  }
+}
+
+func TestElementsVersionOutdated(t *testing.T) {
+  if !elementsVersionOutdated(elementsNetworkInfo{Version: 220101, Subversion: "/Elements Core:22.1.1/"}, "23.3.1") {
+    t.Fatalf("expected 22.1.1 to be outdated")
+  }
+  if elementsVersionOutdated(elementsNetworkInfo{Version: 230301}, "23.3.1") {
+    t.Fatalf("expected the minimum itself to be current")
+  }
+  if !elementsVersionOutdated(elementsNetworkInfo{Subversion: "/Elements Core:23.2.0/"}, "23.3.1") {
+    t.Fatalf("expected subversion fallback to flag 23.2.0")
+  }
+  if elementsVersionOutdated(elementsNetworkInfo{Subversion: "/custom-build/"}, "23.3.1") {
+    t.Fatalf("expected unparseable versions not to be flagged")
+  }
+  if elementsVersionOutdated(elementsNetworkInfo{Version: 10000}, "garbage") {
+    t.Fatalf("expected an invalid minimum to disable the check")
+  }
+}
+
+func TestElementsReadHandlersShareNotInstalledPreamble(t *testing.T) {
+  s := &Server{}
+  if fileExists(s.elementsPaths().ElementsdPath) {
+    t.Skip("elementsd is installed on this host")
+  }
+  handlers := map[string]http.HandlerFunc{
+    "peers": s.handleElementsPeers,
+    "assets": s.handleElementsAssets,
+    "chaintips": s.handleElementsChainTips,
+    "tip": s.handleElementsTip,
+  }
+  for name, handler := range handlers {
+    rec := httptest.NewRecorder()
+    handler(rec, httptest.NewRequest("GET", "/api/elements/"+name, nil))
+    if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "elements_not_installed") {
+      t.Fatalf("%s: expected elements_not_installed, got %d %s", name, rec.Code, rec.Body.String())
+    }
+  }
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+  "context"
+  "errors"
+  "io"
+  "net/http"
+  "net/url"
+  "strings"
+  "time"
+
+  "golang.org/x/net/websocket"
+)
+
+const (
+  elementsStreamInterval = 5 * time.Second
+  elementsStreamMaxSubscribers = 8
+)
+
+func (s *Server) handleElementsStatusStream(w http.ResponseWriter, r *http.Request) {
+  if !s.acquireElementsStream() {
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_stream_busy", "too many elements status subscribers")
+    return
+  }
+  defer s.releaseElementsStream()
+
+  server := websocket.Server{
+    Handshake: checkSameOrigin,
+    Handler: func(ws *websocket.Conn) {
+      s.streamElementsStatus(r.Context(), ws)
+    },
+  }
+  server.ServeHTTP(w, r)
+}
+
+func (s *Server) streamElementsStatus(parent context.Context, ws *websocket.Conn) {
+  ctx, cancel := context.WithCancel(parent)
+  defer cancel()
+  go func() {
+    _, _ = io.Copy(io.Discard, ws)
+    cancel()
+  }()
+
+  ticker := time.NewTicker(elementsStreamInterval)
+  defer ticker.Stop()
+  for {
+    status := s.currentElementsStatus(ctx)
+    if ctx.Err() != nil {
+      return
+    }
+    if err := websocket.JSON.Send(ws, status); err != nil {
+      return
+    }
+    select {
+    case <-ctx.Done():
+      return
+    case <-ticker.C:
+    }
+  }
+}
+
+func (s *Server) currentElementsStatus(ctx context.Context) elementsStatus {
+  if cached, ok := s.cachedElementsStatus(); ok {
+    return cached
+  }
+  resp := s.loadElementsStatus(ctx)
+  if resp.Status != "unknown" && (resp.Status != "running" || resp.RPCOk) {
+    s.storeElementsStatus(resp)
+  }
+  return resp
+}
+
+func (s *Server) acquireElementsStream() bool {
+  s.elementsStreamMu.Lock()
+  defer s.elementsStreamMu.Unlock()
+  if s.elementsStreamSubs >= elementsStreamMaxSubscribers {
+    return false
+  }
+  s.elementsStreamSubs++
+  return true
+}
+
+func (s *Server) releaseElementsStream() {
+  s.elementsStreamMu.Lock()
+  s.elementsStreamSubs--
+  s.elementsStreamMu.Unlock()
+}
+
+func checkSameOrigin(config *websocket.Config, r *http.Request) error {
+  origin := strings.TrimSpace(r.Header.Get("Origin"))
+  if origin == "" {
+    return nil
+  }
+  parsed, err := url.Parse(origin)
+  if err != nil {
+    return err
+  }
+  if !strings.EqualFold(parsed.Host, r.Host) {
+    return errors.New("cross-origin websocket not allowed")
+  }
+  config.Origin = parsed
+  return nil
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+
+  "golang.org/x/net/websocket"
+)
+
+func TestElementsStatusStreamSendsStatus(t *testing.T) {
+  s := &Server{}
+  s.storeElementsStatus(elementsStatus{Installed: true, Status: "running", RPCOk: true, Blocks: 42})
+  srv := httptest.NewServer(http.HandlerFunc(s.handleElementsStatusStream))
+  defer srv.Close()
+
+  wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+  ws, err := websocket.Dial(wsURL, "", srv.URL)
+  if err != nil {
+    t.Fatalf("dial failed: %v", err)
+  }
+  defer ws.Close()
+
+  var status elementsStatus
+  if err := websocket.JSON.Receive(ws, &status); err != nil {
+    t.Fatalf("receive failed: %v", err)
+  }
+  if status.Blocks != 42 || !status.RPCOk {
+    t.Fatalf("unexpected status: %+v", status)
+  }
+}
+
+func TestElementsStatusStreamSubscriberCap(t *testing.T) {
+  s := &Server{}
+  for i := 0; i < elementsStreamMaxSubscribers; i++ {
+    if !s.acquireElementsStream() {
+      t.Fatalf("expected subscriber %d to be accepted", i)
+    }
+  }
+  rec := httptest.NewRecorder()
+  s.handleElementsStatusStream(rec, httptest.NewRequest(http.MethodGet, "/api/elements/status/stream", nil))
+  if rec.Code != http.StatusServiceUnavailable {
+    t.Fatalf("expected 503 when full, got %d", rec.Code)
+  }
+  s.releaseElementsStream()
+  if !s.acquireElementsStream() {
+    t.Fatalf("expected slot after release")
+  }
+}
+
+func TestCheckSameOrigin(t *testing.T) {
+  req := httptest.NewRequest(http.MethodGet, "https://node.local:8443/api/elements/status/stream", nil)
+  req.Host = "node.local:8443"
+  req.Header.Set("Origin", "https://node.local:8443")
+  if err := checkSameOrigin(&websocket.Config{}, req); err != nil {
+    t.Fatalf("expected same origin to pass, got %v", err)
+  }
+  req.Header.Set("Origin", "https://evil.example")
+  if err := checkSameOrigin(&websocket.Config{}, req); err == nil {
+    t.Fatalf("expected cross origin to be rejected")
+  }
+}
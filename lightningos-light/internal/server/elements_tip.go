@@ -0,0 +1,80 @@
+package server
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "net/http"
+  "regexp"
+  "strings"
+)
+
+var elementsBlockHashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// elementsTipBlock is the compact view of the best block. When getblock fails
+// after getbestblockhash succeeded, only Hash and Error are set.
+type elementsTipBlock struct {
+  Hash string `json:"hash"`
+  Height int64 `json:"height,omitempty"`
+  Time int64 `json:"time,omitempty"`
+  TxCount int64 `json:"tx_count,omitempty"`
+  Error *apiError `json:"error,omitempty"`
+}
+
+func (s *Server) handleElementsTip(w http.ResponseWriter, r *http.Request) {
+  ctx, paths, cancel, ok := s.elementsRunningPaths(w, r)
+  if !ok {
+    return
+  }
+  defer cancel()
+
+  tip, err := fetchElementsTipBlock(ctx, paths)
+  if err != nil {
+    writeErrorCode(w, http.StatusServiceUnavailable, "elements_rpc_failed", "Elements RPC unavailable")
+    return
+  }
+  writeJSON(w, http.StatusOK, tip)
+}
+
+// fetchElementsTipBlock only fails when the best block hash is unavailable; a
+// failed getblock is reported inside the result so the hash is not lost.
+func fetchElementsTipBlock(ctx context.Context, paths elementsPaths) (elementsTipBlock, error) {
+  out, err := runElementsCLI(ctx, paths, "getbestblockhash")
+  if err != nil {
+    return elementsTipBlock{}, err
+  }
+  hash := strings.TrimSpace(out)
+  if !elementsBlockHashPattern.MatchString(hash) {
+    return elementsTipBlock{}, errors.New("invalid best block hash")
+  }
+
+  tip := elementsTipBlock{Hash: hash}
+  out, err = runElementsCLI(ctx, paths, "getblock", hash, "1")
+  if err != nil {
+    tip.Error = &apiError{Code: "elements_getblock_failed", Message: "failed to load tip block"}
+    return tip, nil
+  }
+  if err := parseElementsTipBlock(out, &tip); err != nil {
+    tip.Error = &apiError{Code: "elements_rpc_invalid_response", Message: "failed to parse tip block"}
+  }
+  return tip, nil
+}
+
+func parseElementsTipBlock(raw string, tip *elementsTipBlock) error {
+  var block struct {
+    Hash string `json:"hash"`
+    Height int64 `json:"height"`
+    Time int64 `json:"time"`
+    NTx int64 `json:"nTx"`
+  }
+  if err := json.Unmarshal([]byte(raw), &block); err != nil {
+    return err
+  }
+  if block.Hash != tip.Hash {
+    return errors.New("getblock returned a different block")
+  }
+  tip.Height = block.Height
+  tip.Time = block.Time
+  tip.TxCount = block.NTx
+  return nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+  "context"
+  "errors"
+  "strings"
+  "testing"
+)
+
+const testElementsTipHash = "4f5b9a0c2d1e3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f80"
+
+func TestFetchElementsTipBlock(t *testing.T) {
+  stubElementsCLI(t, func(ctx context.Context, paths elementsPaths, args ...string) (string, error) {
+    switch args[0] {
+    case "getbestblockhash":
+      return testElementsTipHash + "\n", nil
+    case "getblock":
+      if len(args) != 3 || args[1] != testElementsTipHash || args[2] != "1" {
+        return "", errors.New("unexpected getblock args")
+      }
+      return `{"hash":"` + testElementsTipHash + `","height":3100000,"time":1760000000,"nTx":7,"tx":["a"]}`, nil
+    }
+    return "", errors.New("unexpected command")
+  })
+
+  tip, err := fetchElementsTipBlock(context.Background(), elementsPaths{})
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if tip.Hash != testElementsTipHash || tip.Height != 3100000 || tip.Time != 1760000000 || tip.TxCount != 7 || tip.Error != nil {
+    t.Fatalf("unexpected tip: %+v", tip)
+  }
+}
+
+func TestFetchElementsTipBlockGetBlockFailureKeepsHash(t *testing.T) {
+  stubElementsCLI(t, func(ctx context.Context, paths elementsPaths, args ...string) (string, error) {
+    if args[0] == "getbestblockhash" {
+      return testElementsTipHash, nil
+    }
+    return "", errors.New("rpc timeout")
+  })
+
+  tip, err := fetchElementsTipBlock(context.Background(), elementsPaths{})
+  if err != nil {
+    t.Fatalf("expected getblock failure to be reported in the result, got %v", err)
+  }
+  if tip.Hash != testElementsTipHash || tip.Height != 0 {
+    t.Fatalf("expected only the hash, got %+v", tip)
+  }
+  if tip.Error == nil || tip.Error.Code != "elements_getblock_failed" {
+    t.Fatalf("expected getblock error, got %+v", tip.Error)
+  }
+}
+
+func TestFetchElementsTipBlockHashFailure(t *testing.T) {
+  stubElementsCLI(t, func(ctx context.Context, paths elementsPaths, args ...string) (string, error) {
+    if args[0] == "getbestblockhash" {
+      return "error: " + strings.Repeat("x", 3), nil
+    }
+    t.Fatalf("getblock should not run without a hash")
+    return "", nil
+  })
+
+  if _, err := fetchElementsTipBlock(context.Background(), elementsPaths{}); err == nil {
+    t.Fatalf("expected error for an invalid hash")
+  }
+}
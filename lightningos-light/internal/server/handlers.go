@@ -1028,7 +1028,7 @@ func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
     return
   }
 
-  service := mapService(req.Service)
+  service := s.mapService(req.Service)
   if service == "" {
     writeError(w, http.StatusBadRequest, "unsupported service")
     return
@@ -1048,14 +1048,14 @@ func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
   writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
 }
 
-func mapService(name string) string {
+func (s *Server) mapService(name string) string {
   switch name {
   case "lnd":
     return "lnd"
   case "lightningos-manager":
     return "lightningos-manager"
   case "lightningos-elements", "elementsd":
-    return elementsServiceName
+    return s.elementsServiceUnit()
   case "lightningos-peerswapd", "peerswapd":
     return peerswapServiceName
   case "lightningos-psweb", "psweb":
@@ -1095,7 +1095,7 @@ func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
     }
   }
 
-  service = mapService(service)
+  service = s.mapService(service)
   if service == "" {
     writeError(w, http.StatusBadRequest, "unsupported service")
     return
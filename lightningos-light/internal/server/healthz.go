@@ -0,0 +1,109 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "os"
+  "strings"
+  "sync"
+  "time"
+
+  "lightningos-light/internal/system"
+)
+
+const (
+  healthzOK = "ok"
+  healthzUnhealthy = "unhealthy"
+  healthzNotConfigured = "not_configured"
+  healthzCheckTimeout = 4 * time.Second
+)
+
+type healthzComponent struct {
+  Status string `json:"status"`
+  Message string `json:"message,omitempty"`
+}
+
+type healthzResponse struct {
+  Status string `json:"status"`
+  Components map[string]healthzComponent `json:"components"`
+  Timestamp string `json:"timestamp"`
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+  checks := map[string]func(context.Context) healthzComponent{
+    "database": s.healthzDatabase,
+    "elements": s.healthzElements,
+    "terminal": s.healthzTerminal,
+  }
+
+  var mu sync.Mutex
+  var wg sync.WaitGroup
+  components := make(map[string]healthzComponent, len(checks))
+  for name, check := range checks {
+    wg.Add(1)
+    go func(name string, check func(context.Context) healthzComponent) {
+      defer wg.Done()
+      ctx, cancel := context.WithTimeout(r.Context(), healthzCheckTimeout)
+      defer cancel()
+      result := check(ctx)
+      mu.Lock()
+      components[name] = result
+      mu.Unlock()
+    }(name, check)
+  }
+  wg.Wait()
+
+  resp := healthzResponse{
+    Status: healthzOK,
+    Components: components,
+    Timestamp: time.Now().UTC().Format(time.RFC3339),
+  }
+  for _, component := range components {
+    if component.Status == healthzUnhealthy {
+      resp.Status = healthzUnhealthy
+    }
+  }
+
+  code := http.StatusOK
+  if resp.Status != healthzOK {
+    code = http.StatusServiceUnavailable
+  }
+  writeJSON(w, code, resp)
+}
+
+func (s *Server) healthzDatabase(ctx context.Context) healthzComponent {
+  if s.db == nil {
+    return healthzComponent{Status: healthzNotConfigured}
+  }
+  if err := s.db.Ping(ctx); err != nil {
+    return healthzComponent{Status: healthzUnhealthy, Message: "database ping failed"}
+  }
+  return healthzComponent{Status: healthzOK}
+}
+
+func (s *Server) healthzElements(ctx context.Context) healthzComponent {
+  status, ok := s.cachedElementsStatus()
+  if !ok {
+    status = s.loadElementsStatus(ctx)
+  }
+  if !status.Installed {
+    return healthzComponent{Status: healthzNotConfigured}
+  }
+  if status.Status != "running" {
+    return healthzComponent{Status: healthzUnhealthy, Message: "elements " + status.Status}
+  }
+  if !status.RPCOk {
+    return healthzComponent{Status: healthzUnhealthy, Message: "elements RPC unavailable"}
+  }
+  return healthzComponent{Status: healthzOK}
+}
+
+func (s *Server) healthzTerminal(ctx context.Context) healthzComponent {
+  if strings.TrimSpace(os.Getenv("TERMINAL_ENABLED")) != "1" {
+    return healthzComponent{Status: healthzNotConfigured}
+  }
+  if !system.SystemctlIsActive(ctx, terminalServiceName) {
+    return healthzComponent{Status: healthzUnhealthy, Message: "terminal service inactive"}
+  }
+  return healthzComponent{Status: healthzOK}
+}
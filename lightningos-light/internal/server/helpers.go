@@ -1,6 +1,9 @@
 package server
 
 import (
+  "bytes"
+  "crypto/sha256"
+  "encoding/hex"
   "encoding/json"
   "net/http"
   "strings"
@@ -14,6 +17,34 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
   }
 }
 
+func writeJSONWithETag(w http.ResponseWriter, r *http.Request, status int, payload any) {
+  var buf bytes.Buffer
+  if err := json.NewEncoder(&buf).Encode(payload); err != nil {
+    writeError(w, http.StatusInternalServerError, "failed to encode response")
+    return
+  }
+  sum := sha256.Sum256(buf.Bytes())
+  etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+  w.Header().Set("ETag", etag)
+  if status == http.StatusOK && etagMatches(r.Header.Get("If-None-Match"), etag) {
+    w.WriteHeader(http.StatusNotModified)
+    return
+  }
+  w.Header().Set("Content-Type", "application/json")
+  w.WriteHeader(status)
+  _, _ = w.Write(buf.Bytes())
+}
+
+func etagMatches(header string, etag string) bool {
+  for _, candidate := range strings.Split(header, ",") {
+    candidate = strings.TrimSpace(candidate)
+    if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+      return true
+    }
+  }
+  return false
+}
+
 func readJSON(r *http.Request, dst any) error {
   dec := json.NewDecoder(r.Body)
   dec.DisallowUnknownFields()
@@ -30,3 +61,16 @@ func normalizePaymentRequest(value string) string {
 func writeError(w http.ResponseWriter, status int, message string) {
   writeJSON(w, status, map[string]string{"error": message})
 }
+
+type apiError struct {
+  Code string `json:"code"`
+  Message string `json:"message"`
+}
+
+func writeErrorCode(w http.ResponseWriter, status int, code string, message string) {
+  writeJSON(w, status, errorCodePayload(code, message))
+}
+
+func errorCodePayload(code string, message string) map[string]apiError {
+  return map[string]apiError{"error": {Code: code, Message: message}}
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestWriteJSONWithETag(t *testing.T) {
+  payload := map[string]int{"days": 7}
+  req := httptest.NewRequest(http.MethodGet, "/api/reports/summary", nil)
+  rec := httptest.NewRecorder()
+  writeJSONWithETag(rec, req, http.StatusOK, payload)
+  if rec.Code != http.StatusOK {
+    t.Fatalf("expected 200, got %d", rec.Code)
+  }
+  etag := rec.Header().Get("ETag")
+  if etag == "" {
+    t.Fatalf("expected ETag header")
+  }
+
+  req = httptest.NewRequest(http.MethodGet, "/api/reports/summary", nil)
+  req.Header.Set("If-None-Match", `"other", W/`+etag)
+  rec = httptest.NewRecorder()
+  writeJSONWithETag(rec, req, http.StatusOK, payload)
+  if rec.Code != http.StatusNotModified {
+    t.Fatalf("expected 304, got %d", rec.Code)
+  }
+  if rec.Body.Len() != 0 {
+    t.Fatalf("expected empty body on 304")
+  }
+
+  req = httptest.NewRequest(http.MethodGet, "/api/reports/summary", nil)
+  req.Header.Set("If-None-Match", etag)
+  rec = httptest.NewRecorder()
+  writeJSONWithETag(rec, req, http.StatusOK, map[string]int{"days": 30})
+  if rec.Code != http.StatusOK {
+    t.Fatalf("expected 200 for changed body, got %d", rec.Code)
+  }
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+  "context"
+  "sync"
+  "time"
+)
+
+const idempotencyTTL = 5 * time.Minute
+
+type idempotencyCache struct {
+  mu sync.Mutex
+  entries map[string]*idempotencyEntry
+}
+
+type idempotencyEntry struct {
+  done chan struct{}
+  status int
+  payload any
+  expires time.Time
+}
+
+// begin returns the entry for key and whether the caller owns it. The owner
+// must call finish; other callers wait for the owner's result.
+func (c *idempotencyCache) begin(key string, now time.Time) (*idempotencyEntry, bool) {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  if c.entries == nil {
+    c.entries = map[string]*idempotencyEntry{}
+  }
+  for k, entry := range c.entries {
+    if !entry.expires.IsZero() && now.After(entry.expires) {
+      delete(c.entries, k)
+    }
+  }
+  if entry, ok := c.entries[key]; ok {
+    return entry, false
+  }
+  entry := &idempotencyEntry{done: make(chan struct{})}
+  c.entries[key] = entry
+  return entry, true
+}
+
+func (c *idempotencyCache) finish(entry *idempotencyEntry, status int, payload any, now time.Time) {
+  c.mu.Lock()
+  entry.status = status
+  entry.payload = payload
+  entry.expires = now.Add(idempotencyTTL)
+  c.mu.Unlock()
+  close(entry.done)
+}
+
+func (c *idempotencyCache) wait(ctx context.Context, entry *idempotencyEntry) (int, any, error) {
+  select {
+  case <-entry.done:
+  case <-ctx.Done():
+    return 0, nil, ctx.Err()
+  }
+  c.mu.Lock()
+  defer c.mu.Unlock()
+  return entry.status, entry.payload, nil
+}
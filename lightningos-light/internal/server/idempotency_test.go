@@ -0,0 +1,44 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "testing"
+  "time"
+)
+
+func TestIdempotencyCacheReplaysResult(t *testing.T) {
+  var cache idempotencyCache
+  now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+  entry, owner := cache.begin("abc", now)
+  if !owner {
+    t.Fatalf("expected first caller to own the key")
+  }
+  dup, owner := cache.begin("abc", now)
+  if owner {
+    t.Fatalf("expected duplicate not to own the key")
+  }
+
+  go cache.finish(entry, http.StatusOK, "restarted", now)
+  status, payload, err := cache.wait(context.Background(), dup)
+  if err != nil || status != http.StatusOK || payload != "restarted" {
+    t.Fatalf("unexpected replay: %d %v %v", status, payload, err)
+  }
+
+  if _, owner := cache.begin("abc", now.Add(idempotencyTTL+time.Second)); !owner {
+    t.Fatalf("expected key to expire after ttl")
+  }
+}
+
+func TestIdempotencyCacheWaitHonorsContext(t *testing.T) {
+  var cache idempotencyCache
+  cache.begin("pending", time.Now())
+  dup, _ := cache.begin("pending", time.Now())
+
+  ctx, cancel := context.WithCancel(context.Background())
+  cancel()
+  if _, _, err := cache.wait(ctx, dup); err == nil {
+    t.Fatalf("expected context error while owner is still running")
+  }
+}
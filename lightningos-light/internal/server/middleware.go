@@ -2,21 +2,42 @@ package server
 
 import (
   "bufio"
+  "bytes"
+  "compress/gzip"
+  "crypto/subtle"
+  "math"
   "net"
   "net/http"
+  "os"
+  "strconv"
+  "strings"
+  "sync"
   "time"
 )
 
+const gzipMinSize = 1024
+
+// requestLogger assigns each request an ID (kept from X-Request-ID when valid),
+// returns it in the response header, stores it in the request context for the
+// CLI helpers and writes one JSON line per request.
 func (s *Server) requestLogger() func(http.Handler) http.Handler {
   return func(next http.Handler) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
       start := time.Now()
+      id := requestIDFor(r.Header.Get(requestIDHeader))
+      w.Header().Set(requestIDHeader, id)
       ww := &responseWriter{ResponseWriter: w, status: 200}
 
-      next.ServeHTTP(ww, r)
+      next.ServeHTTP(ww, r.WithContext(withRequestID(r.Context(), id)))
 
-      duration := time.Since(start)
-      s.logger.Printf("method=%s path=%s status=%d duration_ms=%d", r.Method, r.URL.Path, ww.status, duration.Milliseconds())
+      writeJSONLog(requestLogEntry{
+        Time: logTime(start),
+        RequestID: id,
+        Method: r.Method,
+        Path: r.URL.Path,
+        Status: ww.status,
+        DurationMs: time.Since(start).Milliseconds(),
+      })
     })
   }
 }
@@ -43,3 +64,218 @@ func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
   }
   return nil, nil, http.ErrNotSupported
 }
+
+func gzipMiddleware(minSize int) func(http.Handler) http.Handler {
+  return func(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+      w.Header().Add("Vary", "Accept-Encoding")
+      if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+        next.ServeHTTP(w, r)
+        return
+      }
+      gw := &gzipResponseWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+      defer gw.Close()
+      next.ServeHTTP(gw, r)
+    })
+  }
+}
+
+func acceptsGzip(header string) bool {
+  for _, part := range strings.Split(header, ",") {
+    fields := strings.Split(part, ";")
+    if strings.TrimSpace(strings.ToLower(fields[0])) != "gzip" {
+      continue
+    }
+    for _, param := range fields[1:] {
+      param = strings.TrimSpace(param)
+      if !strings.HasPrefix(param, "q=") {
+        continue
+      }
+      if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil && q == 0 {
+        return false
+      }
+    }
+    return true
+  }
+  return false
+}
+
+type gzipResponseWriter struct {
+  http.ResponseWriter
+  minSize int
+  status int
+  buf bytes.Buffer
+  gz *gzip.Writer
+  passthrough bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+  w.status = status
+  if status == http.StatusNoContent || status == http.StatusNotModified {
+    w.commitPlain()
+  }
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+  if w.gz != nil {
+    return w.gz.Write(p)
+  }
+  if w.passthrough {
+    return w.ResponseWriter.Write(p)
+  }
+  w.buf.Write(p)
+  if w.buf.Len() >= w.minSize {
+    if err := w.commitGzip(); err != nil {
+      return 0, err
+    }
+  }
+  return len(p), nil
+}
+
+func (w *gzipResponseWriter) Flush() {
+  if w.gz != nil {
+    _ = w.gz.Flush()
+  } else if !w.passthrough {
+    w.commitPlain()
+  }
+  if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+    flusher.Flush()
+  }
+}
+
+func (w *gzipResponseWriter) Close() error {
+  if w.gz != nil {
+    return w.gz.Close()
+  }
+  if !w.passthrough {
+    w.commitPlain()
+  }
+  return nil
+}
+
+func (w *gzipResponseWriter) commitPlain() {
+  if w.passthrough || w.gz != nil {
+    return
+  }
+  w.passthrough = true
+  w.ResponseWriter.WriteHeader(w.status)
+  if w.buf.Len() > 0 {
+    _, _ = w.ResponseWriter.Write(w.buf.Bytes())
+    w.buf.Reset()
+  }
+}
+
+func (w *gzipResponseWriter) commitGzip() error {
+  header := w.Header()
+  if header.Get("Content-Encoding") != "" {
+    w.commitPlain()
+    return nil
+  }
+  header.Set("Content-Encoding", "gzip")
+  header.Del("Content-Length")
+  w.ResponseWriter.WriteHeader(w.status)
+  w.gz = gzip.NewWriter(w.ResponseWriter)
+  _, err := w.gz.Write(w.buf.Bytes())
+  w.buf.Reset()
+  return err
+}
+
+type rateLimiter struct {
+  mu sync.Mutex
+  rate float64
+  burst float64
+  buckets map[string]*tokenBucket
+  now func() time.Time
+}
+
+type tokenBucket struct {
+  tokens float64
+  last time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+  return &rateLimiter{
+    rate: rate,
+    burst: float64(burst),
+    buckets: map[string]*tokenBucket{},
+    now: time.Now,
+  }
+}
+
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+  l.mu.Lock()
+  defer l.mu.Unlock()
+  now := l.now()
+  bucket, ok := l.buckets[key]
+  if !ok {
+    bucket = &tokenBucket{tokens: l.burst, last: now}
+    l.buckets[key] = bucket
+  }
+  bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+  bucket.last = now
+  if bucket.tokens >= 1 {
+    bucket.tokens--
+    return true, 0
+  }
+  wait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second))
+  return false, wait
+}
+
+func (s *Server) statusRateLimiter() *rateLimiter {
+  return newRateLimiter(s.cfg.Server.RateLimit.RequestsPerSecond, s.cfg.Server.RateLimit.Burst)
+}
+
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+  if l.rate <= 0 || l.burst < 1 {
+    return next
+  }
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    ok, wait := l.allow(r.Method + " " + r.URL.Path)
+    if !ok {
+      seconds := int(math.Ceil(wait.Seconds()))
+      if seconds < 1 {
+        seconds = 1
+      }
+      w.Header().Set("Retry-After", strconv.Itoa(seconds))
+      writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+      return
+    }
+    next.ServeHTTP(w, r)
+  })
+}
+
+const controlTokenEnv = "CONTROL_API_TOKEN"
+
+// requireControlToken guards mutating control endpoints with the bearer token
+// from CONTROL_API_TOKEN. There is no loopback exemption: once a token is set
+// every POST/DELETE must present it. GET and other safe methods pass through.
+// The token is read per request so a rotated secrets.env applies after reload
+// without rebuilding the router; when it is unset the check is disabled.
+func requireControlToken(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+      next.ServeHTTP(w, r)
+      return
+    }
+    expected := strings.TrimSpace(os.Getenv(controlTokenEnv))
+    if expected == "" {
+      next.ServeHTTP(w, r)
+      return
+    }
+    if !validBearerToken(r.Header.Get("Authorization"), expected) {
+      w.Header().Set("WWW-Authenticate", `Bearer realm="lightningos"`)
+      writeErrorCode(w, http.StatusUnauthorized, "unauthorized", "missing or invalid bearer token")
+      return
+    }
+    next.ServeHTTP(w, r)
+  })
+}
+
+func validBearerToken(header string, expected string) bool {
+  scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+  if !ok || !strings.EqualFold(scheme, "Bearer") {
+    return false
+  }
+  token = strings.TrimSpace(token)
+  return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
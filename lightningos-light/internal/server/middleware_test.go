@@ -0,0 +1,210 @@
+package server
+
+import (
+  "bytes"
+  "compress/gzip"
+  "encoding/json"
+  "errors"
+  "io"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestGzipMiddlewareCompressesLargeResponses(t *testing.T) {
+  body := strings.Repeat("a", 4096)
+  handler := gzipMiddleware(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(http.StatusCreated)
+    _, _ = io.WriteString(w, body)
+  }))
+
+  req := httptest.NewRequest(http.MethodGet, "/api/reports/range", nil)
+  req.Header.Set("Accept-Encoding", "gzip, deflate")
+  rec := httptest.NewRecorder()
+  handler.ServeHTTP(rec, req)
+
+  if rec.Code != http.StatusCreated {
+    t.Fatalf("expected status 201, got %d", rec.Code)
+  }
+  if rec.Header().Get("Content-Encoding") != "gzip" {
+    t.Fatalf("expected gzip encoding")
+  }
+  reader, err := gzip.NewReader(rec.Body)
+  if err != nil {
+    t.Fatalf("invalid gzip body: %v", err)
+  }
+  decoded, _ := io.ReadAll(reader)
+  if string(decoded) != body {
+    t.Fatalf("decoded body mismatch")
+  }
+}
+
+func TestGzipMiddlewareSkipsSmallResponses(t *testing.T) {
+  handler := gzipMiddleware(1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    _, _ = io.WriteString(w, `{"ok":true}`)
+  }))
+
+  req := httptest.NewRequest(http.MethodGet, "/api/reports/summary", nil)
+  req.Header.Set("Accept-Encoding", "gzip")
+  rec := httptest.NewRecorder()
+  handler.ServeHTTP(rec, req)
+
+  if rec.Header().Get("Content-Encoding") != "" {
+    t.Fatalf("expected no encoding for small body")
+  }
+  if rec.Body.String() != `{"ok":true}` {
+    t.Fatalf("unexpected body %q", rec.Body.String())
+  }
+}
+
+func TestAcceptsGzip(t *testing.T) {
+  cases := map[string]bool{
+    "": false,
+    "gzip": true,
+    "deflate, gzip;q=0.5": true,
+    "gzip;q=0": false,
+    "br": false,
+  }
+  for header, want := range cases {
+    if got := acceptsGzip(header); got != want {
+      t.Fatalf("acceptsGzip(%q) = %v, want %v", header, got, want)
+    }
+  }
+}
+
+func TestRateLimiterPerEndpoint(t *testing.T) {
+  now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+  limiter := newRateLimiter(1, 2)
+  limiter.now = func() time.Time { return now }
+  handler := limiter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.WriteHeader(http.StatusOK)
+  }))
+
+  get := func(path string) *httptest.ResponseRecorder {
+    rec := httptest.NewRecorder()
+    handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+    return rec
+  }
+
+  for i := 0; i < 2; i++ {
+    if rec := get("/api/elements/status"); rec.Code != http.StatusOK {
+      t.Fatalf("request %d: expected 200, got %d", i, rec.Code)
+    }
+  }
+  rec := get("/api/elements/status")
+  if rec.Code != http.StatusTooManyRequests {
+    t.Fatalf("expected 429, got %d", rec.Code)
+  }
+  if rec.Header().Get("Retry-After") != "1" {
+    t.Fatalf("expected Retry-After 1, got %q", rec.Header().Get("Retry-After"))
+  }
+  if rec := get("/api/terminal/status"); rec.Code != http.StatusOK {
+    t.Fatalf("expected other endpoint to have its own bucket, got %d", rec.Code)
+  }
+
+  now = now.Add(time.Second)
+  if rec := get("/api/elements/status"); rec.Code != http.StatusOK {
+    t.Fatalf("expected refill after 1s, got %d", rec.Code)
+  }
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+  handler := newRateLimiter(0, 0).middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.WriteHeader(http.StatusOK)
+  }))
+  for i := 0; i < 20; i++ {
+    rec := httptest.NewRecorder()
+    handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/elements/status", nil))
+    if rec.Code != http.StatusOK {
+      t.Fatalf("request %d: expected disabled limiter to pass, got %d", i, rec.Code)
+    }
+  }
+}
+
+func TestRequireControlToken(t *testing.T) {
+  t.Setenv(controlTokenEnv, "s3cret")
+  handler := requireControlToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    w.WriteHeader(http.StatusNoContent)
+  }))
+
+  cases := []struct {
+    method string
+    auth string
+    want int
+  }{
+    {http.MethodPost, "", http.StatusUnauthorized},
+    {http.MethodPost, "Bearer wrong", http.StatusUnauthorized},
+    {http.MethodPost, "Basic s3cret", http.StatusUnauthorized},
+    {http.MethodPost, "Bearer s3cret", http.StatusNoContent},
+    {http.MethodDelete, "bearer s3cret", http.StatusNoContent},
+    {http.MethodGet, "", http.StatusNoContent},
+  }
+  for _, tc := range cases {
+    req := httptest.NewRequest(tc.method, "/api/elements/control", nil)
+    req.RemoteAddr = "127.0.0.1:5000"
+    if tc.auth != "" {
+      req.Header.Set("Authorization", tc.auth)
+    }
+    rec := httptest.NewRecorder()
+    handler.ServeHTTP(rec, req)
+    if rec.Code != tc.want {
+      t.Fatalf("%s %q: expected %d, got %d", tc.method, tc.auth, tc.want, rec.Code)
+    }
+  }
+
+  t.Setenv(controlTokenEnv, "")
+  rec := httptest.NewRecorder()
+  handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/elements/control", nil))
+  if rec.Code != http.StatusNoContent {
+    t.Fatalf("expected open access without a configured token, got %d", rec.Code)
+  }
+}
+
+func TestRequestLoggerAssignsRequestID(t *testing.T) {
+  var buf bytes.Buffer
+  setJSONLogOutput(&buf)
+  t.Cleanup(func() { setJSONLogOutput(nil) })
+
+  var seen string
+  s := &Server{}
+  handler := s.requestLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    seen = requestIDFromContext(r.Context())
+    logCommandError(r.Context(), "elements-cli getblockchaininfo", errors.New("boom"))
+    w.WriteHeader(http.StatusTeapot)
+  }))
+
+  req := httptest.NewRequest(http.MethodGet, "/api/elements/status", nil)
+  req.Header.Set(requestIDHeader, "proxy-id.1")
+  rec := httptest.NewRecorder()
+  handler.ServeHTTP(rec, req)
+  if seen != "proxy-id.1" || rec.Header().Get(requestIDHeader) != "proxy-id.1" {
+    t.Fatalf("expected incoming request id to be kept, got ctx=%q header=%q", seen, rec.Header().Get(requestIDHeader))
+  }
+
+  lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+  if len(lines) != 2 {
+    t.Fatalf("expected 2 log lines, got %q", buf.String())
+  }
+  var cmd commandLogEntry
+  if err := json.Unmarshal([]byte(lines[0]), &cmd); err != nil || cmd.RequestID != "proxy-id.1" || cmd.Error != "boom" {
+    t.Fatalf("unexpected command log %q (%v)", lines[0], err)
+  }
+  var entry requestLogEntry
+  if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+    t.Fatalf("request log is not json: %q", lines[1])
+  }
+  if entry.RequestID != "proxy-id.1" || entry.Status != http.StatusTeapot || entry.Path != "/api/elements/status" {
+    t.Fatalf("unexpected request log %+v", entry)
+  }
+
+  req = httptest.NewRequest(http.MethodGet, "/api/health", nil)
+  req.Header.Set(requestIDHeader, "bad id\n")
+  rec = httptest.NewRecorder()
+  handler.ServeHTTP(rec, req)
+  if got := rec.Header().Get(requestIDHeader); got == "" || got == "bad id\n" || got != seen {
+    t.Fatalf("expected a generated request id, got header=%q ctx=%q", got, seen)
+  }
+}
@@ -18,6 +18,7 @@ import (
   "time"
 
   "lightningos-light/internal/lndclient"
+  "lightningos-light/internal/reports"
   "lightningos-light/lnrpc"
 
   "github.com/jackc/pgx/v5"
@@ -90,6 +91,9 @@ type Notifier struct {
   lastCleanup time.Time
   backupSent map[string]time.Time
   pendingSent map[string]time.Time
+  // storeEvents mirrors reports.store_events: forwards and rebalances are
+  // kept in reports_events only when it is set.
+  storeEvents bool
 }
 
 func NewNotifier(db *pgxpool.Pool, lnd *lndclient.Client, logger *log.Logger) *Notifier {
@@ -602,13 +606,15 @@ order by occurred_at desc limit 1`, normalized).Scan(&payID, &payFee, &payFeeMsa
   if payFeeMsat == 0 && payFee != 0 {
     payFeeMsat = payFee * 1000
   }
-  if payFeeMsat == 0 {
+  var payChannel uint64
+  if payFeeMsat == 0 || n.storeEvents {
     feeCtx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
     if pay, err := n.lookupPaymentByHash(feeCtx, normalized); err == nil && pay != nil {
-      if feeMsat := paymentFeeMsat(pay); feeMsat != 0 {
+      if feeMsat := paymentFeeMsat(pay); payFeeMsat == 0 && feeMsat != 0 {
         payFeeMsat = feeMsat
         payFee = feeMsat / 1000
       }
+      payChannel = rebalanceOutgoingChannel(pay)
     }
     cancel()
   }
@@ -640,11 +646,12 @@ set type='rebalance',
   fee_sat=$3,
   fee_msat=$4,
   memo=$5,
-  occurred_at=$6
+  occurred_at=$6,
+  channel_id=coalesce(channel_id, $7)
 where id=$1
 returning id, occurred_at, type, action, direction, status, amount_sat, fee_sat,
   fee_msat, peer_pubkey, peer_alias, channel_id, channel_point, txid, payment_hash, memo
-`, payID, invAmount, payFee, payFeeMsat, memoValue, invAt)
+`, payID, invAmount, payFee, payFeeMsat, memoValue, invAt, nullableInt(int64(payChannel)))
   updated, err := scanNotification(row)
   if err != nil {
     return
@@ -659,9 +666,37 @@ returning id, occurred_at, type, action, direction, status, amount_sat, fee_sat,
     return
   }
 
+  n.recordRebalanceEvent(ctx, fmt.Sprintf("payment:%s", normalized), updated)
   n.broadcast(updated)
 }
 
+func (n *Notifier) recordRebalanceEvent(ctx context.Context, key string, evt Notification) {
+  feeMsat := evt.FeeMsat
+  if feeMsat == 0 && evt.FeeSat != 0 {
+    feeMsat = evt.FeeSat * 1000
+  }
+  event := reports.Event{
+    Key: key,
+    OccurredAt: evt.OccurredAt,
+    Type: reports.EventRebalance,
+    FeeMsat: feeMsat,
+    AmountMsat: evt.AmountSat * 1000,
+    ChanIDOut: uint64(evt.ChannelID),
+  }
+  reports.RecordToday(event)
+  err := n.insertReportEvent(ctx, event)
+  if err != nil {
+    n.logger.Printf("notifications: reports event insert failed: %v", err)
+  }
+}
+
+func (n *Notifier) insertReportEvent(ctx context.Context, event reports.Event) error {
+  if !n.storeEvents {
+    return nil
+  }
+  return reports.InsertEvent(ctx, n.db, event)
+}
+
 func (n *Notifier) cleanupIfNeeded() {
   n.mu.Lock()
   next := n.lastCleanup.Add(notificationCleanupInterval)
@@ -777,6 +812,7 @@ func (n *Notifier) runInvoices() {
         if n.isSelfPayment(ctx, pay.PaymentRequest, pay) {
           rebalanceEvt := n.rebalanceEvent(ctx, pay, occurredAt)
           if _, err := n.upsertNotification(ctx, fmt.Sprintf("payment:%s", hash), rebalanceEvt); err == nil {
+            n.recordRebalanceEvent(ctx, fmt.Sprintf("payment:%s", hash), rebalanceEvt)
             _ = n.setCursor(ctx, "invoice_settle_index", strconv.FormatUint(settleIndex, 10))
           }
           cancel()
@@ -896,6 +932,7 @@ func (n *Notifier) runPayments() {
     }
     if _, err := n.upsertNotification(ctx, fmt.Sprintf("payment:%s", paymentHash), evt); err == nil {
       if isRebalance {
+        n.recordRebalanceEvent(ctx, fmt.Sprintf("payment:%s", paymentHash), evt)
         _ = n.removeRebalanceInvoice(ctx, paymentHash)
       } else {
         n.reconcileRebalance(ctx, paymentHash)
@@ -1511,6 +1548,14 @@ func (n *Notifier) runForwards() {
         amount := int64(fwd.AmtOut)
         fee := int64(fwd.Fee)
         feeMsat := int64(fwd.FeeMsat)
+        eventFeeMsat := feeMsat
+        if eventFeeMsat == 0 {
+          eventFeeMsat = fee * 1000
+        }
+        amountMsat := int64(fwd.AmtOutMsat)
+        if amountMsat == 0 {
+          amountMsat = amount * 1000
+        }
         evt := Notification{
           OccurredAt: occurredAt,
           Type: "forward",
@@ -1526,6 +1571,19 @@ func (n *Notifier) runForwards() {
         eventKey := fmt.Sprintf("forward:%d:%d:%d", fwd.IncomingHtlcId, fwd.OutgoingHtlcId, tsKey)
         ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
         _, _ = n.upsertNotification(ctx, eventKey, evt)
+        event := reports.Event{
+          Key: eventKey,
+          OccurredAt: occurredAt,
+          Type: reports.EventForward,
+          FeeMsat: eventFeeMsat,
+          AmountMsat: amountMsat,
+          ChanIDIn: fwd.ChanIdIn,
+          ChanIDOut: fwd.ChanIdOut,
+        }
+        reports.RecordToday(event)
+        if err := n.insertReportEvent(ctx, event); err != nil {
+          n.logger.Printf("notifications: reports event insert failed: %v", err)
+        }
         cancel()
       }
 
@@ -1702,6 +1760,7 @@ func (n *Notifier) rebalanceEvent(ctx context.Context, pay *lnrpc.Payment, occur
     evt.FeeMsat = feeMsat
     evt.FeeSat = feeMsat / 1000
   }
+  evt.ChannelID = int64(rebalanceOutgoingChannel(pay))
   if info := n.rebalanceRouteInfo(ctx, pay); info != nil {
     if info.PeerLabel != "" {
       evt.PeerAlias = info.PeerLabel
@@ -1713,6 +1772,16 @@ func (n *Notifier) rebalanceEvent(ctx context.Context, pay *lnrpc.Payment, occur
   return evt
 }
 
+// rebalanceOutgoingChannel is the channel a rebalance left through, the first
+// hop of its route. Channel reports charge the rebalance fee to it.
+func rebalanceOutgoingChannel(pay *lnrpc.Payment) uint64 {
+  route := rebalanceRouteFromPayment(pay)
+  if route == nil || len(route.Hops) == 0 || route.Hops[0] == nil {
+    return 0
+  }
+  return route.Hops[0].ChanId
+}
+
 func rebalanceRouteFromPayment(pay *lnrpc.Payment) *lnrpc.Route {
   if pay == nil {
     return nil
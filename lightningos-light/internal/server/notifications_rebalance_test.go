@@ -0,0 +1,23 @@
+package server
+
+import (
+  "testing"
+
+  "lightningos-light/lnrpc"
+)
+
+func TestRebalanceOutgoingChannel(t *testing.T) {
+  pay := &lnrpc.Payment{Htlcs: []*lnrpc.HTLCAttempt{
+    {Status: lnrpc.HTLCAttempt_FAILED, Route: &lnrpc.Route{Hops: []*lnrpc.Hop{{ChanId: 11}, {ChanId: 12}}}},
+    {Status: lnrpc.HTLCAttempt_SUCCEEDED, Route: &lnrpc.Route{Hops: []*lnrpc.Hop{{ChanId: 21}, {ChanId: 22}}}},
+  }}
+  if got := rebalanceOutgoingChannel(pay); got != 21 {
+    t.Fatalf("expected the first hop of the settled attempt, got %d", got)
+  }
+  if got := rebalanceOutgoingChannel(&lnrpc.Payment{}); got != 0 {
+    t.Fatalf("expected 0 without a route, got %d", got)
+  }
+  if got := rebalanceOutgoingChannel(nil); got != 0 {
+    t.Fatalf("expected 0 for a nil payment, got %d", got)
+  }
+}
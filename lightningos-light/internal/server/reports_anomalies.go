@@ -0,0 +1,91 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+type reportAnomaliesResponse struct {
+  SchemaVersion int `json:"schema_version"`
+  Range string `json:"range"`
+  Timezone string `json:"timezone"`
+  Anomalies []reportAnomaly `json:"anomalies"`
+}
+
+type reportAnomaly struct {
+  Date string `json:"date"`
+  Code string `json:"code"`
+  Reason string `json:"reason"`
+}
+
+func (s *Server) handleReportsAnomalies(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+
+  var items []reports.Row
+  key := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("range")))
+  fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+  toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+  if fromStr != "" || toStr != "" {
+    if fromStr == "" || toStr == "" {
+      writeError(w, http.StatusBadRequest, "from and to are required")
+      return
+    }
+    startDate, endDate, err := parseReportsCustomRange(svc, fromStr, toStr)
+    if err != nil {
+      writeError(w, http.StatusBadRequest, err.Error())
+      return
+    }
+    key = "custom"
+    items, err = svc.CustomRange(ctx, startDate, endDate)
+    if err != nil {
+      writeReportsLoadError(w, err, "failed to load reports")
+      return
+    }
+  } else {
+    if key == "" {
+      key = reports.RangeMonth
+    }
+    rows, _, err := svc.Range(ctx, key, time.Now(), svc.Location())
+    if err != nil {
+      if strings.Contains(err.Error(), "invalid range") {
+        writeError(w, http.StatusBadRequest, err.Error())
+      } else {
+        writeReportsLoadError(w, err, "failed to load reports")
+      }
+      return
+    }
+    items = rows
+  }
+
+  detected := svc.DetectAnomalies(items)
+  anomalies := make([]reportAnomaly, 0, len(detected))
+  for _, anomaly := range detected {
+    anomalies = append(anomalies, reportAnomaly{
+      Date: anomaly.Date.Format("2006-01-02"),
+      Code: string(anomaly.Code),
+      Reason: anomaly.Reason,
+    })
+  }
+
+  writeReportJSON(w, r, reportAnomaliesResponse{
+    SchemaVersion: reports.SchemaVersion,
+    Range: key,
+    Timezone: reportsTimezoneLabel(svc),
+    Anomalies: anomalies,
+  })
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+  "context"
+  "fmt"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+type reportRebalanceBudgetResponse struct {
+  Exceeded bool `json:"exceeded"`
+  WindowDays int `json:"window_days"`
+  BudgetSat int64 `json:"budget_sats"`
+  ActualSat int64 `json:"actual_sats"`
+  OverSat int64 `json:"over_sats"`
+}
+
+func (s *Server) handleReportsRebalanceBudget(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  windowDays := 1
+  if raw := strings.TrimSpace(r.URL.Query().Get("window_days")); raw != "" {
+    parsed, err := strconv.Atoi(raw)
+    if err != nil || parsed < 1 || parsed > reports.MaxRebalanceBudgetWindowDays {
+      writeError(w, http.StatusBadRequest, fmt.Sprintf("window_days must be between 1 and %d", reports.MaxRebalanceBudgetWindowDays))
+      return
+    }
+    windowDays = parsed
+  }
+  budget, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("budget_sats")), 10, 64)
+  if err != nil || budget < 0 {
+    writeError(w, http.StatusBadRequest, "budget_sats must be a non-negative integer")
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+  today, err := svc.Today(svc.QueryContext(ctx), time.Now())
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to check rebalance budget")
+    return
+  }
+  exceeded, delta, err := reports.CheckRebalanceBudget(svc.QueryContext(ctx), s.reportsReadPool(), svc.Location(), windowDays, budget, today.Metrics.RebalanceFeeCostSat)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to check rebalance budget")
+    return
+  }
+
+  resp := reportRebalanceBudgetResponse{
+    Exceeded: exceeded,
+    WindowDays: windowDays,
+    BudgetSat: budget,
+    ActualSat: budget + delta,
+  }
+  if exceeded {
+    resp.OverSat = delta
+  }
+  writeReportJSON(w, r, resp)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+const defaultTopChannels = 10
+
+type reportChannelTotal struct {
+  ChannelID string `json:"channel_id"`
+  Days int64 `json:"days"`
+  ForwardFeeRevenueMsat int64 `json:"forward_fee_revenue_msat"`
+  RebalanceFeeCostMsat int64 `json:"rebalance_fee_cost_msat"`
+  RoutedVolumeMsat int64 `json:"routed_volume_msat"`
+  ForwardCount int64 `json:"forward_count"`
+  RebalanceCount int64 `json:"rebalance_count"`
+}
+
+func (s *Server) handleReportsTopChannels(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+  if !svc.Options().StoreChannels {
+    writeErrorCode(w, http.StatusNotFound, "channel_metrics_disabled", "per-channel metrics are disabled (reports.store_channels)")
+    return
+  }
+
+  fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+  toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+  if fromStr == "" || toStr == "" {
+    writeError(w, http.StatusBadRequest, "from and to are required")
+    return
+  }
+  startDate, endDate, err := parseReportsCustomRange(svc, fromStr, toStr)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+  limit := defaultTopChannels
+  if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+    parsed, err := strconv.Atoi(raw)
+    if err != nil || parsed <= 0 {
+      writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+      return
+    }
+    limit = parsed
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+  defer cancel()
+  found, err := reports.TopChannels(svc.QueryContext(ctx), s.reportsReadPool(), startDate, endDate, limit)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load channel reports")
+    return
+  }
+
+  items := make([]reportChannelTotal, 0, len(found))
+  for _, c := range found {
+    items = append(items, reportChannelTotal{
+      ChannelID: strconv.FormatUint(c.ChannelID, 10),
+      Days: c.Days,
+      ForwardFeeRevenueMsat: c.ForwardFeeRevenueMsat,
+      RebalanceFeeCostMsat: c.RebalanceFeeCostMsat,
+      RoutedVolumeMsat: c.RoutedVolumeMsat,
+      ForwardCount: c.ForwardCount,
+      RebalanceCount: c.RebalanceCount,
+    })
+  }
+  writeReportJSON(w, r, map[string]any{
+    "from": startDate.Format("2006-01-02"),
+    "to": endDate.Format("2006-01-02"),
+    "channels": items,
+  })
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+  "context"
+  "math"
+  "net/http"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+type reportCompareResponse struct {
+  SchemaVersion int `json:"schema_version"`
+  Timezone string `json:"timezone"`
+  A reportCompareSide `json:"a"`
+  B reportCompareSide `json:"b"`
+  Delta reportCompareDelta `json:"delta"`
+  PercentChange reportComparePercent `json:"percent_change"`
+}
+
+type reportCompareSide struct {
+  Start string `json:"start"`
+  End string `json:"end"`
+  Summary reportSummaryResponse `json:"summary"`
+}
+
+type reportCompareDelta struct {
+  ForwardFeeRevenueSat float64 `json:"forward_fee_revenue_sats"`
+  RebalanceFeeCostSat float64 `json:"rebalance_fee_cost_sats"`
+  NetRoutingProfitSat float64 `json:"net_routing_profit_sats"`
+  ForwardCount int64 `json:"forward_count"`
+  RebalanceCount int64 `json:"rebalance_count"`
+  RoutedVolumeSat float64 `json:"routed_volume_sats"`
+}
+
+type reportComparePercent struct {
+  ForwardFeeRevenueSat *float64 `json:"forward_fee_revenue_sats"`
+  RebalanceFeeCostSat *float64 `json:"rebalance_fee_cost_sats"`
+  NetRoutingProfitSat *float64 `json:"net_routing_profit_sats"`
+  ForwardCount *float64 `json:"forward_count"`
+  RebalanceCount *float64 `json:"rebalance_count"`
+  RoutedVolumeSat *float64 `json:"routed_volume_sats"`
+}
+
+func (s *Server) handleReportsCompare(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  query := r.URL.Query()
+  aStartStr := strings.TrimSpace(query.Get("a_start"))
+  aEndStr := strings.TrimSpace(query.Get("a_end"))
+  bStartStr := strings.TrimSpace(query.Get("b_start"))
+  bEndStr := strings.TrimSpace(query.Get("b_end"))
+  if aStartStr == "" || aEndStr == "" || bStartStr == "" || bEndStr == "" {
+    writeError(w, http.StatusBadRequest, "a_start, a_end, b_start and b_end are required")
+    return
+  }
+
+  aStart, aEnd, err := parseReportsCustomRange(svc, aStartStr, aEndStr)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, "range a: "+err.Error())
+    return
+  }
+  bStart, bEnd, err := parseReportsCustomRange(svc, bStartStr, bEndStr)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, "range b: "+err.Error())
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+
+  summaryA, err := svc.CustomSummary(ctx, aStart, aEnd)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load report summary")
+    return
+  }
+  summaryB, err := svc.CustomSummary(ctx, bStart, bEnd)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load report summary")
+    return
+  }
+
+  a := metricsPayload(summaryA.Totals)
+  b := metricsPayload(summaryB.Totals)
+  writeReportJSON(w, r, reportCompareResponse{
+    SchemaVersion: reports.SchemaVersion,
+    Timezone: reportsTimezoneLabel(svc),
+    A: reportCompareSide{
+      Start: aStart.Format("2006-01-02"),
+      End: aEnd.Format("2006-01-02"),
+      Summary: summaryResponse("custom", reportsTimezoneLabel(svc), summaryA),
+    },
+    B: reportCompareSide{
+      Start: bStart.Format("2006-01-02"),
+      End: bEnd.Format("2006-01-02"),
+      Summary: summaryResponse("custom", reportsTimezoneLabel(svc), summaryB),
+    },
+    Delta: reportCompareDelta{
+      ForwardFeeRevenueSat: b.ForwardFeeRevenueSat - a.ForwardFeeRevenueSat,
+      RebalanceFeeCostSat: b.RebalanceFeeCostSat - a.RebalanceFeeCostSat,
+      NetRoutingProfitSat: b.NetRoutingProfitSat - a.NetRoutingProfitSat,
+      ForwardCount: b.ForwardCount - a.ForwardCount,
+      RebalanceCount: b.RebalanceCount - a.RebalanceCount,
+      RoutedVolumeSat: b.RoutedVolumeSat - a.RoutedVolumeSat,
+    },
+    PercentChange: reportComparePercent{
+      ForwardFeeRevenueSat: percentChange(a.ForwardFeeRevenueSat, b.ForwardFeeRevenueSat),
+      RebalanceFeeCostSat: percentChange(a.RebalanceFeeCostSat, b.RebalanceFeeCostSat),
+      NetRoutingProfitSat: percentChange(a.NetRoutingProfitSat, b.NetRoutingProfitSat),
+      ForwardCount: percentChange(float64(a.ForwardCount), float64(b.ForwardCount)),
+      RebalanceCount: percentChange(float64(a.RebalanceCount), float64(b.RebalanceCount)),
+      RoutedVolumeSat: percentChange(a.RoutedVolumeSat, b.RoutedVolumeSat),
+    },
+  })
+}
+
+func percentChange(from float64, to float64) *float64 {
+  if from == 0 {
+    return nil
+  }
+  change := (to - from) / math.Abs(from) * 100
+  return &change
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+  "encoding/csv"
+  "net/http"
+  "strconv"
+  "strings"
+)
+
+const (
+  reportsFormatJSON = "json"
+  reportsFormatCSV = "csv"
+  reportsFormatTSV = "tsv"
+)
+
+// Same order as the reportSeriesItem JSON fields.
+var reportsExportColumns = []string{
+  "date",
+  "forward_fee_revenue_sats",
+  "rebalance_fee_cost_sats",
+  "net_routing_profit_sats",
+  "forward_count",
+  "rebalance_count",
+  "routed_volume_sats",
+  "onchain_balance_sats",
+  "lightning_balance_sats",
+  "total_balance_sats",
+  "onchain_ratio",
+}
+
+// writeReportSeries sends a daily series as JSON, CSV or TSV depending on the
+// Accept header. JSON is the default when no supported type is requested.
+func writeReportSeries(w http.ResponseWriter, r *http.Request, resp reportSeriesResponse) {
+  w.Header().Add("Vary", "Accept")
+  format := reportsResponseFormat(r.Header.Get("Accept"))
+  if format == reportsFormatJSON {
+    writeReportJSON(w, r, resp)
+    return
+  }
+  writeReportSeriesDelimited(w, resp, format)
+}
+
+// writeReportSeriesDelimited writes resp as a CSV or TSV attachment.
+func writeReportSeriesDelimited(w http.ResponseWriter, resp reportSeriesResponse, format string) {
+  contentType := "text/csv; charset=utf-8"
+  comma := ','
+  if format == reportsFormatTSV {
+    contentType = "text/tab-separated-values; charset=utf-8"
+    comma = '\t'
+  }
+  w.Header().Set("Content-Type", contentType)
+  w.Header().Set("Content-Disposition", `attachment; filename="reports-`+resp.Range+`.`+format+`"`)
+  w.WriteHeader(http.StatusOK)
+
+  writer := csv.NewWriter(w)
+  writer.Comma = comma
+  _ = writer.Write(reportsExportColumns)
+  for _, item := range resp.Series {
+    _ = writer.Write(reportsExportRecord(item))
+  }
+  writer.Flush()
+}
+
+func reportsResponseFormat(accept string) string {
+  format := reportsFormatJSON
+  bestQ := 0.0
+  for _, part := range strings.Split(accept, ",") {
+    fields := strings.Split(part, ";")
+    mediaType := strings.ToLower(strings.TrimSpace(fields[0]))
+    q := 1.0
+    for _, param := range fields[1:] {
+      key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+      if ok && strings.TrimSpace(key) == "q" {
+        if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+          q = parsed
+        }
+      }
+    }
+    candidate := ""
+    switch mediaType {
+    case "application/json":
+      candidate = reportsFormatJSON
+    case "text/csv":
+      candidate = reportsFormatCSV
+    case "text/tab-separated-values":
+      candidate = reportsFormatTSV
+    }
+    if candidate != "" && q > bestQ {
+      format = candidate
+      bestQ = q
+    }
+  }
+  return format
+}
+
+func reportsExportRecord(item reportSeriesItem) []string {
+  return []string{
+    item.Date,
+    formatReportFloat(item.ForwardFeeRevenueSat),
+    formatReportFloat(item.RebalanceFeeCostSat),
+    formatReportFloat(item.NetRoutingProfitSat),
+    strconv.FormatInt(item.ForwardCount, 10),
+    strconv.FormatInt(item.RebalanceCount, 10),
+    formatReportFloat(item.RoutedVolumeSat),
+    formatReportInt(item.OnchainBalanceSat),
+    formatReportInt(item.LightningBalanceSat),
+    formatReportInt(item.TotalBalanceSat),
+    formatReportRatio(item.OnchainRatio),
+  }
+}
+
+func formatReportFloat(value float64) string {
+  return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatReportInt(value *int64) string {
+  if value == nil {
+    return ""
+  }
+  return strconv.FormatInt(*value, 10)
+}
+
+func formatReportRatio(value *float64) string {
+  if value == nil {
+    return ""
+  }
+  return formatReportFloat(*value)
+}
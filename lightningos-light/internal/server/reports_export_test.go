@@ -0,0 +1,69 @@
+package server
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func TestReportsResponseFormat(t *testing.T) {
+  cases := map[string]string{
+    "": reportsFormatJSON,
+    "text/html,application/xhtml+xml,*/*;q=0.8": reportsFormatJSON,
+    "text/csv": reportsFormatCSV,
+    "text/tab-separated-values": reportsFormatTSV,
+    "application/json;q=0.5, text/csv": reportsFormatCSV,
+    "text/csv;q=0.2, application/json": reportsFormatJSON,
+    "text/csv;q=0": reportsFormatJSON,
+  }
+  for accept, want := range cases {
+    if got := reportsResponseFormat(accept); got != want {
+      t.Fatalf("Accept %q: expected %s, got %s", accept, want, got)
+    }
+  }
+}
+
+func TestWriteReportSeriesColumnsMatchJSON(t *testing.T) {
+  balance := int64(250000)
+  resp := reportSeriesResponse{
+    Range: "custom",
+    Series: []reportSeriesItem{{Date: "2026-03-01", ForwardFeeRevenueSat: 1.5, ForwardCount: 2, TotalBalanceSat: &balance}},
+  }
+
+  req := httptest.NewRequest(http.MethodGet, "/api/reports/custom", nil)
+  req.Header.Set("Accept", "text/tab-separated-values")
+  rec := httptest.NewRecorder()
+  writeReportSeries(rec, req, resp)
+  if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/tab-separated-values") {
+    t.Fatalf("unexpected content type %q", ct)
+  }
+  lines := strings.Split(strings.TrimRight(rec.Body.String(), "\n"), "\n")
+  if len(lines) != 2 {
+    t.Fatalf("expected header and one row, got %q", rec.Body.String())
+  }
+  if lines[1] != "2026-03-01\t1.5\t0\t0\t2\t0\t0\t\t\t250000\t" {
+    t.Fatalf("unexpected row %q", lines[1])
+  }
+
+  encoded, err := json.Marshal(resp.Series[0])
+  if err != nil {
+    t.Fatalf("marshal: %v", err)
+  }
+  jsonOrder := []string{}
+  dec := json.NewDecoder(strings.NewReader(string(encoded)))
+  _, _ = dec.Token()
+  for dec.More() {
+    key, _ := dec.Token()
+    jsonOrder = append(jsonOrder, key.(string))
+    var skip json.RawMessage
+    _ = dec.Decode(&skip)
+  }
+  if strings.Join(jsonOrder[:len(reportsExportColumns)], ",") != strings.Join(reportsExportColumns, ",") {
+    t.Fatalf("column order %v does not match JSON order %v", reportsExportColumns, jsonOrder)
+  }
+  if got := strings.Split(lines[0], "\t"); len(got) != len(reportsExportColumns) {
+    t.Fatalf("unexpected header %q", lines[0])
+  }
+}
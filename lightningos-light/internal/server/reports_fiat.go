@@ -0,0 +1,90 @@
+package server
+
+import (
+  "context"
+  "errors"
+  "net/http"
+  "strings"
+
+  "lightningos-light/internal/reports"
+)
+
+type reportFiatValues struct {
+  ForwardFeeRevenue float64 `json:"forward_fee_revenue"`
+  RebalanceFeeCost float64 `json:"rebalance_fee_cost"`
+  NetRoutingProfit float64 `json:"net_routing_profit"`
+  RoutedVolume float64 `json:"routed_volume"`
+}
+
+type reportFiatSummary struct {
+  Currency string `json:"currency"`
+  Totals reportFiatValues `json:"totals"`
+  DaysWithRate int64 `json:"days_with_rate"`
+  DaysMissingRate int64 `json:"days_missing_rate"`
+}
+
+func parseReportsCurrency(r *http.Request) (string, error) {
+  raw := strings.TrimSpace(r.URL.Query().Get("currency"))
+  if raw == "" {
+    return "", nil
+  }
+  currency, err := reports.NormalizeCurrency(raw)
+  if err != nil {
+    return "", errors.New("currency must be a 3-letter code")
+  }
+  return currency, nil
+}
+
+func loadReportsPriceTable(ctx context.Context, svc *reports.Service, currency string, items []reports.Row) (reports.PriceTable, error) {
+  if len(items) == 0 {
+    return reports.PriceTable{}, nil
+  }
+  start, end := items[0].ReportDate, items[len(items)-1].ReportDate
+  if end.Before(start) {
+    start, end = end, start
+  }
+  return svc.PriceTable(ctx, currency, start, end)
+}
+
+func applyFiatSeries(series []reportSeriesItem, items []reports.Row, currency string, provider reports.PriceProvider) {
+  for i := range series {
+    if i >= len(items) {
+      break
+    }
+    series[i].Currency = currency
+    fiat, rate, ok := reports.ConvertToFiat(items[i], provider)
+    if !ok {
+      series[i].FiatRateMissing = true
+      continue
+    }
+    values := fiatValuesPayload(fiat)
+    series[i].FiatRate = &rate
+    series[i].Fiat = &values
+  }
+}
+
+func fiatSummary(items []reports.Row, currency string, provider reports.PriceProvider) *reportFiatSummary {
+  summary := &reportFiatSummary{Currency: currency}
+  for _, item := range items {
+    fiat, _, ok := reports.ConvertToFiat(item, provider)
+    if !ok {
+      summary.DaysMissingRate++
+      continue
+    }
+    summary.DaysWithRate++
+    summary.Totals.ForwardFeeRevenue += fiat.ForwardFeeRevenue
+    summary.Totals.RebalanceFeeCost += fiat.RebalanceFeeCost
+    summary.Totals.NetRoutingProfit += fiat.NetRoutingProfit
+    summary.Totals.RoutedVolume += fiat.RoutedVolume
+  }
+  return summary
+}
+
+func fiatValuesPayload(fiat reports.FiatMetrics) reportFiatValues {
+  return reportFiatValues{
+    ForwardFeeRevenue: fiat.ForwardFeeRevenue,
+    RebalanceFeeCost: fiat.RebalanceFeeCost,
+    NetRoutingProfit: fiat.NetRoutingProfit,
+    RoutedVolume: fiat.RoutedVolume,
+  }
+}
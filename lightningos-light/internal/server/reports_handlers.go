@@ -2,6 +2,7 @@ package server
 
 import (
   "context"
+  "errors"
   "fmt"
   "net/http"
   "os"
@@ -12,7 +13,11 @@ import (
   "lightningos-light/internal/reports"
 )
 
-const reportsTimezoneLabel = "system_local"
+// reportsTimezoneLabel names the zone report days are cut in: the
+// reports.timezone name, or Local when it is unset.
+func reportsTimezoneLabel(svc *reports.Service) string {
+  return svc.Location().String()
+}
 
 func (s *Server) handleReportsRange(w http.ResponseWriter, r *http.Request) {
   svc, errMsg := s.reportsService()
@@ -29,24 +34,40 @@ func (s *Server) handleReportsRange(w http.ResponseWriter, r *http.Request) {
   if key == "" {
     key = reports.RangeD1
   }
+  currency, err := parseReportsCurrency(r)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
 
   ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
   defer cancel()
 
-  items, _, err := svc.Range(ctx, key, time.Now(), time.Local)
+  items, _, err := svc.Range(ctx, key, time.Now(), svc.Location())
   if err != nil {
     if strings.Contains(err.Error(), "invalid range") {
       writeError(w, http.StatusBadRequest, err.Error())
     } else {
-      writeError(w, http.StatusInternalServerError, "failed to load reports")
+      writeReportsLoadError(w, err, "failed to load reports")
     }
     return
   }
 
-  writeJSON(w, http.StatusOK, reportSeriesResponse{
+  series := mapSeries(items)
+  if currency != "" {
+    prices, err := loadReportsPriceTable(ctx, svc, currency, items)
+    if err != nil {
+      writeError(w, http.StatusInternalServerError, "failed to load fiat rates")
+      return
+    }
+    applyFiatSeries(series, items, currency, prices)
+  }
+
+  writeReportSeries(w, r, reportSeriesResponse{
+    SchemaVersion: reports.SchemaVersion,
     Range: key,
-    Timezone: reportsTimezoneLabel,
-    Series: mapSeries(items),
+    Timezone: reportsTimezoneLabel(svc),
+    Series: series,
   })
 }
 
@@ -68,38 +89,46 @@ func (s *Server) handleReportsCustom(w http.ResponseWriter, r *http.Request) {
     return
   }
 
-  startDate, err := reports.ParseDate(fromStr, time.Local)
+  startDate, endDate, err := parseReportsCustomRange(svc, fromStr, toStr)
   if err != nil {
-    writeError(w, http.StatusBadRequest, "from must be YYYY-MM-DD")
+    writeError(w, http.StatusBadRequest, err.Error())
     return
   }
-  endDate, err := reports.ParseDate(toStr, time.Local)
+  currency, err := parseReportsCurrency(r)
   if err != nil {
-    writeError(w, http.StatusBadRequest, "to must be YYYY-MM-DD")
+    writeError(w, http.StatusBadRequest, err.Error())
     return
   }
-  if err := reports.ValidateCustomRange(startDate, endDate); err != nil {
-    if strings.Contains(err.Error(), "large") {
-      writeError(w, http.StatusBadRequest, fmt.Sprintf("range too large (max %d days)", reports.CustomRangeDaysLimit()))
-    } else {
-      writeError(w, http.StatusBadRequest, "invalid range")
-    }
+  order, err := parseReportsOrder(r)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
     return
   }
 
   ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
   defer cancel()
 
-  items, err := svc.CustomRange(ctx, startDate, endDate)
+  items, err := svc.CustomRangeOrdered(ctx, startDate, endDate, order)
   if err != nil {
-    writeError(w, http.StatusInternalServerError, "failed to load reports")
+    writeReportsLoadError(w, err, "failed to load reports")
     return
   }
 
-  writeJSON(w, http.StatusOK, reportSeriesResponse{
+  series := mapSeries(items)
+  if currency != "" {
+    prices, err := loadReportsPriceTable(ctx, svc, currency, items)
+    if err != nil {
+      writeError(w, http.StatusInternalServerError, "failed to load fiat rates")
+      return
+    }
+    applyFiatSeries(series, items, currency, prices)
+  }
+
+  writeReportSeries(w, r, reportSeriesResponse{
+    SchemaVersion: reports.SchemaVersion,
     Range: "custom",
-    Timezone: reportsTimezoneLabel,
-    Series: mapSeries(items),
+    Timezone: reportsTimezoneLabel(svc),
+    Series: series,
   })
 }
 
@@ -118,27 +147,47 @@ func (s *Server) handleReportsSummary(w http.ResponseWriter, r *http.Request) {
   if key == "" {
     key = reports.RangeD1
   }
+  currency, err := parseReportsCurrency(r)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+  rounding, err := parseReportsRounding(r)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
 
   ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
   defer cancel()
 
-  summary, _, err := svc.Summary(ctx, key, time.Now(), time.Local)
+  summary, _, err := svc.Summary(ctx, key, time.Now(), svc.Location())
   if err != nil {
     if strings.Contains(err.Error(), "invalid range") {
       writeError(w, http.StatusBadRequest, err.Error())
     } else {
-      writeError(w, http.StatusInternalServerError, "failed to load report summary")
+      writeReportsLoadError(w, err, "failed to load report summary")
     }
     return
   }
 
-  writeJSON(w, http.StatusOK, reportSummaryResponse{
-    Range: key,
-    Timezone: reportsTimezoneLabel,
-    Days: summary.Days,
-    Totals: metricsPayload(summary.Totals),
-    Averages: metricsPayload(summary.Averages),
-  })
+  applyReportsRounding(&summary, rounding)
+  resp := summaryResponse(key, reportsTimezoneLabel(svc), summary)
+  if currency != "" {
+    items, _, err := svc.Range(ctx, key, time.Now(), svc.Location())
+    if err != nil {
+      writeReportsLoadError(w, err, "failed to load report summary")
+      return
+    }
+    prices, err := loadReportsPriceTable(ctx, svc, currency, items)
+    if err != nil {
+      writeError(w, http.StatusInternalServerError, "failed to load fiat rates")
+      return
+    }
+    resp.Fiat = fiatSummary(items, currency, prices)
+  }
+
+  writeReportJSON(w, r, resp)
 }
 
 func (s *Server) handleReportsLive(w http.ResponseWriter, r *http.Request) {
@@ -155,7 +204,7 @@ func (s *Server) handleReportsLive(w http.ResponseWriter, r *http.Request) {
   ctx, cancel := context.WithTimeout(r.Context(), reportsLiveTimeout())
   defer cancel()
 
-  tr, metrics, err := svc.Live(ctx, time.Now(), time.Local, reportsLiveLookbackHours())
+  tr, metrics, err := svc.Live(ctx, time.Now(), svc.Location(), reportsLiveLookbackHours())
   if err != nil {
     writeError(w, http.StatusServiceUnavailable, "live report unavailable")
     return
@@ -164,9 +213,99 @@ func (s *Server) handleReportsLive(w http.ResponseWriter, r *http.Request) {
   payload := metricsPayload(metrics)
   payload.Start = tr.StartLocal.Format(time.RFC3339)
   payload.End = tr.EndLocal.Format(time.RFC3339)
-  payload.Timezone = reportsTimezoneLabel
+  payload.Timezone = reportsTimezoneLabel(svc)
+  payload.SchemaVersion = reports.SchemaVersion
+
+  writeReportJSON(w, r, payload)
+}
+
+type reportTodayResponse struct {
+  SchemaVersion int `json:"schema_version"`
+  Date string `json:"date"`
+  Timezone string `json:"timezone"`
+  Provisional bool `json:"provisional"`
+  Persisted bool `json:"persisted"`
+  LiveSince string `json:"live_since"`
+  Metrics reportMetricsPayload `json:"metrics"`
+}
+
+// handleReportsToday serves the running totals for the current UTC day. The
+// result is always provisional: the nightly run replaces it once the day ends.
+func (s *Server) handleReportsToday(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+
+  today, err := svc.Today(ctx, time.Now())
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load today's report")
+    return
+  }
+
+  writeReportJSON(w, r, reportTodayResponse{
+    SchemaVersion: reports.SchemaVersion,
+    Date: today.Date.Format("2006-01-02"),
+    Timezone: "UTC",
+    Provisional: true,
+    Persisted: today.Persisted,
+    LiveSince: today.LiveSince.Format(time.RFC3339),
+    Metrics: metricsPayload(today.Metrics),
+  })
+}
+
+// parseReportsOrder reads order=asc|desc (by date, default asc).
+func parseReportsOrder(r *http.Request) (reports.SortOrder, error) {
+  switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("order"))) {
+  case "", "asc":
+    return reports.Ascending, nil
+  case "desc":
+    return reports.Descending, nil
+  default:
+    return reports.Ascending, errors.New("order must be asc or desc")
+  }
+}
 
-  writeJSON(w, http.StatusOK, payload)
+func parseReportsCustomRange(svc *reports.Service, fromStr, toStr string) (time.Time, time.Time, error) {
+  startDate, err := reports.ParseDate(fromStr, svc.Location())
+  if err != nil {
+    return time.Time{}, time.Time{}, errors.New("from must be YYYY-MM-DD")
+  }
+  endDate, err := reports.ParseDate(toStr, svc.Location())
+  if err != nil {
+    return time.Time{}, time.Time{}, errors.New("to must be YYYY-MM-DD")
+  }
+  if err := svc.ValidateCustomRange(startDate, endDate); err != nil {
+    switch {
+    case errors.Is(err, reports.ErrRangeTooLarge):
+      return time.Time{}, time.Time{}, fmt.Errorf("range too large (max %d days)", svc.Options().RangeDaysLimit())
+    case errors.Is(err, reports.ErrRangeInFuture):
+      return time.Time{}, time.Time{}, errors.New("to must not be in the future")
+    default:
+      return time.Time{}, time.Time{}, errors.New("invalid range")
+    }
+  }
+  return startDate, endDate, nil
+}
+
+func writeReportsLoadError(w http.ResponseWriter, err error, message string) {
+  if errors.Is(err, reports.ErrQueryTimeout) {
+    writeError(w, http.StatusGatewayTimeout, "reports query timed out")
+    return
+  }
+  if errors.Is(err, reports.ErrTooManyRows) {
+    writeError(w, http.StatusUnprocessableEntity, err.Error())
+    return
+  }
+  writeError(w, http.StatusInternalServerError, message)
 }
 
 func reportsLiveTimeout() time.Duration {
@@ -192,6 +331,7 @@ func reportsLiveLookbackHours() int {
 }
 
 type reportSeriesResponse struct {
+  SchemaVersion int `json:"schema_version"`
   Range string `json:"range"`
   Timezone string `json:"timezone"`
   Series []reportSeriesItem `json:"series"`
@@ -208,17 +348,31 @@ type reportSeriesItem struct {
   OnchainBalanceSat *int64 `json:"onchain_balance_sats"`
   LightningBalanceSat *int64 `json:"lightning_balance_sats"`
   TotalBalanceSat *int64 `json:"total_balance_sats"`
+  OnchainRatio *float64 `json:"onchain_ratio"`
+  Currency string `json:"currency,omitempty"`
+  FiatRate *float64 `json:"fiat_rate,omitempty"`
+  Fiat *reportFiatValues `json:"fiat,omitempty"`
+  FiatRateMissing bool `json:"fiat_rate_missing,omitempty"`
 }
 
 type reportSummaryResponse struct {
+  SchemaVersion int `json:"schema_version"`
   Range string `json:"range"`
   Timezone string `json:"timezone"`
   Days int64 `json:"days"`
+  HasData bool `json:"has_data"`
   Totals reportMetricsPayload `json:"totals"`
   Averages reportMetricsPayload `json:"averages"`
+  Max reportMetricsPayload `json:"max"`
+  Median reportMetricsPayload `json:"median"`
+  EffectivePpm float64 `json:"effective_ppm"`
+  RebalanceCostRatio *float64 `json:"rebalance_cost_ratio"`
+  Fiat *reportFiatSummary `json:"fiat,omitempty"`
+  Partial bool `json:"partial,omitempty"`
 }
 
 type reportMetricsPayload struct {
+  SchemaVersion int `json:"schema_version,omitempty"`
   Start string `json:"start,omitempty"`
   End string `json:"end,omitempty"`
   Timezone string `json:"timezone,omitempty"`
@@ -231,6 +385,7 @@ type reportMetricsPayload struct {
   OnchainBalanceSat *int64 `json:"onchain_balance_sats,omitempty"`
   LightningBalanceSat *int64 `json:"lightning_balance_sats,omitempty"`
   TotalBalanceSat *int64 `json:"total_balance_sats,omitempty"`
+  OnchainRatio *float64 `json:"onchain_ratio,omitempty"`
 }
 
 func mapSeries(items []reports.Row) []reportSeriesItem {
@@ -250,11 +405,29 @@ func mapSeries(items []reports.Row) []reportSeriesItem {
       OnchainBalanceSat: item.Metrics.OnchainBalanceSat,
       LightningBalanceSat: item.Metrics.LightningBalanceSat,
       TotalBalanceSat: item.Metrics.TotalBalanceSat,
+      OnchainRatio: item.Metrics.OnchainRatio,
     })
   }
   return series
 }
 
+func summaryResponse(key string, timezone string, summary reports.Summary) reportSummaryResponse {
+  return reportSummaryResponse{
+    SchemaVersion: reports.SchemaVersion,
+    Range: key,
+    Timezone: timezone,
+    Days: summary.Days,
+    HasData: summary.HasData,
+    Totals: metricsPayload(summary.Totals),
+    Averages: metricsPayload(summary.Averages),
+    Max: metricsPayload(summary.Max),
+    Median: metricsPayload(summary.Median),
+    EffectivePpm: summary.EffectivePpm,
+    RebalanceCostRatio: summary.RebalanceCostRatio,
+    Partial: summary.Partial,
+  }
+}
+
 func metricsPayload(metrics reports.Metrics) reportMetricsPayload {
   return reportMetricsPayload{
     ForwardFeeRevenueSat: metricSats(metrics.ForwardFeeRevenueMsat, metrics.ForwardFeeRevenueSat),
@@ -266,6 +439,7 @@ func metricsPayload(metrics reports.Metrics) reportMetricsPayload {
     OnchainBalanceSat: metrics.OnchainBalanceSat,
     LightningBalanceSat: metrics.LightningBalanceSat,
     TotalBalanceSat: metrics.TotalBalanceSat,
+    OnchainRatio: metrics.OnchainRatio,
   }
 }
 
@@ -0,0 +1,45 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+type reportsHealthResponse struct {
+  Configured bool `json:"configured"`
+  Writable bool `json:"writable"`
+  ReadReplica bool `json:"read_replica"`
+  LatestUpdatedAt string `json:"latest_updated_at,omitempty"`
+  Error string `json:"error,omitempty"`
+}
+
+func (s *Server) handleReportsHealth(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil || s.db == nil {
+    writeJSON(w, http.StatusOK, reportsHealthResponse{Configured: false, Error: strings.TrimSpace(errMsg)})
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(svc.QueryContext(r.Context()), 5*time.Second)
+  defer cancel()
+
+  resp := reportsHealthResponse{Configured: true, ReadReplica: s.reportsReadDB != nil}
+  writable, err := reports.CheckWritable(ctx, s.db)
+  if err != nil {
+    resp.Error = "write check failed"
+    s.logger.Printf("reports health: write check failed: %v", err)
+  }
+  resp.Writable = writable
+  if latest, err := reports.LatestUpdatedAt(ctx, s.reportsReadPool()); err == nil {
+    if !latest.IsZero() {
+      resp.LatestUpdatedAt = latest.UTC().Format(time.RFC3339)
+    }
+  } else if resp.Error == "" {
+    resp.Error = "failed to load latest update"
+  }
+  writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+  "context"
+  "encoding/csv"
+  "errors"
+  "fmt"
+  "io"
+  "math/big"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+const (
+  reportsImportMaxBytes = 4 << 20
+  reportsImportMaxErrors = 50
+)
+
+type reportImportError struct {
+  Line int `json:"line"`
+  Error string `json:"error"`
+}
+
+type reportImportResult struct {
+  Inserted int `json:"inserted"`
+  Updated int `json:"updated"`
+  Failed int `json:"failed"`
+  Errors []reportImportError `json:"errors"`
+}
+
+// handleReportsImportCSV loads daily rows from a CSV in the export format,
+// sent either as the request body or as the "file" field of a multipart form.
+// With on_error=fail (default) any malformed row rejects the whole upload;
+// on_error=skip imports the valid rows and reports the rest.
+func (s *Server) handleReportsImportCSV(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  skip := false
+  switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("on_error"))) {
+  case "", "fail":
+  case "skip":
+    skip = true
+  default:
+    writeError(w, http.StatusBadRequest, "on_error must be fail or skip")
+    return
+  }
+
+  r.Body = http.MaxBytesReader(w, r.Body, reportsImportMaxBytes)
+  body, err := reportsImportBody(r)
+  if err != nil {
+    writeReportsImportReadError(w, err)
+    return
+  }
+  defer body.Close()
+
+  rows, result, err := parseReportsImportCSV(body)
+  if err != nil {
+    writeReportsImportReadError(w, err)
+    return
+  }
+  if result.Failed > 0 && !skip {
+    writeJSON(w, http.StatusBadRequest, result)
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+  defer cancel()
+  result.Inserted, result.Updated, err = svc.ImportRows(ctx, rows)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to import reports")
+    return
+  }
+  writeJSON(w, http.StatusOK, result)
+}
+
+func reportsImportBody(r *http.Request) (io.ReadCloser, error) {
+  if !strings.HasPrefix(strings.ToLower(r.Header.Get("Content-Type")), "multipart/form-data") {
+    return r.Body, nil
+  }
+  if err := r.ParseMultipartForm(reportsImportMaxBytes); err != nil {
+    return nil, err
+  }
+  file, _, err := r.FormFile("file")
+  if err != nil {
+    return nil, errors.New("multipart upload needs a file field")
+  }
+  return file, nil
+}
+
+func writeReportsImportReadError(w http.ResponseWriter, err error) {
+  var tooLarge *http.MaxBytesError
+  if errors.As(err, &tooLarge) {
+    writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("upload exceeds %d bytes", reportsImportMaxBytes))
+    return
+  }
+  writeError(w, http.StatusBadRequest, err.Error())
+}
+
+// parseReportsImportCSV returns the valid rows and a result with the failed
+// row count. The error is only set when the file itself is unusable (bad
+// header, broken CSV quoting, read failure).
+func parseReportsImportCSV(src io.Reader) ([]reports.Row, reportImportResult, error) {
+  result := reportImportResult{Errors: []reportImportError{}}
+  reader := csv.NewReader(src)
+  reader.FieldsPerRecord = -1
+  reader.TrimLeadingSpace = true
+
+  header, err := reader.Read()
+  if err == io.EOF {
+    return nil, result, errors.New("csv is empty")
+  }
+  if err != nil {
+    return nil, result, err
+  }
+  if err := validateReportsImportHeader(header); err != nil {
+    return nil, result, err
+  }
+
+  var rows []reports.Row
+  seen := map[string]int{}
+  for {
+    record, err := reader.Read()
+    if err == io.EOF {
+      break
+    }
+    if err != nil {
+      return nil, result, err
+    }
+    line, _ := reader.FieldPos(0)
+    row, err := parseReportsImportRecord(record)
+    if err == nil {
+      key := row.ReportDate.Format("2006-01-02")
+      if prev, ok := seen[key]; ok {
+        err = fmt.Errorf("duplicate date %s (first on line %d)", key, prev)
+      } else {
+        seen[key] = line
+      }
+    }
+    if err != nil {
+      result.Failed++
+      if len(result.Errors) < reportsImportMaxErrors {
+        result.Errors = append(result.Errors, reportImportError{Line: line, Error: err.Error()})
+      }
+      continue
+    }
+    rows = append(rows, row)
+  }
+  return rows, result, nil
+}
+
+func validateReportsImportHeader(header []string) error {
+  if len(header) > 0 {
+    header[0] = strings.TrimPrefix(header[0], "\ufeff")
+  }
+  if len(header) != len(reportsExportColumns) {
+    return fmt.Errorf("header must be: %s", strings.Join(reportsExportColumns, ","))
+  }
+  for i, name := range header {
+    if strings.ToLower(strings.TrimSpace(name)) != reportsExportColumns[i] {
+      return fmt.Errorf("header must be: %s", strings.Join(reportsExportColumns, ","))
+    }
+  }
+  return nil
+}
+
+// parseReportsImportRecord is the inverse of reportsExportRecord. Sat amounts
+// may carry up to three decimals (the msat part); onchain_ratio is derived
+// from the balances on read, so its value is ignored.
+func parseReportsImportRecord(record []string) (reports.Row, error) {
+  if len(record) != len(reportsExportColumns) {
+    return reports.Row{}, fmt.Errorf("expected %d fields, got %d", len(reportsExportColumns), len(record))
+  }
+  date, err := time.Parse("2006-01-02", strings.TrimSpace(record[0]))
+  if err != nil {
+    return reports.Row{}, errors.New("date must be YYYY-MM-DD")
+  }
+
+  var m reports.Metrics
+  amounts := []struct {
+    column int
+    msat *int64
+    sat *int64
+  }{
+    {1, &m.ForwardFeeRevenueMsat, &m.ForwardFeeRevenueSat},
+    {2, &m.RebalanceFeeCostMsat, &m.RebalanceFeeCostSat},
+    {3, &m.NetRoutingProfitMsat, &m.NetRoutingProfitSat},
+    {6, &m.RoutedVolumeMsat, &m.RoutedVolumeSat},
+  }
+  for _, amount := range amounts {
+    msat, err := parseImportSatsToMsat(record[amount.column])
+    if err != nil {
+      return reports.Row{}, fmt.Errorf("%s: %v", reportsExportColumns[amount.column], err)
+    }
+    *amount.msat = msat
+    *amount.sat = msat / 1000
+  }
+  if want := m.ForwardFeeRevenueMsat - m.RebalanceFeeCostMsat; m.NetRoutingProfitMsat != want {
+    return reports.Row{}, errors.New("net_routing_profit_sats must equal revenue - cost")
+  }
+
+  counts := []struct {
+    column int
+    dest *int64
+  }{
+    {4, &m.ForwardCount},
+    {5, &m.RebalanceCount},
+  }
+  for _, count := range counts {
+    value, err := strconv.ParseInt(strings.TrimSpace(record[count.column]), 10, 64)
+    if err != nil || value < 0 {
+      return reports.Row{}, fmt.Errorf("%s must be a non-negative integer", reportsExportColumns[count.column])
+    }
+    *count.dest = value
+  }
+
+  balances := []struct {
+    column int
+    dest **int64
+  }{
+    {7, &m.OnchainBalanceSat},
+    {8, &m.LightningBalanceSat},
+    {9, &m.TotalBalanceSat},
+  }
+  for _, balance := range balances {
+    raw := strings.TrimSpace(record[balance.column])
+    if raw == "" {
+      continue
+    }
+    value, err := strconv.ParseInt(raw, 10, 64)
+    if err != nil {
+      return reports.Row{}, fmt.Errorf("%s must be an integer or empty", reportsExportColumns[balance.column])
+    }
+    *balance.dest = &value
+  }
+
+  return reports.Row{ReportDate: date, Metrics: m}, nil
+}
+
+func parseImportSatsToMsat(raw string) (int64, error) {
+  raw = strings.TrimSpace(raw)
+  if raw == "" {
+    return 0, nil
+  }
+  if strings.Contains(raw, "/") {
+    return 0, errors.New("not a number")
+  }
+  value, ok := new(big.Rat).SetString(raw)
+  if !ok {
+    return 0, errors.New("not a number")
+  }
+  value.Mul(value, big.NewRat(1000, 1))
+  if !value.IsInt() || !value.Num().IsInt64() {
+    return 0, errors.New("more than 3 decimals or out of range")
+  }
+  return value.Num().Int64(), nil
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestParseReportsImportCSV(t *testing.T) {
+  csv := strings.Join([]string{
+    "\ufeff" + strings.Join(reportsExportColumns, ","),
+    "2026-03-01,12.5,2.25,10.25,3,1,5000,100000,200000,300000,0.33",
+    "2026-03-02,1,0,2,1,0,10,,,,",
+    "2026-03-03,1.0001,0,1.0001,1,0,10,,,,",
+    "bad-date,0,0,0,0,0,0,,,,",
+    "2026-03-01,0,0,0,0,0,0,,,,",
+    "2026-03-04,0,0,0,0,0,0,,,",
+  }, "\n") + "\n"
+
+  rows, result, err := parseReportsImportCSV(strings.NewReader(csv))
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(rows) != 1 || result.Failed != 5 {
+    t.Fatalf("expected 1 valid row and 5 failures, got %d rows, %+v", len(rows), result)
+  }
+  m := rows[0].Metrics
+  if m.ForwardFeeRevenueMsat != 12500 || m.ForwardFeeRevenueSat != 12 || m.NetRoutingProfitMsat != 10250 || m.RoutedVolumeMsat != 5000000 {
+    t.Fatalf("unexpected amounts: %+v", m)
+  }
+  if m.TotalBalanceSat == nil || *m.TotalBalanceSat != 300000 || m.ForwardCount != 3 {
+    t.Fatalf("unexpected balances or counts: %+v", m)
+  }
+  lines := []int{}
+  for _, e := range result.Errors {
+    lines = append(lines, e.Line)
+  }
+  if len(lines) != 5 || lines[0] != 3 || lines[4] != 7 {
+    t.Fatalf("expected errors on lines 3..7, got %v", lines)
+  }
+}
+
+func TestParseReportsImportCSVRejectsHeader(t *testing.T) {
+  if _, _, err := parseReportsImportCSV(strings.NewReader("date,revenue\n2026-03-01,1\n")); err == nil {
+    t.Fatalf("expected header error")
+  }
+  if _, _, err := parseReportsImportCSV(strings.NewReader("")); err == nil {
+    t.Fatalf("expected error for empty csv")
+  }
+}
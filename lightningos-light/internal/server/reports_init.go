@@ -2,9 +2,14 @@ package server
 
 import (
   "context"
+  "errors"
   "fmt"
+  "log"
+  "os"
+  "strings"
   "time"
 
+  "lightningos-light/internal/config"
   "lightningos-light/internal/reports"
 
   "github.com/jackc/pgx/v5/pgxpool"
@@ -12,6 +17,13 @@ import (
 
 func (s *Server) initReports() {
   s.reportsOnce.Do(func() {
+    opts, err := ReportsOptions(s.cfg, s.logger)
+    if err != nil {
+      s.reportsErr = fmt.Sprintf("reports unavailable: %v", err)
+      s.logger.Printf("%s", s.reportsErr)
+      return
+    }
+
     dsn, err := ResolveNotificationsDSN(s.logger)
     if err != nil {
       s.reportsErr = fmt.Sprintf("reports unavailable: %v", err)
@@ -32,9 +44,20 @@ func (s *Server) initReports() {
       s.db = pool
     }
 
-    svc := reports.NewService(pool, s.lnd, s.logger)
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
+    if err := reports.Ping(ctx, pool); err != nil {
+      if errors.Is(err, reports.ErrNoDatabase) {
+        s.reportsErr = "reports disabled: no database configured"
+      } else {
+        s.reportsErr = fmt.Sprintf("reports unavailable: database ping failed: %v", err)
+      }
+      s.logger.Printf("warning: %s", s.reportsErr)
+      return
+    }
+
+    s.reportsReadDB = s.openReportsReadPool()
+    svc := reports.NewService(reports.NewPgStoreWithReplica(pool, s.reportsReadDB, opts), s.lnd, s.logger, opts)
     if err := svc.EnsureSchema(ctx); err != nil {
       s.reportsErr = fmt.Sprintf("reports unavailable: failed to init schema: %v", err)
       s.logger.Printf("%s", s.reportsErr)
@@ -46,7 +69,69 @@ func (s *Server) initReports() {
   })
 }
 
+// ReportsOptions builds the reports settings from the reports section of
+// config.yaml. Invalid anomaly thresholds are logged and replaced by the
+// defaults; an unknown timezone is an error.
+func ReportsOptions(cfg *config.Config, logger *log.Logger) (reports.Options, error) {
+  opts := reports.DefaultOptions()
+  loc, err := reports.LoadTimezone(strings.TrimSpace(cfg.Reports.Timezone))
+  if err != nil {
+    return opts, err
+  }
+  opts.Timezone = loc
+  if cfg.Reports.QueryTimeoutSec > 0 {
+    opts.QueryTimeout = time.Duration(cfg.Reports.QueryTimeoutSec) * time.Second
+  }
+  if cfg.Reports.MaxRangeDays > 0 {
+    opts.MaxRangeDays = cfg.Reports.MaxRangeDays
+  }
+  if cfg.Reports.FetchAllMaxRows != 0 {
+    opts.FetchAllMaxRows = cfg.Reports.FetchAllMaxRows
+  }
+  opts.StoreEvents = cfg.Reports.StoreEvents
+  opts.StoreChannels = cfg.Reports.StoreChannels
+  opts.FiatCurrencies = cfg.Reports.FiatCurrencies
+  opts.PriceURL = strings.TrimSpace(cfg.Reports.PriceURL)
+  opts.Anomalies, err = reports.NormalizeAnomalyThresholds(reports.AnomalyThresholds{
+    CostRevenueRatio: cfg.Reports.Anomalies.CostRevenueRatio,
+    ActiveStreakDays: cfg.Reports.Anomalies.ActiveStreakDays,
+  })
+  if err != nil && logger != nil {
+    logger.Printf("reports: invalid anomaly thresholds, using defaults: %v", err)
+  }
+  return opts, nil
+}
+
 func (s *Server) reportsService() (*reports.Service, string) {
   s.initReports()
   return s.reports, s.reportsErr
 }
+
+// openReportsReadPool connects to REPORTS_PG_READ_DSN when set so report reads
+// can be served by a replica. Any failure falls back to the primary pool.
+func (s *Server) openReportsReadPool() *pgxpool.Pool {
+  dsn := strings.TrimSpace(os.Getenv("REPORTS_PG_READ_DSN"))
+  if dsn == "" || isPlaceholderDSN(dsn) {
+    return nil
+  }
+  ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+  defer cancel()
+  pool, err := pgxpool.New(ctx, dsn)
+  if err != nil {
+    s.logger.Printf("warning: reports read replica disabled: %v", err)
+    return nil
+  }
+  if err := reports.Ping(ctx, pool); err != nil {
+    pool.Close()
+    s.logger.Printf("warning: reports read replica disabled: ping failed: %v", err)
+    return nil
+  }
+  return pool
+}
+
+func (s *Server) reportsReadPool() *pgxpool.Pool {
+  if s.reportsReadDB != nil {
+    return s.reportsReadDB
+  }
+  return s.db
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+  "context"
+  "crypto/hmac"
+  "crypto/rand"
+  "crypto/sha256"
+  "encoding/base64"
+  "errors"
+  "net/http"
+  "net/url"
+  "os"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+const (
+  reportsLinkSecretEnv = "REPORTS_LINK_SECRET"
+  reportsLinkTokenVersion = "v1"
+  reportsLinkDefaultTTL = 24 * time.Hour
+  reportsLinkMaxTTL = 7 * 24 * time.Hour
+)
+
+var (
+  errReportsLinkInvalid = errors.New("invalid download token")
+  errReportsLinkExpired = errors.New("download token expired")
+)
+
+var (
+  reportsLinkKeyOnce sync.Once
+  reportsLinkKeyValue []byte
+)
+
+// reportsLinkKey returns the HMAC key for export links. Without
+// REPORTS_LINK_SECRET a random key is made per process, so links stop working
+// after a restart.
+func reportsLinkKey() []byte {
+  reportsLinkKeyOnce.Do(func() {
+    if secret := strings.TrimSpace(os.Getenv(reportsLinkSecretEnv)); secret != "" {
+      reportsLinkKeyValue = []byte(secret)
+      return
+    }
+    key := make([]byte, 32)
+    if _, err := rand.Read(key); err != nil {
+      panic("reports link key: " + err.Error())
+    }
+    reportsLinkKeyValue = key
+  })
+  return reportsLinkKeyValue
+}
+
+type reportsLinkClaims struct {
+  From time.Time
+  To time.Time
+  ExpiresAt time.Time
+}
+
+// signReportsLink encodes claims as base64url("v1:from:to:exp") + "." +
+// base64url(HMAC-SHA256(payload)).
+func signReportsLink(key []byte, claims reportsLinkClaims) string {
+  payload := strings.Join([]string{
+    reportsLinkTokenVersion,
+    claims.From.Format("2006-01-02"),
+    claims.To.Format("2006-01-02"),
+    strconv.FormatInt(claims.ExpiresAt.Unix(), 10),
+  }, ":")
+  mac := hmac.New(sha256.New, key)
+  mac.Write([]byte(payload))
+  enc := base64.RawURLEncoding
+  return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+// verifyReportsLink checks the signature before looking at any claim, then the
+// expiry. Dates are returned as parsed from the token; callers still validate
+// the range.
+func verifyReportsLink(key []byte, token string, now time.Time) (reportsLinkClaims, error) {
+  encPayload, encSig, ok := strings.Cut(strings.TrimSpace(token), ".")
+  if !ok {
+    return reportsLinkClaims{}, errReportsLinkInvalid
+  }
+  enc := base64.RawURLEncoding
+  payload, err := enc.DecodeString(encPayload)
+  if err != nil {
+    return reportsLinkClaims{}, errReportsLinkInvalid
+  }
+  sig, err := enc.DecodeString(encSig)
+  if err != nil {
+    return reportsLinkClaims{}, errReportsLinkInvalid
+  }
+  mac := hmac.New(sha256.New, key)
+  mac.Write(payload)
+  if !hmac.Equal(sig, mac.Sum(nil)) {
+    return reportsLinkClaims{}, errReportsLinkInvalid
+  }
+
+  parts := strings.Split(string(payload), ":")
+  if len(parts) != 4 || parts[0] != reportsLinkTokenVersion {
+    return reportsLinkClaims{}, errReportsLinkInvalid
+  }
+  from, errFrom := time.Parse("2006-01-02", parts[1])
+  to, errTo := time.Parse("2006-01-02", parts[2])
+  exp, errExp := strconv.ParseInt(parts[3], 10, 64)
+  if errFrom != nil || errTo != nil || errExp != nil {
+    return reportsLinkClaims{}, errReportsLinkInvalid
+  }
+  claims := reportsLinkClaims{From: from, To: to, ExpiresAt: time.Unix(exp, 0)}
+  if !now.Before(claims.ExpiresAt) {
+    return reportsLinkClaims{}, errReportsLinkExpired
+  }
+  return claims, nil
+}
+
+type reportsLinkRequest struct {
+  From string `json:"from"`
+  To string `json:"to"`
+  TTLSec int `json:"ttl_sec"`
+}
+
+func (s *Server) handleReportsExportLinkCreate(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+  var req reportsLinkRequest
+  if err := readJSON(r, &req); err != nil {
+    writeError(w, http.StatusBadRequest, "invalid json")
+    return
+  }
+  fromStr := strings.TrimSpace(req.From)
+  toStr := strings.TrimSpace(req.To)
+  if fromStr == "" || toStr == "" {
+    writeError(w, http.StatusBadRequest, "from and to are required")
+    return
+  }
+  startDate, endDate, err := parseReportsCustomRange(svc, fromStr, toStr)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+  ttl := reportsLinkDefaultTTL
+  if req.TTLSec != 0 {
+    ttl = time.Duration(req.TTLSec) * time.Second
+    if req.TTLSec < 0 || ttl > reportsLinkMaxTTL {
+      writeError(w, http.StatusBadRequest, "ttl_sec must be between 1 and 604800")
+      return
+    }
+  }
+
+  expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+  token := signReportsLink(reportsLinkKey(), reportsLinkClaims{From: startDate, To: endDate, ExpiresAt: expiresAt})
+  writeJSON(w, http.StatusOK, map[string]any{
+    "token": token,
+    "url": "/api/reports/export?token=" + url.QueryEscape(token),
+    "from": startDate.Format("2006-01-02"),
+    "to": endDate.Format("2006-01-02"),
+    "expires_at": expiresAt.UTC().Format(time.RFC3339),
+  })
+}
+
+// handleReportsExportDownload serves the CSV for a signed link. The token is
+// the only credential, so it is checked before touching the database.
+func (s *Server) handleReportsExportDownload(w http.ResponseWriter, r *http.Request) {
+  claims, err := verifyReportsLink(reportsLinkKey(), r.URL.Query().Get("token"), time.Now())
+  if err != nil {
+    writeError(w, http.StatusForbidden, err.Error())
+    return
+  }
+
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  startDate, endDate, err := parseReportsCustomRange(svc, claims.From.Format("2006-01-02"), claims.To.Format("2006-01-02"))
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+  items, err := svc.CustomRange(ctx, startDate, endDate)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load reports")
+    return
+  }
+
+  w.Header().Set("Cache-Control", "no-store")
+  writeReportSeriesDelimited(w, reportSeriesResponse{
+    SchemaVersion: reports.SchemaVersion,
+    Range: startDate.Format("2006-01-02") + "_" + endDate.Format("2006-01-02"),
+    Timezone: reportsTimezoneLabel(svc),
+    Series: mapSeries(items),
+  }, reportsFormatCSV)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+  "errors"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestReportsLinkRoundTrip(t *testing.T) {
+  key := []byte("test-secret")
+  now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+  claims := reportsLinkClaims{
+    From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+    To: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+    ExpiresAt: now.Add(time.Hour),
+  }
+  token := signReportsLink(key, claims)
+
+  got, err := verifyReportsLink(key, token, now)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if got.From.Format("2006-01-02") != "2026-03-01" || got.To.Format("2006-01-02") != "2026-03-31" || !got.ExpiresAt.Equal(claims.ExpiresAt) {
+    t.Fatalf("unexpected claims: %+v", got)
+  }
+
+  if _, err := verifyReportsLink(key, token, now.Add(time.Hour)); !errors.Is(err, errReportsLinkExpired) {
+    t.Fatalf("expected expired error, got %v", err)
+  }
+  if _, err := verifyReportsLink([]byte("other"), token, now); !errors.Is(err, errReportsLinkInvalid) {
+    t.Fatalf("expected invalid error for another key, got %v", err)
+  }
+}
+
+func TestReportsLinkRejectsTampering(t *testing.T) {
+  key := []byte("test-secret")
+  now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+  token := signReportsLink(key, reportsLinkClaims{
+    From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+    To: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+    ExpiresAt: now.Add(time.Hour),
+  })
+  payload, sig, _ := strings.Cut(token, ".")
+  widened := signReportsLink([]byte("attacker"), reportsLinkClaims{
+    From: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+    To: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+    ExpiresAt: now.Add(time.Hour),
+  })
+  otherPayload, _, _ := strings.Cut(widened, ".")
+
+  for _, bad := range []string{"", "abc", payload, payload + ".", otherPayload + "." + sig, payload + "." + sig[:len(sig)-2] + "AA"} {
+    if _, err := verifyReportsLink(key, bad, now); !errors.Is(err, errReportsLinkInvalid) {
+      t.Fatalf("expected %q to be rejected, got %v", bad, err)
+    }
+  }
+}
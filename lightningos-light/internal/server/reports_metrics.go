@@ -0,0 +1,94 @@
+package server
+
+import (
+  "context"
+  "fmt"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+type promMetric struct {
+  Name string
+  Help string
+  Type string
+  Value string
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+  w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+  svc, _ := s.reportsService()
+  if svc == nil {
+    w.WriteHeader(http.StatusOK)
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+
+  today := reports.BuildTimeRangeForToday(time.Now(), svc.Location()).StartLocal
+  items, err := svc.CustomRange(ctx, today, today)
+  if err != nil || len(items) == 0 {
+    if err != nil {
+      s.logger.Printf("metrics: reports unavailable: %v", err)
+    }
+    w.WriteHeader(http.StatusOK)
+    return
+  }
+
+  w.WriteHeader(http.StatusOK)
+  _, _ = w.Write([]byte(renderPromMetrics(reportPromMetrics(items[len(items)-1].Metrics))))
+}
+
+func reportPromMetrics(metrics reports.Metrics) []promMetric {
+  return []promMetric{
+    {
+      Name: "brln_forward_fee_revenue_sats",
+      Help: "Forwarding fee revenue for the latest report day in sats.",
+      Type: "gauge",
+      Value: formatPromFloat(metricSats(metrics.ForwardFeeRevenueMsat, metrics.ForwardFeeRevenueSat)),
+    },
+    {
+      Name: "brln_rebalance_fee_cost_sats",
+      Help: "Rebalance fee cost for the latest report day in sats.",
+      Type: "gauge",
+      Value: formatPromFloat(metricSats(metrics.RebalanceFeeCostMsat, metrics.RebalanceFeeCostSat)),
+    },
+    {
+      Name: "brln_net_routing_profit_sats",
+      Help: "Net routing profit for the latest report day in sats.",
+      Type: "gauge",
+      Value: formatPromFloat(metricSats(metrics.NetRoutingProfitMsat, metrics.NetRoutingProfitSat)),
+    },
+    {
+      Name: "brln_forward_count",
+      Help: "Forwards settled during the latest report day.",
+      Type: "counter",
+      Value: strconv.FormatInt(metrics.ForwardCount, 10),
+    },
+    {
+      Name: "brln_rebalance_count",
+      Help: "Rebalances completed during the latest report day.",
+      Type: "counter",
+      Value: strconv.FormatInt(metrics.RebalanceCount, 10),
+    },
+  }
+}
+
+func renderPromMetrics(items []promMetric) string {
+  var b strings.Builder
+  for _, item := range items {
+    fmt.Fprintf(&b, "# HELP %s %s\n", item.Name, item.Help)
+    fmt.Fprintf(&b, "# TYPE %s %s\n", item.Name, item.Type)
+    fmt.Fprintf(&b, "%s %s\n", item.Name, item.Value)
+  }
+  return b.String()
+}
+
+func formatPromFloat(value float64) string {
+  return strconv.FormatFloat(value, 'f', -1, 64)
+}
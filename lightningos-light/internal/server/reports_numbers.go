@@ -0,0 +1,187 @@
+package server
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "math/big"
+  "net/http"
+  "strings"
+
+  "lightningos-light/internal/reports"
+)
+
+// writeReportJSON writes a report payload with an ETag. With int_as_string=1
+// every sat/msat amount is sent as a JSON string so clients that parse numbers
+// as float64 (JavaScript) keep full precision above 2^53. With btc=1 each sat
+// amount also gets a *_btc sibling rendered as a fixed 8-decimal string.
+func writeReportJSON(w http.ResponseWriter, r *http.Request, payload any) {
+  intAsString := reportsQueryFlag(r, "int_as_string")
+  btc := reportsQueryFlag(r, "btc")
+  if !intAsString && !btc {
+    writeJSONWithETag(w, r, http.StatusOK, payload)
+    return
+  }
+  raw, err := transformReportAmounts(payload, intAsString, btc)
+  if err != nil {
+    writeError(w, http.StatusInternalServerError, "failed to encode response")
+    return
+  }
+  writeJSONWithETag(w, r, http.StatusOK, raw)
+}
+
+func reportsQueryFlag(r *http.Request, name string) bool {
+  switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get(name))) {
+  case "1", "true", "yes":
+    return true
+  default:
+    return false
+  }
+}
+
+// parseReportsRounding reads round=truncate|half_up. Stored averages are
+// truncated; half_up recomputes them from the totals for display.
+func parseReportsRounding(r *http.Request) (reports.RoundingMode, error) {
+  switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("round"))) {
+  case "", "truncate":
+    return reports.RoundTruncate, nil
+  case "half_up":
+    return reports.RoundHalfUp, nil
+  default:
+    return reports.RoundTruncate, errors.New("round must be truncate or half_up")
+  }
+}
+
+func applyReportsRounding(summary *reports.Summary, mode reports.RoundingMode) {
+  if mode == reports.RoundTruncate {
+    return
+  }
+  summary.Averages = reports.AverageMetrics(summary.Totals, summary.Days, mode)
+}
+
+func transformReportAmounts(payload any, intAsString bool, btc bool) (json.RawMessage, error) {
+  encoded, err := json.Marshal(payload)
+  if err != nil {
+    return nil, err
+  }
+  dec := json.NewDecoder(bytes.NewReader(encoded))
+  dec.UseNumber()
+  var tree any
+  if err := dec.Decode(&tree); err != nil {
+    return nil, err
+  }
+  if btc {
+    tree = addBTCAmountNode(tree)
+  }
+  if intAsString {
+    tree = stringifyAmountNode(tree, false)
+  }
+  out, err := json.Marshal(tree)
+  if err != nil {
+    return nil, err
+  }
+  return json.RawMessage(out), nil
+}
+
+func stringifyAmountNode(node any, amount bool) any {
+  switch value := node.(type) {
+  case map[string]any:
+    for key, child := range value {
+      value[key] = stringifyAmountNode(child, isReportAmountKey(key))
+    }
+    return value
+  case []any:
+    for i, child := range value {
+      value[i] = stringifyAmountNode(child, amount)
+    }
+    return value
+  case json.Number:
+    if amount {
+      return value.String()
+    }
+    return value
+  default:
+    return node
+  }
+}
+
+func isReportAmountKey(key string) bool {
+  return strings.HasSuffix(key, "_sats") || strings.HasSuffix(key, "_sat") || strings.HasSuffix(key, "_msat")
+}
+
+// addBTCAmountNode adds a <name>_btc key next to every <name>_sat(s) key. The
+// sibling mirrors the shape of the sat value (number, array or null).
+func addBTCAmountNode(node any) any {
+  switch value := node.(type) {
+  case map[string]any:
+    extra := map[string]any{}
+    for key, child := range value {
+      if base, ok := reportSatKeyBase(key); ok {
+        if _, exists := value[base+"_btc"]; !exists {
+          if converted, ok := btcAmountNode(child); ok {
+            extra[base+"_btc"] = converted
+          }
+        }
+      }
+      value[key] = addBTCAmountNode(child)
+    }
+    for key, child := range extra {
+      value[key] = child
+    }
+    return value
+  case []any:
+    for i, child := range value {
+      value[i] = addBTCAmountNode(child)
+    }
+    return value
+  default:
+    return node
+  }
+}
+
+func btcAmountNode(node any) (any, bool) {
+  switch value := node.(type) {
+  case nil:
+    return nil, true
+  case json.Number:
+    btc, ok := satsToBTCString(value.String())
+    if !ok {
+      return nil, false
+    }
+    return btc, true
+  case []any:
+    out := make([]any, len(value))
+    for i, child := range value {
+      converted, ok := btcAmountNode(child)
+      if !ok {
+        return nil, false
+      }
+      out[i] = converted
+    }
+    return out, true
+  default:
+    return nil, false
+  }
+}
+
+func reportSatKeyBase(key string) (string, bool) {
+  for _, suffix := range []string{"_sats", "_sat"} {
+    if strings.HasSuffix(key, suffix) {
+      return strings.TrimSuffix(key, suffix), true
+    }
+  }
+  return "", false
+}
+
+var satsPerBTC = big.NewRat(100_000_000, 1)
+
+// satsToBTCString converts a decimal sat amount to BTC with exactly 8
+// decimals using rational arithmetic, so large values never pass through
+// float64. Fractional sats round half away from zero.
+func satsToBTCString(sats string) (string, bool) {
+  amount, ok := new(big.Rat).SetString(sats)
+  if !ok {
+    return "", false
+  }
+  return amount.Quo(amount, satsPerBTC).FloatString(8), true
+}
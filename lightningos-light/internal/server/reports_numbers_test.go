@@ -0,0 +1,96 @@
+package server
+
+import (
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "strconv"
+  "testing"
+)
+
+func TestWriteReportJSONIntAsString(t *testing.T) {
+  big := int64(1<<53 + 1)
+  payload := reportMetricsPayload{ForwardCount: 3, RoutedVolumeSat: 12.5, TotalBalanceSat: &big}
+
+  req := httptest.NewRequest(http.MethodGet, "/api/reports/live?int_as_string=1", nil)
+  rec := httptest.NewRecorder()
+  writeReportJSON(rec, req, payload)
+
+  var decoded map[string]any
+  if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+    t.Fatalf("decode: %v", err)
+  }
+  total, ok := decoded["total_balance_sats"].(string)
+  if !ok {
+    t.Fatalf("expected total_balance_sats as string, got %T", decoded["total_balance_sats"])
+  }
+  parsed, err := strconv.ParseInt(total, 10, 64)
+  if err != nil || parsed != big {
+    t.Fatalf("expected %d, got %q", big, total)
+  }
+  if decoded["routed_volume_sats"] != "12.5" {
+    t.Fatalf("unexpected routed volume: %v", decoded["routed_volume_sats"])
+  }
+  if _, ok := decoded["forward_count"].(float64); !ok {
+    t.Fatalf("expected forward_count to stay numeric, got %T", decoded["forward_count"])
+  }
+}
+
+func TestWriteReportJSONDefaultNumeric(t *testing.T) {
+  req := httptest.NewRequest(http.MethodGet, "/api/reports/series", nil)
+  rec := httptest.NewRecorder()
+  writeReportJSON(rec, req, map[string]any{"net_profit_sats": []float64{1, 2.5}})
+
+  var decoded map[string][]any
+  if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+    t.Fatalf("decode: %v", err)
+  }
+  if _, ok := decoded["net_profit_sats"][1].(float64); !ok {
+    t.Fatalf("expected numeric values by default, got %v", decoded["net_profit_sats"])
+  }
+}
+
+func TestSatsToBTCStringFixedPoint(t *testing.T) {
+  cases := map[string]string{
+    "123456789": "1.23456789",
+    "0": "0.00000000",
+    "-5000": "-0.00005000",
+    "2100000000000000": "21000000.00000000",
+    "9223372036854775807": "92233720368.54775807",
+    "12.5": "0.00000013",
+  }
+  for in, want := range cases {
+    got, ok := satsToBTCString(in)
+    if !ok || got != want {
+      t.Fatalf("%s: expected %s, got %q (%v)", in, want, got, ok)
+    }
+  }
+}
+
+func TestWriteReportJSONAddsBTC(t *testing.T) {
+  req := httptest.NewRequest(http.MethodGet, "/api/reports/live?btc=1", nil)
+  rec := httptest.NewRecorder()
+  writeReportJSON(rec, req, map[string]any{
+    "routed_volume_sats": int64(123456789),
+    "net_profit_sats": []int64{100000000, 1},
+    "forward_count": 3,
+  })
+
+  var decoded map[string]any
+  if err := json.Unmarshal(rec.Body.Bytes(), &decoded); err != nil {
+    t.Fatalf("decode: %v", err)
+  }
+  if decoded["routed_volume_btc"] != "1.23456789" {
+    t.Fatalf("unexpected routed_volume_btc: %v", decoded["routed_volume_btc"])
+  }
+  if _, ok := decoded["routed_volume_sats"].(float64); !ok {
+    t.Fatalf("expected sats to stay numeric, got %T", decoded["routed_volume_sats"])
+  }
+  series, ok := decoded["net_profit_btc"].([]any)
+  if !ok || len(series) != 2 || series[0] != "1.00000000" || series[1] != "0.00000001" {
+    t.Fatalf("unexpected net_profit_btc: %v", decoded["net_profit_btc"])
+  }
+  if _, ok := decoded["forward_count_btc"]; ok {
+    t.Fatalf("did not expect btc for non-amount keys")
+  }
+}
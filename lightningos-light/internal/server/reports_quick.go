@@ -0,0 +1,87 @@
+package server
+
+import (
+  "context"
+  "fmt"
+  "net/http"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+const (
+  reportsQuick7D = "7d"
+  reportsQuick30D = "30d"
+  reportsQuick90D = "90d"
+  reportsQuickYTD = "ytd"
+  reportsQuickAll = "all"
+)
+
+func (s *Server) handleReportsQuickSummary(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  window := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("window")))
+  if window == "" {
+    window = reportsQuick7D
+  }
+  dr, err := resolveQuickSummaryWindow(time.Now(), svc.Location(), window)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+  rounding, err := parseReportsRounding(r)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+
+  var summary reports.Summary
+  if dr.All {
+    summary, _, err = svc.Summary(ctx, reports.RangeAll, time.Now(), svc.Location())
+  } else {
+    summary, err = svc.CustomSummary(ctx, dr.StartDate, dr.EndDate)
+  }
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load report summary")
+    return
+  }
+
+  applyReportsRounding(&summary, rounding)
+  writeReportJSON(w, r, summaryResponse(window, reportsTimezoneLabel(svc), summary))
+}
+
+func resolveQuickSummaryWindow(now time.Time, loc *time.Location, window string) (reports.DateRange, error) {
+  if loc == nil {
+    loc = time.Local
+  }
+  local := now.In(loc)
+  yesterday := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+
+  switch window {
+  case reportsQuick7D:
+    return reports.DateRange{StartDate: yesterday.AddDate(0, 0, -6), EndDate: yesterday}, nil
+  case reportsQuick30D:
+    return reports.DateRange{StartDate: yesterday.AddDate(0, 0, -29), EndDate: yesterday}, nil
+  case reportsQuick90D:
+    return reports.DateRange{StartDate: yesterday.AddDate(0, 0, -89), EndDate: yesterday}, nil
+  case reportsQuickYTD:
+    start := time.Date(now.UTC().Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+    return reports.DateRange{StartDate: start, EndDate: yesterday}, nil
+  case reportsQuickAll:
+    return reports.DateRange{All: true}, nil
+  default:
+    return reports.DateRange{}, fmt.Errorf("window must be one of 7d, 30d, 90d, ytd, all")
+  }
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+  "testing"
+  "time"
+)
+
+func TestResolveQuickSummaryWindow(t *testing.T) {
+  now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+  cases := map[string]string{
+    "7d": "2026-03-03",
+    "30d": "2026-02-08",
+    "90d": "2025-12-10",
+    "ytd": "2026-01-01",
+  }
+  for window, start := range cases {
+    dr, err := resolveQuickSummaryWindow(now, time.UTC, window)
+    if err != nil {
+      t.Fatalf("%s: unexpected error: %v", window, err)
+    }
+    if got := dr.StartDate.Format("2006-01-02"); got != start {
+      t.Fatalf("%s: expected start %s, got %s", window, start, got)
+    }
+    if got := dr.EndDate.Format("2006-01-02"); got != "2026-03-09" {
+      t.Fatalf("%s: expected end 2026-03-09, got %s", window, got)
+    }
+  }
+
+  dr, err := resolveQuickSummaryWindow(now, time.UTC, "all")
+  if err != nil || !dr.All {
+    t.Fatalf("expected all window, got %+v (%v)", dr, err)
+  }
+  if _, err := resolveQuickSummaryWindow(now, time.UTC, "14d"); err == nil {
+    t.Fatalf("expected error for unknown window")
+  }
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+type reportDiscrepancy struct {
+  Date string `json:"date"`
+  Field string `json:"field"`
+  Expected int64 `json:"expected"`
+  Actual int64 `json:"actual"`
+}
+
+func (s *Server) handleReportsReconcile(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+  toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+  if fromStr == "" || toStr == "" {
+    writeError(w, http.StatusBadRequest, "from and to are required")
+    return
+  }
+  startDate, endDate, err := parseReportsCustomRange(svc, fromStr, toStr)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+  defer cancel()
+  found, err := reports.ReconcileDaily(svc.QueryContext(ctx), s.reportsReadPool(), startDate, endDate)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to reconcile reports")
+    return
+  }
+
+  items := make([]reportDiscrepancy, 0, len(found))
+  for _, d := range found {
+    items = append(items, reportDiscrepancy{
+      Date: d.ReportDate.Format("2006-01-02"),
+      Field: d.Field,
+      Expected: d.Expected,
+      Actual: d.Actual,
+    })
+  }
+  writeJSON(w, http.StatusOK, map[string]any{
+    "from": startDate.Format("2006-01-02"),
+    "to": endDate.Format("2006-01-02"),
+    "discrepancies": items,
+  })
+}
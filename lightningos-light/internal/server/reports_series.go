@@ -0,0 +1,205 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+const (
+  reportsSeriesNetProfit = "net_profit"
+  reportsSeriesVolume = "volume"
+  reportsSmoothMin = 2
+  reportsCumulativeRange = "range"
+  reportsCumulativeAll = "all"
+  reportsSmoothMax = 90
+)
+
+type reportChartSeriesResponse struct {
+  SchemaVersion int `json:"schema_version"`
+  Range string `json:"range"`
+  Timezone string `json:"timezone"`
+  Dates []string `json:"dates"`
+  Smooth int `json:"smooth,omitempty"`
+  Cumulative string `json:"cumulative,omitempty"`
+  NetProfitSat []float64 `json:"net_profit_sats,omitempty"`
+  VolumeSat []float64 `json:"volume_sats,omitempty"`
+}
+
+func (s *Server) handleReportsSeries(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  metric := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("metric")))
+  if metric != "" && metric != reportsSeriesNetProfit && metric != reportsSeriesVolume {
+    writeError(w, http.StatusBadRequest, "metric must be net_profit or volume")
+    return
+  }
+
+  smooth := 0
+  if raw := strings.TrimSpace(r.URL.Query().Get("smooth")); raw != "" {
+    parsed, err := strconv.Atoi(raw)
+    if err != nil || parsed < reportsSmoothMin || parsed > reportsSmoothMax {
+      writeError(w, http.StatusBadRequest, "smooth must be between 2 and 90")
+      return
+    }
+    smooth = parsed
+  }
+
+  cumulative := ""
+  switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("cumulative"))) {
+  case "", "0", "false":
+  case "1", "true", reportsCumulativeRange:
+    cumulative = reportsCumulativeRange
+  case reportsCumulativeAll:
+    cumulative = reportsCumulativeAll
+  default:
+    writeError(w, http.StatusBadRequest, "cumulative must be 1 or all")
+    return
+  }
+  if cumulative != "" && smooth > 0 {
+    writeError(w, http.StatusBadRequest, "smooth and cumulative cannot be combined")
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+
+  var items []reports.Row
+  var startDate, endDate time.Time
+  key := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("range")))
+  fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+  toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+  if fromStr != "" || toStr != "" {
+    if fromStr == "" || toStr == "" {
+      writeError(w, http.StatusBadRequest, "from and to are required")
+      return
+    }
+    start, end, err := parseReportsCustomRange(svc, fromStr, toStr)
+    if err != nil {
+      writeError(w, http.StatusBadRequest, err.Error())
+      return
+    }
+    key = "custom"
+    startDate, endDate = start, end
+    items, err = svc.CustomRange(ctx, startDate, endDate)
+    if err != nil {
+      writeReportsLoadError(w, err, "failed to load reports")
+      return
+    }
+  } else {
+    if key == "" {
+      key = reports.RangeMonth
+    }
+    rows, dr, err := svc.Range(ctx, key, time.Now(), svc.Location())
+    if err != nil {
+      if strings.Contains(err.Error(), "invalid range") {
+        writeError(w, http.StatusBadRequest, err.Error())
+      } else {
+        writeReportsLoadError(w, err, "failed to load reports")
+      }
+      return
+    }
+    items = rows
+    startDate, endDate = dr.StartDate, dr.EndDate
+    if dr.All && len(rows) > 0 {
+      startDate = rows[0].ReportDate
+      endDate = rows[len(rows)-1].ReportDate
+    }
+  }
+
+  resp := buildChartSeries(key, metric, reports.FillDailyGaps(items, startDate, endDate))
+  resp.Timezone = reportsTimezoneLabel(svc)
+  if smooth > 0 {
+    resp.Smooth = smooth
+    resp.NetProfitSat = trailingMovingAverage(resp.NetProfitSat, smooth)
+    resp.VolumeSat = trailingMovingAverage(resp.VolumeSat, smooth)
+  }
+  if cumulative != "" {
+    var profitBase, volumeBase float64
+    if cumulative == reportsCumulativeAll && !startDate.IsZero() {
+      before, err := svc.CustomSummary(ctx, time.Time{}, startDate.AddDate(0, 0, -1))
+      if err != nil {
+        writeReportsLoadError(w, err, "failed to load reports")
+        return
+      }
+      profitBase = metricSats(before.Totals.NetRoutingProfitMsat, before.Totals.NetRoutingProfitSat)
+      volumeBase = metricSats(before.Totals.RoutedVolumeMsat, before.Totals.RoutedVolumeSat)
+    }
+    resp.Cumulative = cumulative
+    resp.NetProfitSat = runningTotal(resp.NetProfitSat, profitBase)
+    resp.VolumeSat = runningTotal(resp.VolumeSat, volumeBase)
+  }
+  writeReportJSON(w, r, resp)
+}
+
+func buildChartSeries(key string, metric string, items []reports.Row) reportChartSeriesResponse {
+  resp := reportChartSeriesResponse{
+    SchemaVersion: reports.SchemaVersion,
+    Range: key,
+    Dates: make([]string, 0, len(items)),
+  }
+  includeProfit := metric == "" || metric == reportsSeriesNetProfit
+  includeVolume := metric == "" || metric == reportsSeriesVolume
+  if includeProfit {
+    resp.NetProfitSat = make([]float64, 0, len(items))
+  }
+  if includeVolume {
+    resp.VolumeSat = make([]float64, 0, len(items))
+  }
+  for _, item := range items {
+    resp.Dates = append(resp.Dates, item.ReportDate.Format("2006-01-02"))
+    if includeProfit {
+      resp.NetProfitSat = append(resp.NetProfitSat, metricSats(item.Metrics.NetRoutingProfitMsat, item.Metrics.NetRoutingProfitSat))
+    }
+    if includeVolume {
+      resp.VolumeSat = append(resp.VolumeSat, metricSats(item.Metrics.RoutedVolumeMsat, item.Metrics.RoutedVolumeSat))
+    }
+  }
+  return resp
+}
+
+func trailingMovingAverage(values []float64, window int) []float64 {
+  if values == nil || window < 2 {
+    return values
+  }
+  out := make([]float64, len(values))
+  sum := 0.0
+  for i, value := range values {
+    sum += value
+    if i >= window {
+      sum -= values[i-window]
+    }
+    size := window
+    if i+1 < window {
+      size = i + 1
+    }
+    out[i] = sum / float64(size)
+  }
+  return out
+}
+
+// runningTotal turns daily values into a cumulative curve starting at base.
+func runningTotal(values []float64, base float64) []float64 {
+  if values == nil {
+    return nil
+  }
+  out := make([]float64, len(values))
+  sum := base
+  for i, value := range values {
+    sum += value
+    out[i] = sum
+  }
+  return out
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+  "math"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+func TestTrailingMovingAverage(t *testing.T) {
+  got := trailingMovingAverage([]float64{3, 6, 9, 12, 0}, 3)
+  want := []float64{3, 4.5, 6, 9, 7}
+  if len(got) != len(want) {
+    t.Fatalf("expected %d points, got %d", len(want), len(got))
+  }
+  for i := range want {
+    if math.Abs(got[i]-want[i]) > 1e-9 {
+      t.Fatalf("point %d: expected %v, got %v", i, want[i], got[i])
+    }
+  }
+
+  if trailingMovingAverage(nil, 7) != nil {
+    t.Fatalf("expected nil series to stay nil")
+  }
+}
+
+func TestRunningTotal(t *testing.T) {
+  got := runningTotal([]float64{10, 0, -4, 2.5}, 100)
+  want := []float64{110, 110, 106, 108.5}
+  for i := range want {
+    if math.Abs(got[i]-want[i]) > 1e-9 {
+      t.Fatalf("point %d: expected %v, got %v", i, want[i], got[i])
+    }
+  }
+  if runningTotal(nil, 5) != nil {
+    t.Fatalf("expected nil series to stay nil")
+  }
+}
+
+func TestParseReportsOrder(t *testing.T) {
+  cases := map[string]reports.SortOrder{
+    "": reports.Ascending,
+    "asc": reports.Ascending,
+    "DESC": reports.Descending,
+  }
+  for raw, want := range cases {
+    req := httptest.NewRequest(http.MethodGet, "/api/reports/custom?order="+raw, nil)
+    got, err := parseReportsOrder(req)
+    if err != nil || got != want {
+      t.Fatalf("order=%q: expected %v, got %v (%v)", raw, want, got, err)
+    }
+  }
+  req := httptest.NewRequest(http.MethodGet, "/api/reports/custom?order=newest", nil)
+  if _, err := parseReportsOrder(req); err == nil {
+    t.Fatalf("expected error for unknown order")
+  }
+}
+
+func TestReportsTimezoneLabel(t *testing.T) {
+  opts := reports.DefaultOptions()
+  opts.Timezone = time.FixedZone("America/Sao_Paulo", -3*60*60)
+  if got := reportsTimezoneLabel(reports.NewService(nil, nil, nil, opts)); got != "America/Sao_Paulo" {
+    t.Fatalf("expected the configured zone, got %q", got)
+  }
+  if got := reportsTimezoneLabel(reports.NewService(nil, nil, nil, reports.DefaultOptions())); got != "Local" {
+    t.Fatalf("expected Local without reports.timezone, got %q", got)
+  }
+}
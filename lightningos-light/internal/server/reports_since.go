@@ -0,0 +1,57 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/reports"
+)
+
+type reportSummarySinceResponse struct {
+  reportSummaryResponse
+  Since string `json:"since"`
+  Cursor string `json:"cursor"`
+}
+
+// handleReportsSummarySince sums only the report days written after since so
+// a poller can fetch deltas; it passes the returned cursor back next time.
+func (s *Server) handleReportsSummarySince(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  var since time.Time
+  if raw := strings.TrimSpace(r.URL.Query().Get("since")); raw != "" {
+    parsed, err := time.Parse(time.RFC3339Nano, raw)
+    if err != nil {
+      writeError(w, http.StatusBadRequest, "since must be an RFC3339 timestamp")
+      return
+    }
+    since = parsed
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+  summary, cursor, err := reports.FetchSummarySince(svc.QueryContext(ctx), s.reportsReadPool(), since)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load report summary")
+    return
+  }
+
+  resp := reportSummarySinceResponse{
+    reportSummaryResponse: summaryResponse("since", reportsTimezoneLabel(svc), summary),
+    Cursor: cursor.UTC().Format(time.RFC3339Nano),
+  }
+  if !since.IsZero() {
+    resp.Since = since.UTC().Format(time.RFC3339Nano)
+  }
+  writeReportJSON(w, r, resp)
+}
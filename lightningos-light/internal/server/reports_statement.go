@@ -0,0 +1,151 @@
+package server
+
+import (
+  "bytes"
+  "context"
+  "html/template"
+  "io"
+  "net/http"
+  "strings"
+  "time"
+)
+
+type reportsStatementData struct {
+  From string
+  To string
+  Timezone string
+  GeneratedAt string
+  Days int64
+  Rows []reportSeriesItem
+  Totals reportMetricsPayload
+}
+
+// Values only reach the page through html/template so they are escaped for
+// their context; do not switch this to text/template.
+var reportsStatementTemplate = template.Must(template.New("statement").Funcs(template.FuncMap{
+  "sats": formatReportFloat,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Routing statement {{.From}} to {{.To}}</title>
+<style>
+body { font-family: sans-serif; font-size: 12px; margin: 24px; color: #111; }
+h1 { font-size: 18px; margin: 0 0 4px; }
+p.meta { margin: 0 0 16px; color: #444; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #999; padding: 4px 6px; }
+td.num, th.num { text-align: right; }
+tfoot td { font-weight: bold; }
+tr { page-break-inside: avoid; }
+thead { display: table-header-group; }
+</style>
+</head>
+<body>
+<h1>Routing statement</h1>
+<p class="meta">Range {{.From}} to {{.To}} ({{.Days}} days, {{.Timezone}}). Generated {{.GeneratedAt}}.</p>
+<table>
+<thead>
+<tr>
+<th>Date</th>
+<th class="num">Forward fee revenue (sats)</th>
+<th class="num">Rebalance fee cost (sats)</th>
+<th class="num">Net routing profit (sats)</th>
+<th class="num">Forwards</th>
+<th class="num">Rebalances</th>
+<th class="num">Routed volume (sats)</th>
+</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>
+<td>{{.Date}}</td>
+<td class="num">{{sats .ForwardFeeRevenueSat}}</td>
+<td class="num">{{sats .RebalanceFeeCostSat}}</td>
+<td class="num">{{sats .NetRoutingProfitSat}}</td>
+<td class="num">{{.ForwardCount}}</td>
+<td class="num">{{.RebalanceCount}}</td>
+<td class="num">{{sats .RoutedVolumeSat}}</td>
+</tr>
+{{- else}}
+<tr><td colspan="7">No report rows in this range.</td></tr>
+{{- end}}
+</tbody>
+<tfoot>
+<tr>
+<td>Total</td>
+<td class="num">{{sats .Totals.ForwardFeeRevenueSat}}</td>
+<td class="num">{{sats .Totals.RebalanceFeeCostSat}}</td>
+<td class="num">{{sats .Totals.NetRoutingProfitSat}}</td>
+<td class="num">{{.Totals.ForwardCount}}</td>
+<td class="num">{{.Totals.RebalanceCount}}</td>
+<td class="num">{{sats .Totals.RoutedVolumeSat}}</td>
+</tr>
+</tfoot>
+</table>
+</body>
+</html>
+`))
+
+// handleReportsStatement renders a range as a standalone HTML page meant for
+// the browser's print-to-PDF.
+func (s *Server) handleReportsStatement(w http.ResponseWriter, r *http.Request) {
+  svc, errMsg := s.reportsService()
+  if svc == nil {
+    msg := strings.TrimSpace(errMsg)
+    if msg == "" {
+      msg = "reports unavailable"
+    }
+    writeError(w, http.StatusServiceUnavailable, msg)
+    return
+  }
+
+  fromStr := strings.TrimSpace(r.URL.Query().Get("from"))
+  toStr := strings.TrimSpace(r.URL.Query().Get("to"))
+  if fromStr == "" || toStr == "" {
+    writeError(w, http.StatusBadRequest, "from and to are required")
+    return
+  }
+  startDate, endDate, err := parseReportsCustomRange(svc, fromStr, toStr)
+  if err != nil {
+    writeError(w, http.StatusBadRequest, err.Error())
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+  defer cancel()
+
+  items, err := svc.CustomRange(ctx, startDate, endDate)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load reports")
+    return
+  }
+  summary, err := svc.CustomSummary(ctx, startDate, endDate)
+  if err != nil {
+    writeReportsLoadError(w, err, "failed to load report summary")
+    return
+  }
+
+  var buf bytes.Buffer
+  err = renderReportsStatement(&buf, reportsStatementData{
+    From: startDate.Format("2006-01-02"),
+    To: endDate.Format("2006-01-02"),
+    Timezone: reportsTimezoneLabel(svc),
+    GeneratedAt: time.Now().Format(time.RFC3339),
+    Days: summary.Days,
+    Rows: mapSeries(items),
+    Totals: metricsPayload(summary.Totals),
+  })
+  if err != nil {
+    writeError(w, http.StatusInternalServerError, "failed to render statement")
+    return
+  }
+  w.Header().Set("Content-Type", "text/html; charset=utf-8")
+  w.Header().Set("Cache-Control", "no-store")
+  w.WriteHeader(http.StatusOK)
+  _, _ = buf.WriteTo(w)
+}
+
+func renderReportsStatement(w io.Writer, data reportsStatementData) error {
+  return reportsStatementTemplate.Execute(w, data)
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+  "bytes"
+  "strings"
+  "testing"
+)
+
+func TestRenderReportsStatement(t *testing.T) {
+  var buf bytes.Buffer
+  err := renderReportsStatement(&buf, reportsStatementData{
+    From: "2026-03-01",
+    To: "2026-03-02",
+    Timezone: `<script>alert("x")</script>`,
+    GeneratedAt: "2026-03-03T10:00:00Z",
+    Days: 2,
+    Rows: []reportSeriesItem{
+      {Date: "2026-03-01", ForwardFeeRevenueSat: 12.5, ForwardCount: 3},
+      {Date: "2026-03-02", ForwardFeeRevenueSat: 7, RebalanceFeeCostSat: 2},
+    },
+    Totals: reportMetricsPayload{ForwardFeeRevenueSat: 19.5, RebalanceFeeCostSat: 2, NetRoutingProfitSat: 17.5, ForwardCount: 3},
+  })
+  if err != nil {
+    t.Fatalf("render failed: %v", err)
+  }
+  out := buf.String()
+  if strings.Contains(out, "<script>") {
+    t.Fatalf("expected values to be escaped: %s", out)
+  }
+  for _, want := range []string{"2026-03-01 to 2026-03-02", "Generated 2026-03-03T10:00:00Z", ">12.5<", ">17.5<", "&lt;script&gt;"} {
+    if !strings.Contains(out, want) {
+      t.Fatalf("expected %q in output", want)
+    }
+  }
+}
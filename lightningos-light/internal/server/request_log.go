@@ -0,0 +1,113 @@
+package server
+
+import (
+  "context"
+  "crypto/rand"
+  "encoding/hex"
+  "encoding/json"
+  "io"
+  "os"
+  "regexp"
+  "sync"
+  "time"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// An incoming X-Request-ID is kept so a proxy's ID carries through; anything
+// that does not look like an ID is replaced rather than echoed into the logs.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+func newRequestID() string {
+  buf := make([]byte, 8)
+  if _, err := rand.Read(buf); err != nil {
+    return "unknown"
+  }
+  return hex.EncodeToString(buf)
+}
+
+func requestIDFor(header string) string {
+  if requestIDPattern.MatchString(header) {
+    return header
+  }
+  return newRequestID()
+}
+
+func withRequestID(ctx context.Context, id string) context.Context {
+  return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// requestIDFromContext returns the ID set by requestLogger, or "" outside a
+// request (background pollers, startup).
+func requestIDFromContext(ctx context.Context) string {
+  if ctx == nil {
+    return ""
+  }
+  id, _ := ctx.Value(requestIDKey{}).(string)
+  return id
+}
+
+type requestLogEntry struct {
+  Time string `json:"time"`
+  RequestID string `json:"request_id"`
+  Method string `json:"method"`
+  Path string `json:"path"`
+  Status int `json:"status"`
+  DurationMs int64 `json:"duration_ms"`
+}
+
+type commandLogEntry struct {
+  Time string `json:"time"`
+  Level string `json:"level"`
+  RequestID string `json:"request_id,omitempty"`
+  Command string `json:"command"`
+  Error string `json:"error"`
+}
+
+var (
+  jsonLogMu sync.Mutex
+  jsonLogOut io.Writer = os.Stdout
+)
+
+// setJSONLogOutput points the JSON line logs at the server logger's writer. It
+// is called from New, before any request is served.
+func setJSONLogOutput(w io.Writer) {
+  jsonLogMu.Lock()
+  defer jsonLogMu.Unlock()
+  if w == nil {
+    w = os.Stdout
+  }
+  jsonLogOut = w
+}
+
+func writeJSONLog(entry any) {
+  line, err := json.Marshal(entry)
+  if err != nil {
+    return
+  }
+  line = append(line, '\n')
+  jsonLogMu.Lock()
+  defer jsonLogMu.Unlock()
+  _, _ = jsonLogOut.Write(line)
+}
+
+func logTime(t time.Time) string {
+  return t.UTC().Format(time.RFC3339Nano)
+}
+
+// logCommandError records a failed helper command with the request ID from
+// ctx so it can be matched to the access log line of the request that ran it.
+func logCommandError(ctx context.Context, command string, err error) {
+  if err == nil {
+    return
+  }
+  writeJSONLog(commandLogEntry{
+    Time: logTime(time.Now()),
+    Level: "error",
+    RequestID: requestIDFromContext(ctx),
+    Command: command,
+    Error: err.Error(),
+  })
+}
@@ -13,11 +13,14 @@ func (s *Server) routes() http.Handler {
   r := chi.NewRouter()
   r.Use(middleware.Recoverer)
   r.Use(s.requestLogger())
+  limited := r.With(s.statusRateLimiter().middleware)
 
   r.Get("/api/health", s.handleHealth)
+  r.Get("/healthz", s.handleHealthz)
   r.Get("/api/amboss/health", s.handleAmbossHealthGet)
   r.Post("/api/amboss/health", s.handleAmbossHealthPost)
   r.Get("/api/system", s.handleSystem)
+  r.Get("/api/system/units", s.handleSystemUnits)
   r.Get("/api/disk", s.handleDisk)
   r.Get("/api/postgres", s.handlePostgres)
   r.Get("/api/bitcoin", s.handleBitcoin)
@@ -25,13 +28,22 @@ func (s *Server) routes() http.Handler {
   r.Get("/api/bitcoin/source", s.handleBitcoinSourceGet)
   r.Post("/api/bitcoin/source", s.handleBitcoinSourcePost)
   r.Get("/api/mempool/fees", s.handleMempoolFees)
-  r.Get("/api/bitcoin-local/status", s.handleBitcoinLocalStatus)
+  limited.Get("/api/bitcoin-local/status", s.handleBitcoinLocalStatus)
   r.Get("/api/bitcoin-local/config", s.handleBitcoinLocalConfigGet)
   r.Post("/api/bitcoin-local/config", s.handleBitcoinLocalConfigPost)
-  r.Get("/api/elements/status", s.handleElementsStatus)
+  limited.Get("/api/elements/status", s.handleElementsStatus)
+  limited.Get("/api/elements/peers", s.handleElementsPeers)
+  r.Get("/api/elements/status/stream", s.handleElementsStatusStream)
+  r.Get("/api/elements/history", s.handleElementsHistory)
+  r.Get("/api/elements/assets", s.handleElementsAssets)
+  r.Get("/api/elements/chaintips", s.handleElementsChainTips)
+  r.Get("/api/elements/tip", s.handleElementsTip)
+  r.Post("/api/elements/rpc", s.handleElementsRPC)
   r.Get("/api/elements/mainchain", s.handleElementsMainchainGet)
   r.Post("/api/elements/mainchain", s.handleElementsMainchainPost)
-  r.Get("/api/lnd/status", s.handleLNDStatus)
+  r.With(requireControlToken).Post("/api/elements/control", s.handleElementsControl)
+  r.Get("/api/audit/control", s.handleControlAudit)
+  limited.Get("/api/lnd/status", s.handleLNDStatus)
   r.Get("/api/lnd/config", s.handleLNDConfigGet)
   r.Get("/api/wizard/status", s.handleWizardStatus)
   r.Post("/api/wizard/bitcoin-remote", s.handleWizardBitcoinRemote)
@@ -54,13 +66,34 @@ func (s *Server) routes() http.Handler {
   r.Get("/api/notifications/backup/telegram", s.handleTelegramBackupGet)
   r.Post("/api/notifications/backup/telegram", s.handleTelegramBackupPost)
   r.Post("/api/notifications/backup/telegram/test", s.handleTelegramBackupTest)
-  r.Get("/api/reports/range", s.handleReportsRange)
-  r.Get("/api/reports/custom", s.handleReportsCustom)
-  r.Get("/api/reports/summary", s.handleReportsSummary)
-  r.Get("/api/reports/live", s.handleReportsLive)
+  r.Group(func(r chi.Router) {
+    r.Use(gzipMiddleware(gzipMinSize))
+    r.Get("/api/reports/range", s.handleReportsRange)
+    r.Get("/api/reports/custom", s.handleReportsCustom)
+    r.Get("/api/reports/series", s.handleReportsSeries)
+    r.Get("/api/reports/summary", s.handleReportsSummary)
+    r.Get("/api/reports/summary/quick", s.handleReportsQuickSummary)
+    r.Get("/api/reports/summary/since", s.handleReportsSummarySince)
+    r.Get("/api/reports/compare", s.handleReportsCompare)
+    r.Get("/api/reports/live", s.handleReportsLive)
+    r.Get("/api/reports/today", s.handleReportsToday)
+    r.Get("/api/reports/anomalies", s.handleReportsAnomalies)
+    r.Get("/api/reports/rebalance-budget", s.handleReportsRebalanceBudget)
+    r.Get("/api/reports/channels/top", s.handleReportsTopChannels)
+    r.Get("/api/reports/statement", s.handleReportsStatement)
+  })
   r.Get("/api/reports/config", s.handleReportsConfigGet)
   r.Post("/api/reports/config", s.handleReportsConfigPost)
-  r.Get("/api/terminal/status", s.handleTerminalStatus)
+  r.With(requireControlToken).Post("/api/reports/import", s.handleReportsImportCSV)
+  r.With(requireControlToken).Post("/api/reports/export-links", s.handleReportsExportLinkCreate)
+  r.Get("/api/reports/export", s.handleReportsExportDownload)
+  r.Get("/api/reports/reconcile", s.handleReportsReconcile)
+  r.Get("/api/reports/health", s.handleReportsHealth)
+  limited.Get("/api/terminal/status", s.handleTerminalStatus)
+  r.With(requireControlToken).Post("/api/terminal/credential/rotate", s.handleTerminalRotateCredential)
+  r.Get("/api/terminal/sessions", s.handleTerminalSessions)
+  r.With(requireControlToken).Post("/api/terminal/sessions/{id}/kill", s.handleTerminalKill)
+  r.Get("/metrics", s.handleMetrics)
 
   r.Route("/api/onchain", func(r chi.Router) {
     r.Get("/utxos", s.handleOnchainUtxos)
@@ -3,10 +3,14 @@ package server
 import (
   "context"
   "crypto/tls"
+  "errors"
   "fmt"
   "log"
   "net/http"
+  "os"
+  "os/signal"
   "sync"
+  "syscall"
   "time"
 
   "lightningos-light/internal/config"
@@ -16,6 +20,11 @@ import (
   "github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+  shutdownTimeout = 10 * time.Second
+  reportsFlushTimeout = 15 * time.Second
+)
+
 type Server struct {
   cfg    *config.Config
   logger *log.Logger
@@ -28,9 +37,26 @@ type Server struct {
   reports *reports.Service
   reportsErr string
   reportsOnce sync.Once
+  reportsReadDB *pgxpool.Pool
   lndRestartMu sync.RWMutex
   lastLNDRestart time.Time
   walletActivityMu sync.Mutex
+  terminalRotateMu sync.Mutex
+  terminalClientsMu sync.Mutex
+  terminalClients map[int64]terminalClient
+  terminalClientSeq int64
+  elementsStatusMu sync.Mutex
+  elementsStatusCache *elementsStatus
+  elementsStatusExpires time.Time
+  elementsDiskMu sync.Mutex
+  elementsDiskDir string
+  elementsDiskBytes int64
+  elementsDiskExpires time.Time
+  shutdownDone chan struct{}
+  elementsControlKeys idempotencyCache
+  elementsStreamMu sync.Mutex
+  elementsStreamSubs int
+  audit controlAuditSink
 }
 
 func New(cfg *config.Config, logger *log.Logger) *Server {
@@ -38,6 +64,11 @@ func New(cfg *config.Config, logger *log.Logger) *Server {
     cfg:    cfg,
     logger: logger,
     lnd:    lndclient.New(cfg, logger),
+    shutdownDone: make(chan struct{}),
+  }
+  setSystemdConcurrency(cfg.Systemd.MaxConcurrent)
+  if logger != nil {
+    setJSONLogOutput(logger.Writer())
   }
   srv.chat = NewChatService(srv.lnd, logger)
   srv.amboss = NewAmbossHealthChecker(srv.lnd, logger)
@@ -47,6 +78,8 @@ func New(cfg *config.Config, logger *log.Logger) *Server {
 func (s *Server) Run() error {
   s.initNotifications()
   s.initReports()
+  s.startElementsSnapshotter()
+  s.initControlAudit()
   if s.chat != nil {
     s.chat.Start()
   }
@@ -67,8 +100,41 @@ func (s *Server) Run() error {
     TLSConfig:         tlsCfg,
   }
 
+  ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+  defer stop()
+  go func() {
+    <-ctx.Done()
+    s.shutdown(httpServer)
+  }()
+
   s.logger.Printf("listening on https://%s", addr)
-  return httpServer.ListenAndServeTLS(s.cfg.Server.TLSCert, s.cfg.Server.TLSKey)
+  err := httpServer.ListenAndServeTLS(s.cfg.Server.TLSCert, s.cfg.Server.TLSKey)
+  if errors.Is(err, http.ErrServerClosed) {
+    <-s.shutdownDone
+    return nil
+  }
+  return err
+}
+
+func (s *Server) shutdown(httpServer *http.Server) {
+  defer close(s.shutdownDone)
+  s.logger.Printf("shutting down")
+
+  ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+  defer cancel()
+  if err := httpServer.Shutdown(ctx); err != nil {
+    s.logger.Printf("shutdown: http server: %v", err)
+  }
+  if s.reports != nil {
+    flushCtx, flushCancel := context.WithTimeout(context.Background(), reportsFlushTimeout)
+    defer flushCancel()
+    if err := s.reports.Flush(flushCtx, time.Now(), s.reports.Location()); err != nil {
+      s.logger.Printf("shutdown: reports flush failed: %v", err)
+    }
+  }
+  if s.reportsReadDB != nil {
+    s.reportsReadDB.Close()
+  }
 }
 
 func (s *Server) initNotifications() {
@@ -91,6 +157,7 @@ func (s *Server) initNotifications() {
 
   s.db = pool
   s.notifier = NewNotifier(pool, s.lnd, s.logger)
+  s.notifier.storeEvents = s.cfg.Reports.StoreEvents
   s.notifierErr = ""
   s.notifier.Start()
   if s.chat != nil {
@@ -0,0 +1,41 @@
+package server
+
+import (
+  "context"
+  "net/http"
+  "sync"
+  "time"
+)
+
+type systemUnitStatus struct {
+  Unit string `json:"unit"`
+  Status string `json:"status"`
+  Error string `json:"error,omitempty"`
+}
+
+func (s *Server) handleSystemUnits(w http.ResponseWriter, r *http.Request) {
+  var units []string
+  if s.cfg != nil {
+    units = s.cfg.Systemd.StatusUnits
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 8*time.Second)
+  defer cancel()
+
+  items := make([]systemUnitStatus, len(units))
+  var wg sync.WaitGroup
+  for i, unit := range units {
+    wg.Add(1)
+    go func(i int, unit string) {
+      defer wg.Done()
+      status, err := systemdUnitStatus(ctx, unit)
+      items[i] = systemUnitStatus{Unit: unit, Status: status}
+      if err != nil {
+        items[i].Error = "status check failed"
+      }
+    }(i, unit)
+  }
+  wg.Wait()
+
+  writeJSON(w, http.StatusOK, map[string]any{"units": items})
+}
@@ -2,12 +2,231 @@ package server
 
 import (
   "context"
+  "strings"
+  "time"
 
   "lightningos-light/internal/system"
 )
 
+var systemdTransientErrors = []string{
+  "connection reset by peer",
+  "transport endpoint is not connected",
+  "failed to connect to bus",
+  "connection timed out",
+}
+
+var systemdRetryBaseDelay = 250 * time.Millisecond
+
+const (
+  systemdStatusRunning = "running"
+  systemdStatusStopped = "stopped"
+  systemdStatusFailed = "failed"
+  systemdStatusUnknown = "unknown"
+)
+
+const defaultSystemdMaxConcurrent = 4
+
+// systemdStatusAttempts is how many times the read-only status calls are tried
+// before a transient bus error is reported.
+const systemdStatusAttempts = 3
+
+// systemdSemaphore caps how many systemd-run processes are alive at once so a
+// burst of dashboard requests queues up instead of forking a process each.
+type systemdSemaphore struct {
+  slots chan struct{}
+}
+
+func newSystemdSemaphore(n int) *systemdSemaphore {
+  if n < 1 {
+    n = defaultSystemdMaxConcurrent
+  }
+  return &systemdSemaphore{slots: make(chan struct{}, n)}
+}
+
+// acquire blocks until a slot frees or ctx is done.
+func (s *systemdSemaphore) acquire(ctx context.Context) error {
+  select {
+  case s.slots <- struct{}{}:
+    return nil
+  case <-ctx.Done():
+    return ctx.Err()
+  }
+}
+
+func (s *systemdSemaphore) release() {
+  <-s.slots
+}
+
+var systemdLimiter = newSystemdSemaphore(defaultSystemdMaxConcurrent)
+
+// setSystemdConcurrency replaces the global limit. It is called from New,
+// before any request can spawn systemd-run.
+func setSystemdConcurrency(n int) {
+  systemdLimiter = newSystemdSemaphore(n)
+}
+
+func withSystemdSlot(ctx context.Context, run func() error) error {
+  sem := systemdLimiter
+  if err := sem.acquire(ctx); err != nil {
+    return err
+  }
+  defer sem.release()
+  return run()
+}
+
 func runSystemd(ctx context.Context, args ...string) (string, error) {
+  out, err := systemdRun(ctx, args...)
+  if err != nil {
+    logCommandError(ctx, "systemd-run "+systemdCommandName(args), err)
+  }
+  return out, err
+}
+
+// runSystemdRetry is runSystemd for idempotent reads (is-active, config cats):
+// transient bus errors are retried up to attempts times and only the final
+// failure is logged. Never use it for commands that change state.
+func runSystemdRetry(ctx context.Context, attempts int, args ...string) (string, error) {
+  out, err := retryTransient(ctx, attempts, func() (string, error) {
+    return systemdRun(ctx, args...)
+  })
+  if err != nil {
+    logCommandError(ctx, "systemd-run "+systemdCommandName(args), err)
+  }
+  return out, err
+}
+
+// systemdRun is runSystemd without the error log, for callers that log the
+// failure with more context themselves.
+func systemdRun(ctx context.Context, args ...string) (string, error) {
+  var out string
+  err := withSystemdSlot(ctx, func() error {
+    var err error
+    out, err = system.RunCommandWithSudo(ctx, "systemd-run", systemdRunArgs(args)...)
+    return err
+  })
+  return out, err
+}
+
+// systemdUnitStatus runs systemctl is-active for unit and normalizes the state
+// to running, stopped, failed or unknown. is-active exits non-zero for every
+// state but active, so the error is only returned (and logged) when no state
+// was printed.
+func systemdUnitStatus(ctx context.Context, unit string) (string, error) {
+  out, err := retryTransient(ctx, systemdStatusAttempts, func() (string, error) {
+    return systemdRun(ctx, "systemctl", "is-active", unit)
+  })
+  return systemdUnitState(ctx, out, err)
+}
+
+func systemdUnitState(ctx context.Context, out string, err error) (string, error) {
+  status := normalizeSystemdState(out)
+  if err != nil && status == systemdStatusUnknown {
+    logCommandError(ctx, "systemd-run systemctl is-active", err)
+    return status, err
+  }
+  return status, nil
+}
+
+func normalizeSystemdState(state string) string {
+  switch strings.TrimSpace(state) {
+  case "active", "activating", "reloading":
+    return systemdStatusRunning
+  case "inactive", "deactivating":
+    return systemdStatusStopped
+  case "failed":
+    return systemdStatusFailed
+  default:
+    return systemdStatusUnknown
+  }
+}
+
+// RunSystemdStream is runSystemd for long commands (e.g. an Elements reindex):
+// onLine gets each output line as it arrives instead of one buffered string.
+// It shares the systemd-run slots and is retried under sudo only when the
+// first run was refused.
+func RunSystemdStream(ctx context.Context, onLine func(string), args ...string) error {
+  err := withSystemdSlot(ctx, func() error {
+    return system.RunCommandStreamWithSudo(ctx, onLine, "systemd-run", systemdRunArgs(args)...)
+  })
+  if err != nil {
+    logCommandError(ctx, "systemd-run "+systemdCommandName(args), err)
+  }
+  return err
+}
+
+// systemdRunValueFlags are the systemd-run options callers pass with their
+// value as a separate argument.
+var systemdRunValueFlags = map[string]bool{
+  "--uid": true,
+  "--gid": true,
+  "--unit": true,
+  "-u": true,
+  "--property": true,
+  "-p": true,
+  "--setenv": true,
+  "-E": true,
+}
+
+// systemdCommandName names a systemd-run call for logs without its arguments,
+// which can carry shell scripts with secrets. systemctl keeps its verb.
+func systemdCommandName(args []string) string {
+  for i := 0; i < len(args); i++ {
+    arg := args[i]
+    if strings.HasPrefix(arg, "-") {
+      if systemdRunValueFlags[arg] {
+        i++
+      }
+      continue
+    }
+    if arg == "systemctl" && i+1 < len(args) {
+      return arg + " " + args[i+1]
+    }
+    return arg
+  }
+  return ""
+}
+
+func systemdRunArgs(args []string) []string {
   base := []string{"--quiet", "--wait", "--pipe", "--collect"}
-  full := append(base, args...)
-  return system.RunCommandWithSudo(ctx, "systemd-run", full...)
+  return append(base, args...)
+}
+
+func retryTransient(ctx context.Context, attempts int, run func() (string, error)) (string, error) {
+  if attempts < 1 {
+    attempts = 1
+  }
+  delay := systemdRetryBaseDelay
+  var out string
+  var err error
+  for attempt := 1; attempt <= attempts; attempt++ {
+    out, err = run()
+    if err == nil || !isTransientSystemdError(out, err) || attempt == attempts {
+      return out, err
+    }
+    if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+      return out, err
+    }
+    timer := time.NewTimer(delay)
+    select {
+    case <-ctx.Done():
+      timer.Stop()
+      return out, err
+    case <-timer.C:
+    }
+    delay *= 2
+  }
+  return out, err
+}
+
+func isTransientSystemdError(out string, err error) bool {
+  if err == nil {
+    return false
+  }
+  msg := strings.ToLower(out + " " + err.Error())
+  for _, pattern := range systemdTransientErrors {
+    if strings.Contains(msg, pattern) {
+      return true
+    }
+  }
+  return false
 }
@@ -0,0 +1,208 @@
+package server
+
+import (
+  "bytes"
+  "context"
+  "errors"
+  "strings"
+  "sync"
+  "testing"
+  "time"
+)
+
+func TestRetryTransient(t *testing.T) {
+  prev := systemdRetryBaseDelay
+  systemdRetryBaseDelay = time.Millisecond
+  t.Cleanup(func() { systemdRetryBaseDelay = prev })
+
+  t.Run("transient then success", func(t *testing.T) {
+    calls := 0
+    out, err := retryTransient(context.Background(), 3, func() (string, error) {
+      calls++
+      if calls < 3 {
+        return "Failed to connect to bus: Connection reset by peer", errors.New("systemd-run failed: exit status 1")
+      }
+      return "active", nil
+    })
+    if err != nil || out != "active" {
+      t.Fatalf("expected success, got %q, %v", out, err)
+    }
+    if calls != 3 {
+      t.Fatalf("expected 3 calls, got %d", calls)
+    }
+  })
+
+  t.Run("non-transient fails immediately", func(t *testing.T) {
+    calls := 0
+    _, err := retryTransient(context.Background(), 3, func() (string, error) {
+      calls++
+      return "", errors.New("exec: \"systemd-run\": executable file not found in $PATH")
+    })
+    if err == nil {
+      t.Fatalf("expected error")
+    }
+    if calls != 1 {
+      t.Fatalf("expected 1 call, got %d", calls)
+    }
+  })
+
+  t.Run("exhausted returns last error", func(t *testing.T) {
+    calls := 0
+    _, err := retryTransient(context.Background(), 2, func() (string, error) {
+      calls++
+      return "", errors.New("connection reset by peer")
+    })
+    if err == nil {
+      t.Fatalf("expected error")
+    }
+    if calls != 2 {
+      t.Fatalf("expected 2 calls, got %d", calls)
+    }
+  })
+}
+
+func TestNormalizeSystemdState(t *testing.T) {
+  cases := map[string]string{
+    "active\n": systemdStatusRunning,
+    "activating": systemdStatusRunning,
+    "reloading": systemdStatusRunning,
+    "inactive\n": systemdStatusStopped,
+    "deactivating": systemdStatusStopped,
+    "failed": systemdStatusFailed,
+    "": systemdStatusUnknown,
+    "Failed to connect to bus": systemdStatusUnknown,
+  }
+  for state, want := range cases {
+    if got := normalizeSystemdState(state); got != want {
+      t.Fatalf("%q: expected %s, got %s", state, want, got)
+    }
+  }
+}
+
+func TestSystemdUnitStateLogsOnlyUnknown(t *testing.T) {
+  var buf bytes.Buffer
+  setJSONLogOutput(&buf)
+  defer setJSONLogOutput(nil)
+
+  exitErr := errors.New("exit status 3")
+  status, err := systemdUnitState(context.Background(), "inactive\n", exitErr)
+  if status != systemdStatusStopped || err != nil {
+    t.Fatalf("expected stopped without error, got %s %v", status, err)
+  }
+  if buf.Len() != 0 {
+    t.Fatalf("expected no log for a parsed state, got %q", buf.String())
+  }
+
+  status, err = systemdUnitState(context.Background(), "", exitErr)
+  if status != systemdStatusUnknown || !errors.Is(err, exitErr) {
+    t.Fatalf("expected unknown with the error, got %s %v", status, err)
+  }
+  if !strings.Contains(buf.String(), "systemctl is-active") {
+    t.Fatalf("expected the failure to be logged, got %q", buf.String())
+  }
+}
+
+func TestSystemdSemaphoreLimitsConcurrency(t *testing.T) {
+  const limit = 2
+  sem := newSystemdSemaphore(limit)
+  var mu sync.Mutex
+  active, peak := 0, 0
+  var wg sync.WaitGroup
+  for i := 0; i < limit+1; i++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      if err := sem.acquire(context.Background()); err != nil {
+        t.Errorf("acquire failed: %v", err)
+        return
+      }
+      defer sem.release()
+      mu.Lock()
+      active++
+      peak = max(peak, active)
+      mu.Unlock()
+      time.Sleep(20 * time.Millisecond)
+      mu.Lock()
+      active--
+      mu.Unlock()
+    }()
+  }
+  wg.Wait()
+  if peak != limit {
+    t.Fatalf("expected at most %d concurrent calls, saw %d", limit, peak)
+  }
+}
+
+func TestSystemdSemaphoreRespectsContext(t *testing.T) {
+  sem := newSystemdSemaphore(1)
+  if err := sem.acquire(context.Background()); err != nil {
+    t.Fatalf("acquire failed: %v", err)
+  }
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+  defer cancel()
+  if err := sem.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+    t.Fatalf("expected deadline error while slot is held, got %v", err)
+  }
+  sem.release()
+  if err := sem.acquire(context.Background()); err != nil {
+    t.Fatalf("expected slot after release, got %v", err)
+  }
+}
+
+func TestRunSystemdStreamWaitsForSlot(t *testing.T) {
+  var buf bytes.Buffer
+  setJSONLogOutput(&buf)
+  defer setJSONLogOutput(nil)
+  setSystemdConcurrency(1)
+  defer setSystemdConcurrency(defaultSystemdMaxConcurrent)
+  if err := systemdLimiter.acquire(context.Background()); err != nil {
+    t.Fatalf("acquire failed: %v", err)
+  }
+  defer systemdLimiter.release()
+
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+  defer cancel()
+  var lines []string
+  err := RunSystemdStream(ctx, func(line string) { lines = append(lines, line) }, "--uid", "losop", "elements-cli", "reindex")
+  if !errors.Is(err, context.DeadlineExceeded) || len(lines) != 0 {
+    t.Fatalf("expected to give up waiting for a slot, got %v %v", err, lines)
+  }
+  if !strings.Contains(buf.String(), "systemd-run elements-cli") {
+    t.Fatalf("expected the failure to be logged, got %q", buf.String())
+  }
+}
+
+func TestSystemdCommandName(t *testing.T) {
+  cases := map[string][]string{
+    "systemctl restart": {"systemctl", "restart", "elementsd"},
+    "/bin/sh": {"/bin/sh", "-c", "rm -rf /tmp/x"},
+    "/opt/elements/bin/elements-cli": {"--uid", "elements", "/opt/elements/bin/elements-cli", "getblockcount"},
+  }
+  for want, args := range cases {
+    if got := systemdCommandName(args); got != want {
+      t.Fatalf("%v: expected %q, got %q", args, want, got)
+    }
+  }
+}
+
+func TestRunSystemdRetryLogsOnce(t *testing.T) {
+  var buf bytes.Buffer
+  setJSONLogOutput(&buf)
+  defer setJSONLogOutput(nil)
+  setSystemdConcurrency(1)
+  defer setSystemdConcurrency(defaultSystemdMaxConcurrent)
+  if err := systemdLimiter.acquire(context.Background()); err != nil {
+    t.Fatalf("acquire failed: %v", err)
+  }
+  defer systemdLimiter.release()
+
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+  defer cancel()
+  _, err := runSystemdRetry(ctx, systemdStatusAttempts, "systemctl", "is-active", "elementsd")
+  if !errors.Is(err, context.DeadlineExceeded) {
+    t.Fatalf("expected to give up waiting for a slot, got %v", err)
+  }
+  if n := strings.Count(buf.String(), "systemd-run systemctl is-active"); n != 1 {
+    t.Fatalf("expected one log line, got %d: %q", n, buf.String())
+  }
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+  "crypto/subtle"
+  "net"
+  "net/http"
+  "net/netip"
+  "os"
+  "strings"
+)
+
+func terminalAllowWrite() bool {
+  return strings.TrimSpace(os.Getenv("TERMINAL_ALLOW_WRITE")) == "1"
+}
+
+func terminalWriteUsers() []string {
+  raw := strings.TrimSpace(os.Getenv("TERMINAL_WRITE_USERS"))
+  if raw == "" {
+    return nil
+  }
+  var users []string
+  for _, part := range strings.Split(raw, ",") {
+    if user := strings.TrimSpace(part); user != "" {
+      users = append(users, user)
+    }
+  }
+  return users
+}
+
+func terminalWriteAllowed(user string, users []string, allowWrite bool) bool {
+  if len(users) == 0 {
+    return allowWrite
+  }
+  user = strings.TrimSpace(user)
+  if user == "" {
+    return false
+  }
+  return stringInSlice(user, users)
+}
+
+// terminalRequestUser returns the caller's verified identity, or "" when there
+// is none. Basic auth counts only when it matches TERMINAL_CREDENTIAL.
+// X-Forwarded-User and Remote-User can be sent by any client, so they are read
+// only when the peer address is listed in TERMINAL_TRUSTED_PROXIES.
+func terminalRequestUser(r *http.Request) string {
+  if user, password, ok := r.BasicAuth(); ok {
+    if terminalCredentialMatches(user, password, os.Getenv("TERMINAL_CREDENTIAL")) {
+      return strings.TrimSpace(user)
+    }
+    return ""
+  }
+  if !terminalTrustedPeer(r.RemoteAddr, terminalTrustedProxies()) {
+    return ""
+  }
+  for _, header := range []string{"X-Forwarded-User", "Remote-User"} {
+    if user := strings.TrimSpace(r.Header.Get(header)); user != "" {
+      return user
+    }
+  }
+  return ""
+}
+
+func terminalCredentialMatches(user string, password string, credential string) bool {
+  wantUser, wantPassword, ok := strings.Cut(strings.TrimSpace(credential), ":")
+  if !ok || wantUser == "" || wantPassword == "" {
+    return false
+  }
+  userOK := subtle.ConstantTimeCompare([]byte(strings.TrimSpace(user)), []byte(wantUser)) == 1
+  passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(wantPassword)) == 1
+  return userOK && passwordOK
+}
+
+// terminalTrustedProxies parses TERMINAL_TRUSTED_PROXIES, a comma separated
+// list of IPs or CIDRs. Invalid entries are skipped.
+func terminalTrustedProxies() []netip.Prefix {
+  raw := strings.TrimSpace(os.Getenv("TERMINAL_TRUSTED_PROXIES"))
+  if raw == "" {
+    return nil
+  }
+  var prefixes []netip.Prefix
+  for _, part := range strings.Split(raw, ",") {
+    part = strings.TrimSpace(part)
+    if part == "" {
+      continue
+    }
+    if prefix, err := netip.ParsePrefix(part); err == nil {
+      prefixes = append(prefixes, prefix.Masked())
+      continue
+    }
+    if addr, err := netip.ParseAddr(part); err == nil {
+      addr = addr.Unmap()
+      prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+    }
+  }
+  return prefixes
+}
+
+func terminalTrustedPeer(remoteAddr string, proxies []netip.Prefix) bool {
+  if len(proxies) == 0 {
+    return false
+  }
+  host, _, err := net.SplitHostPort(remoteAddr)
+  if err != nil {
+    host = remoteAddr
+  }
+  addr, err := netip.ParseAddr(host)
+  if err != nil {
+    return false
+  }
+  addr = addr.Unmap()
+  for _, prefix := range proxies {
+    if prefix.Contains(addr) {
+      return true
+    }
+  }
+  return false
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+  "net/http"
+  "net/http/httptest"
+  "testing"
+)
+
+func TestTerminalWriteAllowed(t *testing.T) {
+  if !terminalWriteAllowed("", nil, true) {
+    t.Fatalf("empty allowlist should fall back to global allow")
+  }
+  if terminalWriteAllowed("losop", nil, false) {
+    t.Fatalf("empty allowlist should fall back to global deny")
+  }
+  users := []string{"losop", "admin"}
+  if !terminalWriteAllowed("admin", users, false) {
+    t.Fatalf("listed user should be allowed")
+  }
+  if terminalWriteAllowed("guest", users, true) {
+    t.Fatalf("unlisted user should be denied")
+  }
+  if terminalWriteAllowed("", users, true) {
+    t.Fatalf("anonymous user should be denied when allowlist is set")
+  }
+}
+
+func TestTerminalRequestUser(t *testing.T) {
+  t.Setenv("TERMINAL_CREDENTIAL", "losop:secret")
+  t.Setenv("TERMINAL_TRUSTED_PROXIES", "10.0.0.0/8, 127.0.0.1")
+
+  req := httptest.NewRequest("GET", "/api/terminal/status", nil)
+  req.SetBasicAuth("losop", "secret")
+  req.Header.Set("X-Forwarded-User", "proxy-user")
+  if got := terminalRequestUser(req); got != "losop" {
+    t.Fatalf("expected basic auth user, got %q", got)
+  }
+
+  req = httptest.NewRequest("GET", "/api/terminal/status", nil)
+  req.RemoteAddr = "10.1.2.3:4000"
+  req.Header.Set("Remote-User", "admin")
+  if got := terminalRequestUser(req); got != "admin" {
+    t.Fatalf("expected Remote-User from trusted proxy, got %q", got)
+  }
+}
+
+func TestTerminalRequestUserRejectsSpoofing(t *testing.T) {
+  t.Setenv("TERMINAL_CREDENTIAL", "losop:secret")
+  t.Setenv("TERMINAL_TRUSTED_PROXIES", "127.0.0.1")
+  t.Setenv("TERMINAL_WRITE_USERS", "admin")
+
+  req := httptest.NewRequest("POST", "/api/terminal/sessions/1234/kill", nil)
+  req.RemoteAddr = "192.168.1.50:5000"
+  req.Header.Set("X-Forwarded-User", "admin")
+  if got := terminalRequestUser(req); got != "" {
+    t.Fatalf("expected header from untrusted peer to be ignored, got %q", got)
+  }
+
+  req = httptest.NewRequest("POST", "/api/terminal/sessions/1234/kill", nil)
+  req.SetBasicAuth("admin", "guess")
+  if got := terminalRequestUser(req); got != "" {
+    t.Fatalf("expected unverified basic auth to be ignored, got %q", got)
+  }
+
+  req = httptest.NewRequest("POST", "/api/terminal/sessions/1234/kill", nil)
+  req.RemoteAddr = "192.168.1.50:5000"
+  req.Header.Set("X-Forwarded-User", "admin")
+  rec := httptest.NewRecorder()
+  (&Server{}).handleTerminalKill(rec, req)
+  if rec.Code != http.StatusForbidden {
+    t.Fatalf("expected spoofed user to be refused, got %d", rec.Code)
+  }
+
+  t.Setenv("TERMINAL_TRUSTED_PROXIES", "")
+  req = httptest.NewRequest("GET", "/api/terminal/status", nil)
+  req.RemoteAddr = "127.0.0.1:5000"
+  req.Header.Set("Remote-User", "admin")
+  if got := terminalRequestUser(req); got != "" {
+    t.Fatalf("expected proxy headers to be ignored without trusted proxies, got %q", got)
+  }
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+  "context"
+  "crypto/rand"
+  "fmt"
+  "math"
+  "math/big"
+  "net/http"
+  "os"
+  "strconv"
+  "strings"
+  "time"
+)
+
+const (
+  terminalServiceName = "lightningos-terminal"
+  terminalCredentialDefaultLength = 24
+  terminalCredentialMinLength = 16
+  terminalCredentialMaxLength = 128
+  terminalCredentialMinEntropyBits = 90
+  terminalCredentialAlnum = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+  // No quotes, spaces, '$', '=' or ':' so the value stays safe in secrets.env
+  // and in the user:password credential.
+  terminalCredentialSymbols = "-_.~!@%^*+"
+)
+
+type terminalCredentialPolicy struct {
+  Length int `json:"length"`
+  Symbols bool `json:"symbols"`
+  AlphabetSize int `json:"alphabet_size"`
+  EntropyBits float64 `json:"entropy_bits"`
+  MinLength int `json:"min_length"`
+  Valid bool `json:"valid"`
+}
+
+type terminalRotateResponse struct {
+  OK bool `json:"ok"`
+  Credential string `json:"credential"`
+}
+
+func (s *Server) handleTerminalRotateCredential(w http.ResponseWriter, r *http.Request) {
+  if !s.terminalRotateMu.TryLock() {
+    writeErrorCode(w, http.StatusConflict, "terminal_rotation_in_progress", "credential rotation already in progress")
+    return
+  }
+  defer s.terminalRotateMu.Unlock()
+
+  result := controlAuditResultFailed
+  defer func() {
+    s.recordControlAudit(r, "terminal.rotate_credential", result)
+  }()
+
+  policy, err := terminalCredentialPolicyFromEnv()
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "terminal_credential_policy_invalid", err.Error())
+    return
+  }
+  password, err := generateTerminalPassword(policy)
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "terminal_credential_generate_failed", "failed to generate credential")
+    return
+  }
+  credential := terminalCredentialUser() + ":" + password
+
+  if err := ensureSecretsDir(); err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "secrets_unavailable", "failed to prepare secrets")
+    return
+  }
+  if err := writeEnvFileValue(secretsPath, "TERMINAL_CREDENTIAL", credential); err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "terminal_credential_store_failed", "failed to store credential")
+    return
+  }
+  _ = os.Setenv("TERMINAL_CREDENTIAL", credential)
+
+  ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+  defer cancel()
+  if _, err := runSystemd(ctx, "systemctl", "restart", terminalServiceName); err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "terminal_restart_failed", "terminal restart failed")
+    return
+  }
+
+  result = controlAuditResultOK
+  writeJSON(w, http.StatusOK, terminalRotateResponse{OK: true, Credential: credential})
+}
+
+func terminalCredentialUser() string {
+  if parts := strings.SplitN(terminalCredential(), ":", 2); len(parts) == 2 && parts[0] != "" {
+    return parts[0]
+  }
+  if user := strings.TrimSpace(os.Getenv("TERMINAL_OPERATOR_USER")); user != "" {
+    return user
+  }
+  return "losop"
+}
+
+func terminalCredentialPolicyFromEnv() (terminalCredentialPolicy, error) {
+  length := terminalCredentialDefaultLength
+  if raw := strings.TrimSpace(os.Getenv("TERMINAL_CREDENTIAL_LENGTH")); raw != "" {
+    parsed, err := strconv.Atoi(raw)
+    if err != nil {
+      return newTerminalCredentialPolicy(0, false), fmt.Errorf("TERMINAL_CREDENTIAL_LENGTH must be a number")
+    }
+    length = parsed
+  }
+  symbols := strings.TrimSpace(os.Getenv("TERMINAL_CREDENTIAL_SYMBOLS")) == "1"
+  policy := newTerminalCredentialPolicy(length, symbols)
+  if err := policy.validate(); err != nil {
+    return policy, err
+  }
+  return policy, nil
+}
+
+func newTerminalCredentialPolicy(length int, symbols bool) terminalCredentialPolicy {
+  size := len(terminalCredentialAlphabet(symbols))
+  policy := terminalCredentialPolicy{
+    Length: length,
+    Symbols: symbols,
+    AlphabetSize: size,
+    EntropyBits: math.Round(float64(length)*math.Log2(float64(size))*10) / 10,
+    MinLength: terminalCredentialMinLength,
+  }
+  policy.Valid = policy.validate() == nil
+  return policy
+}
+
+func (p terminalCredentialPolicy) validate() error {
+  if p.Length < terminalCredentialMinLength {
+    return fmt.Errorf("credential length must be at least %d", terminalCredentialMinLength)
+  }
+  if p.Length > terminalCredentialMaxLength {
+    return fmt.Errorf("credential length must be at most %d", terminalCredentialMaxLength)
+  }
+  if p.EntropyBits < terminalCredentialMinEntropyBits {
+    return fmt.Errorf("credential entropy below %d bits", terminalCredentialMinEntropyBits)
+  }
+  return nil
+}
+
+func terminalCredentialAlphabet(symbols bool) string {
+  if symbols {
+    return terminalCredentialAlnum + terminalCredentialSymbols
+  }
+  return terminalCredentialAlnum
+}
+
+func generateTerminalPassword(policy terminalCredentialPolicy) (string, error) {
+  if err := policy.validate(); err != nil {
+    return "", err
+  }
+  alphabet := terminalCredentialAlphabet(policy.Symbols)
+  max := big.NewInt(int64(len(alphabet)))
+  out := make([]byte, policy.Length)
+  for i := range out {
+    n, err := rand.Int(rand.Reader, max)
+    if err != nil {
+      return "", err
+    }
+    out[i] = alphabet[n.Int64()]
+  }
+  return string(out), nil
+}
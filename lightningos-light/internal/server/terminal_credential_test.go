@@ -0,0 +1,54 @@
+package server
+
+import (
+  "strings"
+  "testing"
+)
+
+func TestTerminalCredentialPolicyFromEnv(t *testing.T) {
+  t.Setenv("TERMINAL_CREDENTIAL_LENGTH", "")
+  t.Setenv("TERMINAL_CREDENTIAL_SYMBOLS", "")
+  policy, err := terminalCredentialPolicyFromEnv()
+  if err != nil || policy.Length != terminalCredentialDefaultLength || policy.Symbols {
+    t.Fatalf("unexpected default policy: %+v (%v)", policy, err)
+  }
+
+  t.Setenv("TERMINAL_CREDENTIAL_LENGTH", "12")
+  if policy, err := terminalCredentialPolicyFromEnv(); err == nil || policy.Valid {
+    t.Fatalf("expected length below 16 to be rejected, got %+v", policy)
+  }
+
+  t.Setenv("TERMINAL_CREDENTIAL_LENGTH", "32")
+  t.Setenv("TERMINAL_CREDENTIAL_SYMBOLS", "1")
+  policy, err = terminalCredentialPolicyFromEnv()
+  if err != nil || !policy.Valid || policy.AlphabetSize != len(terminalCredentialAlnum)+len(terminalCredentialSymbols) {
+    t.Fatalf("unexpected symbols policy: %+v (%v)", policy, err)
+  }
+  if policy.EntropyBits < terminalCredentialMinEntropyBits {
+    t.Fatalf("expected entropy above floor, got %v", policy.EntropyBits)
+  }
+}
+
+func TestGenerateTerminalPassword(t *testing.T) {
+  policy := newTerminalCredentialPolicy(40, true)
+  password, err := generateTerminalPassword(policy)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if len(password) != 40 {
+    t.Fatalf("expected 40 chars, got %d", len(password))
+  }
+  alphabet := terminalCredentialAlphabet(true)
+  for _, ch := range password {
+    if !strings.ContainsRune(alphabet, ch) {
+      t.Fatalf("unexpected char %q in %q", ch, password)
+    }
+  }
+  if strings.ContainsAny(password, ":$= '\"") {
+    t.Fatalf("password contains unsafe chars: %q", password)
+  }
+
+  if _, err := generateTerminalPassword(newTerminalCredentialPolicy(8, false)); err == nil {
+    t.Fatalf("expected short policy to be rejected")
+  }
+}
@@ -76,5 +76,8 @@ func (s *Server) handleTerminalProxy(w http.ResponseWriter, r *http.Request) {
     s.logger.Printf("terminal proxy error: %v", err)
     http.Error(w, "Terminal service unavailable", http.StatusBadGateway)
   }
+  if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+    defer s.trackTerminalClient(r.RemoteAddr)()
+  }
   proxy.ServeHTTP(w, r)
 }
@@ -0,0 +1,272 @@
+package server
+
+import (
+  "bufio"
+  "bytes"
+  "context"
+  "errors"
+  "net/http"
+  "os"
+  "path/filepath"
+  "sort"
+  "strconv"
+  "strings"
+  "time"
+
+  "lightningos-light/internal/system"
+
+  "github.com/go-chi/chi/v5"
+)
+
+const (
+  terminalProcessName = "gotty"
+  procClockTicks = 100
+  terminalClientMatchWindow = 10 * time.Second
+)
+
+var procRoot = "/proc"
+
+type terminalSession struct {
+  ID string `json:"id"`
+  PID int `json:"pid"`
+  ClientAddr string `json:"client_addr,omitempty"`
+  StartedAt string `json:"started_at"`
+  Command string `json:"command,omitempty"`
+}
+
+type terminalClient struct {
+  Addr string
+  Started time.Time
+}
+
+type procStat struct {
+  PID int
+  Comm string
+  PPID int
+  StartTicks uint64
+}
+
+func (s *Server) handleTerminalSessions(w http.ResponseWriter, r *http.Request) {
+  if strings.TrimSpace(os.Getenv("TERMINAL_ENABLED")) != "1" {
+    writeJSON(w, http.StatusOK, map[string]any{"sessions": []terminalSession{}})
+    return
+  }
+
+  sessions, err := s.listTerminalSessions()
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "terminal_sessions_failed", "failed to list terminal sessions")
+    return
+  }
+  writeJSON(w, http.StatusOK, map[string]any{"sessions": sessions})
+}
+
+func (s *Server) handleTerminalKill(w http.ResponseWriter, r *http.Request) {
+  if !terminalWriteAllowed(terminalRequestUser(r), terminalWriteUsers(), terminalAllowWrite()) {
+    writeErrorCode(w, http.StatusForbidden, "terminal_write_disabled", "terminal write access is disabled")
+    return
+  }
+
+  pid, err := strconv.Atoi(strings.TrimSpace(chi.URLParam(r, "id")))
+  if err != nil || pid <= 1 {
+    writeErrorCode(w, http.StatusBadRequest, "invalid_session_id", "invalid session id")
+    return
+  }
+
+  sessions, err := s.listTerminalSessions()
+  if err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "terminal_sessions_failed", "failed to list terminal sessions")
+    return
+  }
+  found := false
+  for _, session := range sessions {
+    if session.PID == pid {
+      found = true
+      break
+    }
+  }
+  if !found {
+    writeErrorCode(w, http.StatusNotFound, "terminal_session_not_found", "terminal session not found")
+    return
+  }
+
+  ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+  defer cancel()
+  if _, err := system.RunCommandWithSudo(ctx, "kill", "-TERM", strconv.Itoa(pid)); err != nil {
+    writeErrorCode(w, http.StatusInternalServerError, "terminal_kill_failed", "failed to terminate session")
+    return
+  }
+  writeJSON(w, http.StatusOK, map[string]any{"ok": true, "id": strconv.Itoa(pid)})
+}
+
+func (s *Server) listTerminalSessions() ([]terminalSession, error) {
+  stats, err := readProcStats(procRoot)
+  if err != nil {
+    return nil, err
+  }
+  bootTime, err := readBootTime(procRoot)
+  if err != nil {
+    return nil, err
+  }
+
+  servers := map[int]bool{}
+  for _, stat := range stats {
+    if stat.Comm == terminalProcessName {
+      servers[stat.PID] = true
+    }
+  }
+
+  clients := s.terminalClientsSnapshot()
+  used := make([]bool, len(clients))
+  sessions := []terminalSession{}
+  for _, stat := range stats {
+    if !servers[stat.PPID] {
+      continue
+    }
+    started := bootTime.Add(time.Duration(stat.StartTicks) * time.Second / procClockTicks)
+    session := terminalSession{
+      ID: strconv.Itoa(stat.PID),
+      PID: stat.PID,
+      StartedAt: started.UTC().Format(time.RFC3339),
+      Command: readProcCmdline(procRoot, stat.PID),
+    }
+    if idx := matchTerminalClient(clients, used, started); idx >= 0 {
+      used[idx] = true
+      session.ClientAddr = clients[idx].Addr
+    }
+    sessions = append(sessions, session)
+  }
+  sort.Slice(sessions, func(i, j int) bool {
+    return sessions[i].StartedAt < sessions[j].StartedAt
+  })
+  return sessions, nil
+}
+
+func (s *Server) trackTerminalClient(addr string) func() {
+  s.terminalClientsMu.Lock()
+  if s.terminalClients == nil {
+    s.terminalClients = map[int64]terminalClient{}
+  }
+  s.terminalClientSeq++
+  id := s.terminalClientSeq
+  s.terminalClients[id] = terminalClient{Addr: addr, Started: time.Now()}
+  s.terminalClientsMu.Unlock()
+
+  return func() {
+    s.terminalClientsMu.Lock()
+    delete(s.terminalClients, id)
+    s.terminalClientsMu.Unlock()
+  }
+}
+
+func (s *Server) terminalClientsSnapshot() []terminalClient {
+  s.terminalClientsMu.Lock()
+  defer s.terminalClientsMu.Unlock()
+  clients := make([]terminalClient, 0, len(s.terminalClients))
+  for _, client := range s.terminalClients {
+    clients = append(clients, client)
+  }
+  return clients
+}
+
+func matchTerminalClient(clients []terminalClient, used []bool, started time.Time) int {
+  best := -1
+  var bestDiff time.Duration
+  for i, client := range clients {
+    if used[i] {
+      continue
+    }
+    diff := client.Started.Sub(started)
+    if diff < 0 {
+      diff = -diff
+    }
+    if diff > terminalClientMatchWindow {
+      continue
+    }
+    if best < 0 || diff < bestDiff {
+      best = i
+      bestDiff = diff
+    }
+  }
+  return best
+}
+
+func readProcStats(root string) ([]procStat, error) {
+  entries, err := os.ReadDir(root)
+  if err != nil {
+    return nil, err
+  }
+  var stats []procStat
+  for _, entry := range entries {
+    if _, err := strconv.Atoi(entry.Name()); err != nil {
+      continue
+    }
+    raw, err := os.ReadFile(filepath.Join(root, entry.Name(), "stat"))
+    if err != nil {
+      continue
+    }
+    stat, err := parseProcStat(string(raw))
+    if err != nil {
+      continue
+    }
+    stats = append(stats, stat)
+  }
+  return stats, nil
+}
+
+func parseProcStat(raw string) (procStat, error) {
+  commStart := strings.IndexByte(raw, '(')
+  commEnd := strings.LastIndexByte(raw, ')')
+  if commStart < 0 || commEnd < commStart {
+    return procStat{}, errors.New("invalid stat format")
+  }
+  pid, err := strconv.Atoi(strings.TrimSpace(raw[:commStart]))
+  if err != nil {
+    return procStat{}, err
+  }
+  // Fields after the comm start at field 3 (state); starttime is field 22.
+  fields := strings.Fields(raw[commEnd+1:])
+  if len(fields) < 20 {
+    return procStat{}, errors.New("stat too short")
+  }
+  ppid, err := strconv.Atoi(fields[1])
+  if err != nil {
+    return procStat{}, err
+  }
+  startTicks, err := strconv.ParseUint(fields[19], 10, 64)
+  if err != nil {
+    return procStat{}, err
+  }
+  return procStat{
+    PID: pid,
+    Comm: raw[commStart+1 : commEnd],
+    PPID: ppid,
+    StartTicks: startTicks,
+  }, nil
+}
+
+func readBootTime(root string) (time.Time, error) {
+  raw, err := os.ReadFile(filepath.Join(root, "stat"))
+  if err != nil {
+    return time.Time{}, err
+  }
+  scanner := bufio.NewScanner(bytes.NewReader(raw))
+  for scanner.Scan() {
+    fields := strings.Fields(scanner.Text())
+    if len(fields) == 2 && fields[0] == "btime" {
+      secs, err := strconv.ParseInt(fields[1], 10, 64)
+      if err != nil {
+        return time.Time{}, err
+      }
+      return time.Unix(secs, 0), nil
+    }
+  }
+  return time.Time{}, errors.New("btime not found")
+}
+
+func readProcCmdline(root string, pid int) string {
+  raw, err := os.ReadFile(filepath.Join(root, strconv.Itoa(pid), "cmdline"))
+  if err != nil {
+    return ""
+  }
+  return strings.TrimSpace(strings.ReplaceAll(string(raw), "\x00", " "))
+}
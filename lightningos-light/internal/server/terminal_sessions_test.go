@@ -0,0 +1,43 @@
+package server
+
+import (
+  "testing"
+  "time"
+)
+
+func TestParseProcStat(t *testing.T) {
+  raw := "4242 (tmux: client) S 4100 4242 4100 34816 4242 4194560 300 0 0 0 1 2 0 0 20 0 1 0 987654 8192000 900 18446744073709551615\n"
+  stat, err := parseProcStat(raw)
+  if err != nil {
+    t.Fatalf("unexpected error: %v", err)
+  }
+  if stat.PID != 4242 || stat.PPID != 4100 || stat.Comm != "tmux: client" || stat.StartTicks != 987654 {
+    t.Fatalf("unexpected stat: %+v", stat)
+  }
+
+  if _, err := parseProcStat("garbage"); err == nil {
+    t.Fatalf("expected error for malformed stat")
+  }
+}
+
+func TestMatchTerminalClientPicksNearestUnused(t *testing.T) {
+  base := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+  clients := []terminalClient{
+    {Addr: "10.0.0.2:5000", Started: base.Add(-2 * time.Second)},
+    {Addr: "10.0.0.3:5001", Started: base.Add(time.Second)},
+    {Addr: "10.0.0.4:5002", Started: base.Add(-time.Minute)},
+  }
+  used := make([]bool, len(clients))
+
+  if idx := matchTerminalClient(clients, used, base); idx != 1 {
+    t.Fatalf("expected nearest client 1, got %d", idx)
+  }
+  used[1] = true
+  if idx := matchTerminalClient(clients, used, base); idx != 0 {
+    t.Fatalf("expected fallback client 0, got %d", idx)
+  }
+  used[0] = true
+  if idx := matchTerminalClient(clients, used, base); idx != -1 {
+    t.Fatalf("expected no match outside window, got %d", idx)
+  }
+}
@@ -7,6 +7,8 @@ import (
   "strings"
 )
 
+const secretMask = "••••"
+
 type terminalStatus struct {
   Enabled bool `json:"enabled"`
   Credential string `json:"credential"`
@@ -14,12 +16,22 @@ type terminalStatus struct {
   Port int `json:"port"`
   OperatorUser string `json:"operator_user"`
   OperatorPassword string `json:"operator_password"`
+  HasPassword bool `json:"has_password"`
+  Revealed bool `json:"revealed"`
+  WriteUsers []string `json:"write_users,omitempty"`
+  User string `json:"user,omitempty"`
+  CanWrite bool `json:"can_write"`
+  CredentialPolicy terminalCredentialPolicy `json:"credential_policy"`
+  Error *apiError `json:"error,omitempty"`
 }
 
 func (s *Server) handleTerminalStatus(w http.ResponseWriter, r *http.Request) {
   enabled := strings.TrimSpace(os.Getenv("TERMINAL_ENABLED")) == "1"
   credential := strings.TrimSpace(os.Getenv("TERMINAL_CREDENTIAL"))
-  allowWrite := strings.TrimSpace(os.Getenv("TERMINAL_ALLOW_WRITE")) == "1"
+  allowWrite := terminalAllowWrite()
+  writeUsers := terminalWriteUsers()
+  user := terminalRequestUser(r)
+  policy, _ := terminalCredentialPolicyFromEnv()
   operatorUser := strings.TrimSpace(os.Getenv("TERMINAL_OPERATOR_USER"))
   operatorPassword := strings.TrimSpace(os.Getenv("TERMINAL_OPERATOR_PASSWORD"))
   port := 7681
@@ -29,12 +41,42 @@ func (s *Server) handleTerminalStatus(w http.ResponseWriter, r *http.Request) {
     }
   }
 
+  var statusErr *apiError
+  if enabled && basicAuthHeader(credential) == "" {
+    statusErr = &apiError{Code: "terminal_credential_invalid", Message: "TERMINAL_CREDENTIAL must be user:password"}
+  }
+
+  reveal := strings.TrimSpace(r.URL.Query().Get("reveal")) == "1"
+  hasPassword := operatorPassword != ""
+  if !reveal {
+    credential = maskSecret(credential)
+    operatorPassword = maskSecret(operatorPassword)
+  }
+
   writeJSON(w, http.StatusOK, terminalStatus{
     Enabled: enabled,
     Credential: credential,
     AllowWrite: allowWrite,
     OperatorUser: operatorUser,
     OperatorPassword: operatorPassword,
+    HasPassword: hasPassword,
+    Revealed: reveal,
     Port: port,
+    WriteUsers: writeUsers,
+    User: user,
+    CanWrite: terminalWriteAllowed(user, writeUsers, allowWrite),
+    CredentialPolicy: policy,
+    Error: statusErr,
   })
 }
+
+func maskSecret(value string) string {
+  if value == "" {
+    return ""
+  }
+  runes := []rune(value)
+  if len(runes) <= 4 {
+    return secretMask
+  }
+  return secretMask + string(runes[len(runes)-4:])
+}
@@ -6,6 +6,7 @@ import (
   "context"
   "errors"
   "fmt"
+  "io"
   "os"
   "os/exec"
   "strconv"
@@ -254,6 +255,70 @@ func RunCommandWithSudo(ctx context.Context, name string, args ...string) (strin
   return sudoOut, fmt.Errorf("%s failed: %w; sudo failed: %v", name, err, sudoErr)
 }
 
+func RunCommandStream(ctx context.Context, onLine func(string), name string, args ...string) error {
+  cmd := exec.CommandContext(ctx, name, args...)
+  reader, writer := io.Pipe()
+  cmd.Stdout = writer
+  cmd.Stderr = writer
+
+  done := make(chan struct{})
+  go func() {
+    defer close(done)
+    scanner := bufio.NewScanner(reader)
+    scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+    for scanner.Scan() {
+      if onLine != nil {
+        onLine(scanner.Text())
+      }
+    }
+    _, _ = io.Copy(io.Discard, reader)
+  }()
+
+  err := cmd.Run()
+  _ = writer.Close()
+  <-done
+  if err != nil {
+    return fmt.Errorf("%s failed: %w", name, err)
+  }
+  return nil
+}
+
+// RunCommandStreamWithSudo retries under sudo only when the first run was
+// refused before doing any work: no output, or nothing but permission errors.
+// Once the command has streamed real output a second run would repeat it, so
+// the first error is returned instead.
+func RunCommandStreamWithSudo(ctx context.Context, onLine func(string), name string, args ...string) error {
+  refused := true
+  err := RunCommandStream(ctx, func(line string) {
+    if !isPermissionDenied(line) {
+      refused = false
+    }
+    if onLine != nil {
+      onLine(line)
+    }
+  }, name, args...)
+  if err == nil || !refused {
+    return err
+  }
+  sudoPath, sudoErr := exec.LookPath("sudo")
+  if sudoErr != nil {
+    return err
+  }
+  sudoArgs := append([]string{"-n", name}, args...)
+  if sudoErr = RunCommandStream(ctx, onLine, sudoPath, sudoArgs...); sudoErr == nil {
+    return nil
+  }
+  return fmt.Errorf("%s failed: %w; sudo failed: %v", name, err, sudoErr)
+}
+
+func isPermissionDenied(line string) bool {
+  lower := strings.ToLower(line)
+  return strings.Contains(lower, "permission denied") ||
+    strings.Contains(lower, "access denied") ||
+    strings.Contains(lower, "operation not permitted") ||
+    strings.Contains(lower, "interactive authentication required")
+}
+
 func systemctlPath() string {
   if path, err := exec.LookPath("systemctl"); err == nil {
     return path
@@ -0,0 +1,39 @@
+package system
+
+import (
+  "context"
+  "os/exec"
+  "testing"
+)
+
+func TestRunCommandStreamWithSudoKeepsOutputOnFailure(t *testing.T) {
+  if _, err := exec.LookPath("sh"); err != nil {
+    t.Skip("sh not available")
+  }
+  var lines []string
+  err := RunCommandStreamWithSudo(context.Background(), func(line string) {
+    lines = append(lines, line)
+  }, "sh", "-c", "echo one; echo two; exit 3")
+  if err == nil {
+    t.Fatalf("expected the command to fail")
+  }
+  if len(lines) != 2 || lines[0] != "one" || lines[1] != "two" {
+    t.Fatalf("expected each line once without a sudo retry, got %q", lines)
+  }
+}
+
+func TestIsPermissionDenied(t *testing.T) {
+  for _, line := range []string{
+    "Failed to start transient service unit: Access denied",
+    "sh: 1: cannot create /etc/x: Permission denied",
+    "Failed to connect to bus: Operation not permitted",
+    "Interactive authentication required.",
+  } {
+    if !isPermissionDenied(line) {
+      t.Fatalf("expected %q to count as a refusal", line)
+    }
+  }
+  if isPermissionDenied("Loading block index...") {
+    t.Fatalf("expected regular output not to count as a refusal")
+  }
+}
@@ -11,15 +11,18 @@ async function request(path: string, options?: RequestInit) {
   if (!res.ok) {
     const text = await res.text()
     if (text) {
+      let message = text
       try {
         const payload = JSON.parse(text)
         if (payload && typeof payload.error === 'string') {
-          throw new Error(payload.error)
+          message = payload.error
+        } else if (payload?.error && typeof payload.error.message === 'string') {
+          message = payload.error.message
         }
       } catch {
         // fall through to raw text
       }
-      throw new Error(text)
+      throw new Error(message)
     }
     throw new Error('Request failed')
   }
@@ -156,7 +159,11 @@ export const updateTelegramBackupConfig = (payload: { bot_token?: string; chat_i
 export const testTelegramBackup = () =>
   request('/api/notifications/backup/telegram/test', { method: 'POST' })
 
-export const getTerminalStatus = () => request('/api/terminal/status')
+export const getTerminalStatus = (reveal = false) =>
+  request(reveal ? '/api/terminal/status?reveal=1' : '/api/terminal/status')
+export const getTerminalSessions = () => request('/api/terminal/sessions')
+export const killTerminalSession = (id: string) =>
+  request(`/api/terminal/sessions/${encodeURIComponent(id)}/kill`, { method: 'POST' })
 
 export const getOnchainUtxos = (params?: {
   min_conf?: number
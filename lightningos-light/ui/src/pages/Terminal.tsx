@@ -6,9 +6,19 @@ type TerminalStatus = {
   enabled: boolean
   credential?: string
   allow_write?: boolean
+  can_write?: boolean
   port?: number
   operator_user?: string
   operator_password?: string
+  has_password?: boolean
+  credential_policy?: {
+    length: number
+    symbols: boolean
+    alphabet_size: number
+    entropy_bits: number
+    min_length: number
+    valid: boolean
+  }
 }
 
 export default function Terminal() {
@@ -25,6 +35,15 @@ export default function Terminal() {
     }
   }
 
+  const copyOperatorPassword = async () => {
+    try {
+      const data: TerminalStatus = await getTerminalStatus(true)
+      await copyToClipboard(data?.operator_password || '')
+    } catch {
+      // ignore copy failures
+    }
+  }
+
   useEffect(() => {
     let mounted = true
     getTerminalStatus()
@@ -64,7 +83,7 @@ export default function Terminal() {
                     {t('terminal.disabledMessage')}
                   </p>
                 )}
-                {status?.operator_password && (
+                {status?.has_password && (
                   <div className="flex flex-wrap items-center gap-2">
                     <span className="text-fog/50">{t('terminal.operator')}</span>
                     <span className="font-mono text-fog/80">{status.operator_user || 'losop'}</span>
@@ -72,7 +91,7 @@ export default function Terminal() {
                     <span className="font-mono text-fog/80">{status.operator_password}</span>
                     <button
                       className="text-fog/50 hover:text-fog"
-                      onClick={() => copyToClipboard(status.operator_password || '')}
+                      onClick={copyOperatorPassword}
                       title={t('terminal.copyOperatorPassword')}
                       aria-label={t('terminal.copyOperatorPassword')}
                     >